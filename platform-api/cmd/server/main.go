@@ -1,34 +1,66 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"time"
 
 	"devplatform/platform-api/internal/config"
+	"devplatform/platform-api/internal/events"
 	"devplatform/platform-api/internal/handlers"
 	"devplatform/platform-api/internal/middleware"
+	"devplatform/platform-api/internal/models"
+	"devplatform/platform-api/internal/ratelimit"
 	"devplatform/platform-api/internal/services"
 	"devplatform/platform-api/pkg/aws"
 	"devplatform/platform-api/pkg/database"
-	"devplatform/platform-api/pkg/k8s"
+	"devplatform/platform-api/pkg/policy"
+	"devplatform/platform-api/pkg/terraform"
+	"devplatform/platform-api/pkg/tlsutil"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
 
 func main() {
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML config file")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration with secrets redacted and exit")
+	flag.Parse()
+
 	if err := godotenv.Load(); err != nil {
 		log.Printf("Warning: .env file not loaded: %v", err)
 	}
 
-	cfg := config.Load()
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	validationErr := cfg.Validate()
+
+	if *printConfig {
+		out, err := cfg.Redacted().YAML()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print(out)
+		if validationErr != nil {
+			fmt.Fprintf(os.Stderr, "configuration is invalid:\n%v\n", validationErr)
+			os.Exit(1)
+		}
+		return
+	}
 
-	// Validate JWT secret in production
-	if cfg.Environment == "production" && cfg.JWTSecret == "dev-secret-key-change-in-production" {
-		log.Fatal("SECURITY ERROR: Default JWT secret detected in production environment")
+	if validationErr != nil {
+		log.Fatalf("Invalid configuration:\n%v", validationErr)
 	}
 
-	db, err := database.NewPostgresConnection(cfg.DatabaseURL)
+	db, err := database.NewPostgresConnection(cfg.DatabaseDSN())
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
@@ -39,14 +71,68 @@ func main() {
 		log.Fatalf("Failed to load AWS config: %v", err)
 	}
 
-	k8sClient, err := k8s.NewClient(cfg.KubeConfig)
+	eventBus := events.NewBus(cfg.EventReplayBuffer)
+	if err := eventBus.Share(db, cfg.DatabaseDSN()); err != nil {
+		log.Fatalf("Failed to share events between replicas: %v", err)
+	}
+
+	clusterRegistry := services.NewClusterRegistry(db, awsConfig, eventBus)
+	if err := clusterRegistry.Seed(clusterRegistrations(cfg)); err != nil {
+		log.Fatalf("Failed to register clusters: %v", err)
+	}
+	defaultClient, err := clusterRegistry.Client("")
 	if err != nil {
 		log.Fatalf("Failed to create Kubernetes client: %v", err)
 	}
 
+	syncCtx, cancelSync := context.WithTimeout(context.Background(), 30*time.Second)
+	if !defaultClient.Cache.WaitForSync(syncCtx) {
+		log.Printf("Warning: informer cache not synced yet, reads fall back to the API server until it is")
+	}
+	cancelSync()
+
 	costService := services.NewCostService(awsConfig)
-	tenantService := services.NewTenantService(db, k8sClient)
-	k8sService := services.NewK8sService(k8sClient)
+	var executor terraform.Executor
+	switch {
+	case cfg.TerraformExecutor == "local":
+		executor = terraform.NewLocalExecutor(cfg.TerraformBinary, cfg.TerraformWorkDir, cfg.TerraformBackend,
+			cfg.TerraformBackendConfig, cfg.TerraformRunUIDBase)
+	case cfg.TFCToken != "":
+		tfc := terraform.NewClient(cfg.TFCAddress, cfg.TFCToken)
+		tfc.HTTPClient.Timeout = time.Duration(cfg.TFCTimeout) * time.Second
+		tfc.MaxRetries = cfg.TFCMaxRetries
+		executor = terraform.NewCloudExecutor(tfc, cfg.TFCOrganization, cfg.TFCVariableSets)
+	}
+	var planPolicy *policy.Policy
+	if cfg.TerraformPolicyFile != "" {
+		if planPolicy, err = policy.Load(cfg.TerraformPolicyFile); err != nil {
+			log.Fatalf("Failed to load Terraform policy: %v", err)
+		}
+	}
+	infraService := services.NewInfrastructureService(db, clusterRegistry, executor, services.InfrastructureOptions{
+		SyncOutputs: cfg.TFCSyncOutputs,
+		Policy:      planPolicy,
+	}, eventBus)
+	infraService.ResumeTracking()
+	if cfg.TerraformDriftInterval > 0 {
+		go infraService.RunDriftDetection(time.Duration(cfg.TerraformDriftInterval)*time.Minute, nil)
+	}
+	tenantService := services.NewTenantService(db, clusterRegistry, eventBus, infraService)
+	go tenantService.ResumeDeletions(time.Minute, nil)
+	templateService := services.NewTemplateService(db, infraService)
+	k8sService := services.NewK8sService(clusterRegistry)
+	nodeService := services.NewNodeService(db, clusterRegistry)
+	go nodeService.FailAbandonedOperations(nil)
+	auditService := services.NewAuditService(db)
+	sleepService := services.NewSleepService(db, tenantService, costService, eventBus)
+
+	// Cron schedules have minute resolution.
+	go sleepService.Run(time.Minute, nil)
+
+	if cfg.BudgetMonthlyLimit > 0 || cfg.TenantBudgetMonthlyLimit > 0 {
+		budgetMonitor := services.NewBudgetMonitor(db, costService, eventBus, cfg.BudgetMonthlyLimit, cfg.TenantBudgetMonthlyLimit)
+		go budgetMonitor.Run(time.Duration(cfg.BudgetCheckInterval)*time.Minute, nil)
+	}
 
 	// Set production mode if not development
 	if cfg.Environment != "development" {
@@ -56,7 +142,15 @@ func main() {
 	r := gin.New()
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
-	r.Use(middleware.CORS())
+	r.Use(middleware.RequestID())
+	r.Use(middleware.CORS(middleware.CORSOptions{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowedMethods:   cfg.CORSAllowedMethods,
+		AllowedHeaders:   cfg.CORSAllowedHeaders,
+		ExposedHeaders:   []string{middleware.RequestIDHeader},
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAge:           cfg.CORSMaxAge,
+	}))
 	r.Use(middleware.RequestLogger())
 
 	// Add security headers
@@ -64,7 +158,9 @@ func main() {
 		c.Header("X-Content-Type-Options", "nosniff")
 		c.Header("X-Frame-Options", "DENY")
 		c.Header("X-XSS-Protection", "1; mode=block")
-		c.Header("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+		if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+			c.Header("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+		}
 		c.Next()
 	})
 
@@ -74,35 +170,183 @@ func main() {
 	public := api.Group("/")
 	{
 		public.GET("/health", handlers.HealthCheck)
+		public.GET("/ready", handlers.ReadinessCheck(db, clusterRegistry))
 	}
 
 	// Protected endpoints (auth required)
 	protected := api.Group("/")
+	protected.Use(middleware.Audit(auditService))
+	protected.Use(middleware.ClientCertAuth(cfg.TLSClientIdentities))
 	protected.Use(middleware.AuthRequired(cfg.JWTSecret))
+	if cfg.RateLimitEnabled {
+		protected.Use(middleware.RateLimit(newRateLimitStore(cfg, db), middleware.RateLimitOptions{
+			Read:  ratelimit.PerMinute(cfg.RateLimitReadPerMinute),
+			Write: ratelimit.PerMinute(cfg.RateLimitWritePerMinute),
+			Cost:  ratelimit.PerMinute(cfg.RateLimitCostPerMinute),
+		}))
+	}
 	{
 		// Tenant management
 		protected.GET("/tenants", handlers.ListTenants(tenantService))
-		protected.POST("/tenants", handlers.CreateTenant(tenantService))
+		protected.POST("/tenants", middleware.AuditAs("tenant.create", "tenant", ""), handlers.CreateTenant(tenantService))
 		protected.GET("/tenants/:id", handlers.GetTenant(tenantService))
-		protected.DELETE("/tenants/:id", handlers.DeleteTenant(tenantService))
+		protected.GET("/tenants/:id/workloads", middleware.RequireTenantMember(tenantService), handlers.GetTenantWorkloads(tenantService))
+		protected.GET("/tenants/:id/events", middleware.RequireTenantMember(tenantService), handlers.GetTenantEvents(tenantService))
+		protected.GET("/tenants/:id/pods/:pod/logs", middleware.RequireTenantMember(tenantService), handlers.StreamPodLogs(tenantService))
+		protected.GET("/tenants/:id/members", middleware.RequireTenantMember(tenantService), handlers.ListTenantMembers(tenantService))
+		protected.POST("/tenants/:id/members", middleware.RequireRole("admin"), middleware.AuditAs("tenant.member.add", "tenant", "id"), handlers.AddTenantMember(tenantService))
+		protected.DELETE("/tenants/:id/members/:user", middleware.RequireRole("admin"), middleware.AuditAs("tenant.member.remove", "tenant", "id"), handlers.RemoveTenantMember(tenantService))
+		protected.GET("/tenants/:id/sleep", middleware.RequireTenantMember(tenantService), handlers.GetTenantSleep(sleepService))
+		protected.POST("/tenants/:id/sleep", middleware.RequireTenantMember(tenantService), middleware.AuditAs("tenant.sleep", "tenant", "id"), handlers.SetTenantSleep(sleepService, true))
+		protected.POST("/tenants/:id/wake", middleware.RequireTenantMember(tenantService), middleware.AuditAs("tenant.wake", "tenant", "id"), handlers.SetTenantSleep(sleepService, false))
+		// Tenant infrastructure
+		infra := protected.Group("/tenants/:id/infrastructure", middleware.RequireTenantMember(tenantService))
+		infra.GET("/runs", handlers.ListInfrastructureRuns(infraService))
+		infra.POST("/runs", middleware.AuditAs("infrastructure.run.create", "tenant", "id"), handlers.CreateInfrastructureRun(infraService))
+		infra.GET("/runs/:run_id", handlers.GetInfrastructureRun(infraService))
+		infra.GET("/runs/:run_id/logs", handlers.GetInfrastructureRunLogs(infraService))
+		infra.GET("/runs/:run_id/plan", handlers.GetInfrastructureRunPlan(infraService, tenantService))
+		infra.POST("/runs/:run_id/apply", middleware.AuditAs("infrastructure.run.apply", "tenant", "id"), handlers.ConfirmInfrastructureRun(infraService, true))
+		infra.POST("/runs/:run_id/discard", middleware.AuditAs("infrastructure.run.discard", "tenant", "id"), handlers.ConfirmInfrastructureRun(infraService, false))
+		infra.GET("/outputs", handlers.GetInfrastructureOutputs(infraService, tenantService))
+		infra.POST("/outputs/sync", middleware.AuditAs("infrastructure.outputs.sync", "tenant", "id"), handlers.SyncInfrastructureOutputs(infraService))
+		infra.GET("/drift", handlers.GetInfrastructureDrift(infraService))
+
+		protected.GET("/tenants/:id/resources", middleware.RequireTenantMember(tenantService), handlers.ListTenantResources(templateService))
+		protected.POST("/tenants/:id/resources", middleware.RequireTenantMember(tenantService), middleware.AuditAs("tenant.resource.create", "tenant", "id"), handlers.CreateTenantResource(templateService))
+		protected.DELETE("/tenants/:id/resources/:name", middleware.RequireTenantMember(tenantService), middleware.AuditAs("tenant.resource.delete", "tenant", "id"), handlers.DeleteTenantResource(templateService))
+
+		protected.DELETE("/tenants/:id", middleware.RequireRole("admin"), middleware.AuditAs("tenant.delete", "tenant", "id"), handlers.DeleteTenant(tenantService))
+
+		// Service catalog
+		protected.GET("/templates", handlers.ListTemplates(templateService))
+		protected.GET("/templates/:name", handlers.GetTemplate(templateService))
+		protected.POST("/templates", middleware.RequireRole("admin"), middleware.AuditAs("template.create", "template", ""), handlers.CreateTemplate(templateService))
+
+		// Sleep schedules
+		protected.GET("/sleep-schedules", middleware.RequireRole("admin"), handlers.ListSleepSchedules(sleepService))
+		protected.POST("/sleep-schedules", middleware.RequireRole("admin"), middleware.AuditAs("sleep_schedule.save", "sleep_schedule", ""), handlers.SaveSleepSchedule(sleepService))
+		protected.DELETE("/sleep-schedules/:id", middleware.RequireRole("admin"), middleware.AuditAs("sleep_schedule.delete", "sleep_schedule", "id"), handlers.DeleteSleepSchedule(sleepService))
 
 		// Cost management
 		protected.GET("/tenants/:id/costs", handlers.GetTenantCosts(costService))
 		protected.GET("/costs/overview", handlers.GetCostOverview(costService))
 
 		// Cluster management
+		protected.GET("/clusters", handlers.ListClusters(clusterRegistry))
+		protected.POST("/clusters", middleware.RequireRole("admin"), middleware.AuditAs("cluster.register", "cluster", ""), handlers.RegisterCluster(clusterRegistry))
+		protected.DELETE("/clusters/:name", middleware.RequireRole("admin"), middleware.AuditAs("cluster.remove", "cluster", "name"), handlers.RemoveCluster(clusterRegistry))
+		protected.GET("/clusters/:name/overview", handlers.GetClusterOverview(k8sService))
 		protected.GET("/clusters/:name/status", handlers.GetClusterStatus(k8sService))
 		protected.GET("/clusters/:name/nodes", handlers.GetClusterNodes(k8sService))
 		protected.GET("/clusters/:name/namespaces", handlers.GetNamespaces(k8sService))
+
+		// Node operations
+		nodes := protected.Group("/clusters/:name/nodes/:node", middleware.RequireRole("admin"))
+		nodes.POST("/cordon", middleware.AuditAs("node.cordon", "node", ""), handlers.CordonNode(nodeService, true))
+		nodes.POST("/uncordon", middleware.AuditAs("node.uncordon", "node", ""), handlers.CordonNode(nodeService, false))
+		nodes.POST("/drain", middleware.AuditAs("node.drain", "node", ""), handlers.DrainNode(nodeService))
+		nodes.GET("/operations", handlers.ListNodeOperations(nodeService))
+		nodes.GET("/operations/:operation", handlers.GetNodeOperation(nodeService))
+		nodes.PATCH("/labels", middleware.AuditAs("node.labels", "node", ""), handlers.PatchNodeLabels(nodeService))
+		nodes.PATCH("/taints", middleware.AuditAs("node.taints", "node", ""), handlers.PatchNodeTaints(nodeService))
+
+		// Live change stream
+		protected.GET("/events/stream", handlers.StreamEvents(eventBus, tenantService))
+
+		// Audit log
+		protected.GET("/audit", middleware.RequireRole("admin"), handlers.ListAuditLog(auditService))
+		protected.GET("/audit/verify", middleware.RequireRole("admin"), handlers.VerifyAuditLog(auditService))
+	}
+
+	port := cfg.Port
+
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if cfg.TLSCertFile == "" {
+		log.Printf("Platform API starting on port %s in %s mode", port, cfg.Environment)
+		if err := server.ListenAndServe(); err != nil {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+		return
+	}
+
+	clientAuth, err := tlsutil.ParseClientAuth(cfg.TLSClientAuth)
+	if err != nil {
+		log.Fatalf("Invalid TLS client auth mode: %v", err)
 	}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	reloader, err := tlsutil.NewReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSClientCAFile)
+	if err != nil {
+		log.Fatalf("Failed to load TLS certificates: %v", err)
 	}
+	go reloader.Watch(time.Duration(cfg.TLSReloadInterval)*time.Second, nil)
+
+	server.TLSConfig = reloader.TLSConfig(clientAuth)
 
-	log.Printf("Platform API starting on port %s in %s mode", port, cfg.Environment)
-	if err := r.Run(":" + port); err != nil {
+	log.Printf("Platform API starting with TLS (client auth: %s) on port %s in %s mode", cfg.TLSClientAuth, port, cfg.Environment)
+	if err := server.ListenAndServeTLS("", ""); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// clusterRegistrations turns the configured clusters into registrations. The
+// cluster named by ClusterName is added from KubeConfig when not listed
+// explicitly, and is the default unless another entry claims it.
+func clusterRegistrations(cfg *config.Config) []models.ClusterRegistration {
+	var registrations []models.ClusterRegistration
+	hasPrimary, hasDefault := false, false
+
+	for _, c := range cfg.Clusters {
+		registrations = append(registrations, models.ClusterRegistration{
+			Name:           c.Name,
+			Source:         c.Source,
+			KubeConfig:     c.KubeConfig,
+			Context:        c.Context,
+			EKSClusterName: c.EKSClusterName,
+			Region:         c.Region,
+			Default:        c.Default,
+		})
+		hasPrimary = hasPrimary || c.Name == cfg.ClusterName
+		hasDefault = hasDefault || c.Default
+	}
+
+	if !hasPrimary {
+		primary := models.ClusterRegistration{
+			Name:    cfg.ClusterName,
+			Source:  models.ClusterSourceInCluster,
+			Default: !hasDefault,
+		}
+		if cfg.KubeConfig != "" {
+			primary.Source = models.ClusterSourceKubeconfig
+			primary.KubeConfig = cfg.KubeConfig
+		}
+		registrations = append(registrations, primary)
+	} else if !hasDefault {
+		for i := range registrations {
+			registrations[i].Default = registrations[i].Name == cfg.ClusterName
+		}
+	}
+
+	return registrations
+}
+
+func newRateLimitStore(cfg *config.Config, db *sql.DB) ratelimit.Store {
+	if cfg.RateLimitStore != "postgres" {
+		return ratelimit.NewMemoryStore()
+	}
+
+	store := ratelimit.NewPostgresStore(db)
+	go func() {
+		for range time.Tick(10 * time.Minute) {
+			if err := store.Cleanup(time.Hour); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		}
+	}()
+	return store
+}
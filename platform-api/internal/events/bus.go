@@ -0,0 +1,183 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	TopicTenantLifecycle = "tenant.lifecycle"
+	TopicNodeReadiness   = "node.readiness"
+	TopicBudgetAlert     = "budget.alert"
+	TopicQuotaBreach     = "quota.breach"
+	TopicInfraRun        = "infrastructure.run"
+	TopicInfraDrift      = "infrastructure.drift"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind before it
+// is dropped. A dropped client reconnects with Last-Event-ID and catches up
+// from the replay buffer.
+const subscriberBuffer = 64
+
+// Event is a change published on the bus. TenantID is set for events that
+// belong to a tenant; AdminOnly events are only delivered to admins. Key
+// identifies a change every replica observes, such as an informer update, so
+// a shared bus delivers it once.
+type Event struct {
+	ID        uint64      `json:"id"`
+	Topic     string      `json:"topic"`
+	Type      string      `json:"type"`
+	TenantID  string      `json:"tenant_id,omitempty"`
+	Cluster   string      `json:"cluster,omitempty"`
+	AdminOnly bool        `json:"-"`
+	Key       string      `json:"-"`
+	Data      interface{} `json:"data"`
+	Time      time.Time   `json:"time"`
+}
+
+// Bus fans events out to subscribers and keeps the most recent ones in a
+// bounded ring so reconnecting clients can resume. A nil *Bus discards
+// everything, which keeps publishers simple when streaming is not wired up.
+type Bus struct {
+	mu     sync.Mutex
+	nextID uint64
+	ring   []Event
+	start  int
+	count  int
+	subs   map[*Subscription]struct{}
+	relay  *relay
+}
+
+type Subscription struct {
+	C <-chan Event
+
+	bus    *Bus
+	ch     chan Event
+	filter func(Event) bool
+	closed bool
+}
+
+func NewBus(replaySize int) *Bus {
+	return &Bus{
+		nextID: 1,
+		ring:   make([]Event, replaySize),
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish assigns the event an ID and timestamp, records it for replay and
+// delivers it to matching subscribers without blocking. On a shared bus the
+// event is queued for the database and delivered once it comes back.
+func (b *Bus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.relay != nil {
+		b.relay.enqueue(e)
+		return
+	}
+	e.ID = b.nextID
+	b.deliver(e)
+}
+
+// deliver records an event that has its ID and hands it to subscribers. An
+// event already in the replay buffer is ignored. b.mu must be held.
+func (b *Bus) deliver(e Event) {
+	if e.ID >= b.nextID {
+		b.nextID = e.ID + 1
+	}
+
+	if len(b.ring) > 0 {
+		for i := 0; i < b.count; i++ {
+			if b.ring[(b.start+i)%len(b.ring)].ID == e.ID {
+				return
+			}
+		}
+		if b.count < len(b.ring) {
+			b.ring[(b.start+b.count)%len(b.ring)] = e
+			b.count++
+		} else {
+			b.ring[b.start] = e
+			b.start = (b.start + 1) % len(b.ring)
+		}
+	}
+
+	for sub := range b.subs {
+		if sub.filter != nil && !sub.filter(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			b.drop(sub)
+		}
+	}
+}
+
+// oldestID is the lowest ID in the replay buffer. Shared events can arrive
+// out of order, so it is not necessarily the first. b.mu must be held.
+func (b *Bus) oldestID() uint64 {
+	oldest := b.nextID
+	for i := 0; i < b.count; i++ {
+		if id := b.ring[(b.start+i)%len(b.ring)].ID; id < oldest {
+			oldest = id
+		}
+	}
+	return oldest
+}
+
+// Subscribe registers a subscriber and returns the buffered events after
+// lastID that pass filter. complete is false when events after lastID have
+// already left the buffer, so the client should refetch full state.
+func (b *Bus) Subscribe(lastID uint64, filter func(Event) bool) (sub *Subscription, replay []Event, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	complete = true
+	if lastID >= b.nextID {
+		// The ID predates a restart; everything buffered is new to the client.
+		complete = false
+		lastID = 0
+	} else if lastID > 0 {
+		complete = lastID+1 >= b.oldestID()
+	}
+
+	for i := 0; i < b.count; i++ {
+		e := b.ring[(b.start+i)%len(b.ring)]
+		if e.ID <= lastID {
+			continue
+		}
+		if filter == nil || filter(e) {
+			replay = append(replay, e)
+		}
+	}
+
+	ch := make(chan Event, subscriberBuffer)
+	sub = &Subscription{C: ch, bus: b, ch: ch, filter: filter}
+	b.subs[sub] = struct{}{}
+
+	return sub, replay, complete
+}
+
+// Close unsubscribes. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.drop(s)
+}
+
+func (b *Bus) drop(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(b.subs, sub)
+	close(sub.ch)
+}
@@ -0,0 +1,215 @@
+package events
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// eventsChannel is the notification channel shared events are
+	// announced on, with their ID as the payload.
+	eventsChannel = "platform_events"
+
+	// eventRetention is how long shared events are kept for replicas
+	// filling their replay buffer.
+	eventRetention = time.Hour
+
+	// relayQueue is how many events may wait to be stored before new ones
+	// are dropped.
+	relayQueue = 1024
+
+	listenerPingInterval = 90 * time.Second
+)
+
+// relay stores published events in the platform_events table.
+type relay struct {
+	db    *sql.DB
+	queue chan Event
+}
+
+func (r *relay) enqueue(e Event) {
+	select {
+	case r.queue <- e:
+	default:
+		log.Printf("Warning: event queue full, dropping %s %s event", e.Topic, e.Type)
+	}
+}
+
+// Share makes the bus span every replica using db. Published events are
+// stored in platform_events, whose ID sequence numbers them for all
+// replicas, and announced with NOTIFY; each replica listens and delivers
+// them to its own subscribers, so a client can resume with Last-Event-ID on
+// any replica. The replay buffer is filled from the table, and again after
+// the listener reconnects. Events with a Key are stored once however many
+// replicas publish them.
+func (b *Bus) Share(db *sql.DB, dsn string) error {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Warning: event listener: %v", err)
+		}
+	})
+	if err := listener.Listen(eventsChannel); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to listen for events: %v", err)
+	}
+	if err := b.load(db); err != nil {
+		listener.Close()
+		return err
+	}
+
+	r := &relay{db: db, queue: make(chan Event, relayQueue)}
+	b.mu.Lock()
+	b.relay = r
+	b.mu.Unlock()
+
+	go r.run()
+	go b.receive(db, listener)
+	return nil
+}
+
+// run stores queued events and prunes those past eventRetention.
+func (r *relay) run() {
+	ticker := time.NewTicker(eventRetention / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case e := <-r.queue:
+			if err := r.store(e); err != nil {
+				log.Printf("Warning: failed to share %s %s event: %v", e.Topic, e.Type, err)
+			}
+		case <-ticker.C:
+			if _, err := r.db.Exec(`DELETE FROM platform_events WHERE created_at < $1`, time.Now().Add(-eventRetention)); err != nil {
+				log.Printf("Warning: failed to prune events: %v", err)
+			}
+		}
+	}
+}
+
+// store inserts the event and announces it in the same statement, so the
+// notification is only sent once the row is visible.
+func (r *relay) store(e Event) error {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %v", err)
+	}
+
+	query := `
+		WITH stored AS (
+			INSERT INTO platform_events (dedup_key, topic, type, tenant_id, cluster, admin_only, data, created_at)
+			VALUES (NULLIF($1, ''), $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT DO NOTHING
+			RETURNING id
+		)
+		SELECT pg_notify($9, id::text) FROM stored
+	`
+	_, err = r.db.Exec(query, e.Key, e.Topic, e.Type, e.TenantID, e.Cluster, e.AdminOnly, data, e.Time, eventsChannel)
+	return err
+}
+
+// receive delivers announced events until the listener is closed.
+func (b *Bus) receive(db *sql.DB, listener *pq.Listener) {
+	for {
+		select {
+		case n, ok := <-listener.Notify:
+			if !ok {
+				return
+			}
+			if n == nil {
+				// Reconnected: notifications sent meanwhile were lost.
+				if err := b.load(db); err != nil {
+					log.Printf("Warning: %v", err)
+				}
+				continue
+			}
+			id, err := strconv.ParseUint(n.Extra, 10, 64)
+			if err != nil {
+				continue
+			}
+			e, err := scanEvent(db.QueryRow(`SELECT `+eventColumns+` FROM platform_events WHERE id = $1`, id))
+			if err != nil {
+				if err != sql.ErrNoRows {
+					log.Printf("Warning: failed to read event %d: %v", id, err)
+				}
+				continue
+			}
+			b.mu.Lock()
+			b.deliver(*e)
+			b.mu.Unlock()
+		case <-time.After(listenerPingInterval):
+			go listener.Ping()
+		}
+	}
+}
+
+// load delivers the stored events from the oldest one buffered onwards,
+// oldest first; those already buffered are skipped.
+func (b *Bus) load(db *sql.DB) error {
+	b.mu.Lock()
+	since := uint64(0)
+	if b.count > 0 {
+		since = b.oldestID()
+	}
+	limit := len(b.ring)
+	b.mu.Unlock()
+	if limit == 0 {
+		return nil
+	}
+
+	query := `
+		SELECT ` + eventColumns + ` FROM (
+			SELECT * FROM platform_events WHERE id >= $1 ORDER BY id DESC LIMIT $2
+		) recent ORDER BY id
+	`
+	rows, err := db.Query(query, since, limit)
+	if err != nil {
+		return fmt.Errorf("failed to load events: %v", err)
+	}
+	defer rows.Close()
+
+	var loaded []Event
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return err
+		}
+		loaded = append(loaded, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to load events: %v", err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, e := range loaded {
+		b.deliver(e)
+	}
+	return nil
+}
+
+const eventColumns = `id, topic, type, tenant_id, cluster, admin_only, data, created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEvent(row rowScanner) (*Event, error) {
+	var e Event
+	var data []byte
+	err := row.Scan(&e.ID, &e.Topic, &e.Type, &e.TenantID, &e.Cluster, &e.AdminOnly, &data, &e.Time)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan event: %v", err)
+	}
+	e.Time = e.Time.UTC()
+	e.Data = json.RawMessage(data)
+	return &e, nil
+}
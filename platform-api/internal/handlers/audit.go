@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"devplatform/platform-api/internal/models"
+	"devplatform/platform-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+	maxAuditExport    = 50000
+)
+
+func ListAuditLog(auditService *services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query models.AuditQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		exporting := query.Format == "csv" || query.Format == "ndjson"
+		switch {
+		case query.Format != "" && query.Format != "json" && !exporting:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "format must be one of json, csv, ndjson"})
+			return
+		case exporting && (query.Limit <= 0 || query.Limit > maxAuditExport):
+			query.Limit = maxAuditExport
+		case !exporting && query.Limit <= 0:
+			query.Limit = defaultAuditLimit
+		case !exporting && query.Limit > maxAuditLimit:
+			query.Limit = maxAuditLimit
+		}
+		if query.Offset < 0 {
+			query.Offset = 0
+		}
+
+		entries, err := auditService.List(&query)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		switch query.Format {
+		case "csv":
+			writeAuditCSV(c, entries)
+		case "ndjson":
+			writeAuditNDJSON(c, entries)
+		default:
+			c.JSON(http.StatusOK, gin.H{
+				"entries": entries,
+				"count":   len(entries),
+				"limit":   query.Limit,
+				"offset":  query.Offset,
+			})
+		}
+	}
+}
+
+func VerifyAuditLog(auditService *services.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := auditService.Verify()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		status := http.StatusOK
+		if !result.Valid {
+			status = http.StatusConflict
+		}
+		c.JSON(status, result)
+	}
+}
+
+func writeAuditCSV(c *gin.Context, entries []models.AuditEntry) {
+	c.Header("Content-Disposition", "attachment; filename="+auditExportName("csv"))
+	c.Header("Content-Type", "text/csv")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"id", "timestamp", "actor", "actor_id", "action", "resource_type", "resource_id",
+		"request_id", "source_ip", "outcome", "status_code", "before", "after", "prev_hash", "hash"})
+	for _, e := range entries {
+		w.Write([]string{
+			strconv.FormatInt(e.ID, 10),
+			e.Timestamp.Format(time.RFC3339Nano),
+			e.Actor,
+			e.ActorID,
+			e.Action,
+			e.ResourceType,
+			e.ResourceID,
+			e.RequestID,
+			e.SourceIP,
+			e.Outcome,
+			strconv.Itoa(e.StatusCode),
+			string(e.Before),
+			string(e.After),
+			e.PrevHash,
+			e.Hash,
+		})
+	}
+	w.Flush()
+}
+
+func writeAuditNDJSON(c *gin.Context, entries []models.AuditEntry) {
+	c.Header("Content-Disposition", "attachment; filename="+auditExportName("ndjson"))
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+
+	enc := json.NewEncoder(c.Writer)
+	for _, e := range entries {
+		enc.Encode(e)
+	}
+}
+
+func auditExportName(ext string) string {
+	return "audit-" + time.Now().UTC().Format("20060102T150405Z") + "." + ext
+}
@@ -1,19 +1,85 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
+	"devplatform/platform-api/internal/middleware"
+	"devplatform/platform-api/internal/models"
 	"devplatform/platform-api/internal/services"
 	"github.com/gin-gonic/gin"
 )
 
+func ListClusters(clusters *services.ClusterRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		registrations, err := clusters.List()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"clusters": registrations,
+			"count":    len(registrations),
+		})
+	}
+}
+
+func RegisterCluster(clusters *services.ClusterRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.ClusterRegistration
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := clusters.Register(&req); err != nil {
+			if errors.Is(err, services.ErrClusterExists) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Cluster already exists"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		middleware.AuditResourceID(c, req.Name)
+		middleware.AuditAfter(c, req)
+
+		c.JSON(http.StatusCreated, gin.H{"cluster": req})
+	}
+}
+
+func RemoveCluster(clusters *services.ClusterRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clusterName := c.Param("name")
+
+		if cluster, err := clusters.Get(clusterName); err == nil {
+			middleware.AuditBefore(c, cluster)
+		}
+
+		if err := clusters.Remove(clusterName); err != nil {
+			switch {
+			case errors.Is(err, services.ErrClusterNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Cluster not found"})
+			case errors.Is(err, services.ErrClusterInUse):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Cluster removed successfully"})
+	}
+}
+
 func GetClusterStatus(k8sService *services.K8sService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		clusterName := c.Param("name")
 
-		status, err := k8sService.GetClusterStatus(clusterName)
+		status, err := k8sService.GetClusterStatus(c.Request.Context(), clusterName)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			clusterError(c, err)
 			return
 		}
 
@@ -27,9 +93,9 @@ func GetClusterNodes(k8sService *services.K8sService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		clusterName := c.Param("name")
 
-		nodes, err := k8sService.GetNodes()
+		nodes, err := k8sService.GetNodes(c.Request.Context(), clusterName)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			clusterError(c, err)
 			return
 		}
 
@@ -45,9 +111,9 @@ func GetNamespaces(k8sService *services.K8sService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		clusterName := c.Param("name")
 
-		namespaces, err := k8sService.GetNamespaces()
+		namespaces, err := k8sService.GetNamespaces(c.Request.Context(), clusterName)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			clusterError(c, err)
 			return
 		}
 
@@ -58,3 +124,25 @@ func GetNamespaces(k8sService *services.K8sService) gin.HandlerFunc {
 		})
 	}
 }
+
+func GetClusterOverview(k8sService *services.K8sService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clusterName := c.Param("name")
+
+		overview, err := k8sService.GetClusterOverview(c.Request.Context(), clusterName)
+		if err != nil {
+			clusterError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, overview)
+	}
+}
+
+func clusterError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrClusterNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cluster not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"devplatform/platform-api/internal/events"
+	"devplatform/platform-api/internal/middleware"
+	"devplatform/platform-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	eventStreamHeartbeat         = 15 * time.Second
+	eventStreamMembershipRefresh = 30 * time.Second
+)
+
+var eventTopics = map[string]bool{
+	events.TopicTenantLifecycle: true,
+	events.TopicNodeReadiness:   true,
+	events.TopicBudgetAlert:     true,
+	events.TopicQuotaBreach:     true,
+	events.TopicInfraRun:        true,
+	events.TopicInfraDrift:      true,
+}
+
+// StreamEvents serves the event bus as server-sent events. Clients select
+// topics with ?topic=a,b and resume with the Last-Event-ID header (or the
+// last_event_id parameter, since EventSource cannot set headers on its first
+// request). Non-admins only receive events for tenants they belong to, plus
+// cluster-wide events that are not restricted to admins.
+func StreamEvents(bus *events.Bus, tenantService *services.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		topics := make(map[string]bool)
+		for _, value := range c.QueryArray("topic") {
+			for _, topic := range strings.Split(value, ",") {
+				topic = strings.TrimSpace(topic)
+				if topic == "" {
+					continue
+				}
+				if !eventTopics[topic] {
+					c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown topic %q", topic)})
+					return
+				}
+				topics[topic] = true
+			}
+		}
+
+		lastEventID := c.GetHeader("Last-Event-ID")
+		if lastEventID == "" {
+			lastEventID = c.Query("last_event_id")
+		}
+		var lastID uint64
+		if lastEventID != "" {
+			id, err := strconv.ParseUint(lastEventID, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Last-Event-ID"})
+				return
+			}
+			lastID = id
+		}
+
+		ctx := c.Request.Context()
+		admin := middleware.HasRole(c, "admin")
+		userID, username := c.GetString("user_id"), c.GetString("username")
+
+		var (
+			mu      sync.RWMutex
+			members map[string]bool
+		)
+		refreshMembers := func() error {
+			ids, err := tenantService.MemberTenantIDs(ctx, userID, username)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			members = ids
+			mu.Unlock()
+			return nil
+		}
+		if !admin {
+			if err := refreshMembers(); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		filter := func(e events.Event) bool {
+			if len(topics) > 0 && !topics[e.Topic] {
+				return false
+			}
+			if admin {
+				return true
+			}
+			if e.AdminOnly {
+				return false
+			}
+			if e.TenantID == "" {
+				return true
+			}
+			mu.RLock()
+			defer mu.RUnlock()
+			return members[e.TenantID]
+		}
+
+		sub, replay, complete := bus.Subscribe(lastID, filter)
+		defer sub.Close()
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+
+		fmt.Fprint(c.Writer, "retry: 3000\n\n")
+		if !complete {
+			// Some events after Last-Event-ID are gone; the client should
+			// reload full state before applying further changes.
+			fmt.Fprint(c.Writer, "event: reset\ndata: {}\n\n")
+		}
+		for _, e := range replay {
+			writeEvent(c, e)
+		}
+		c.Writer.Flush()
+
+		heartbeat := time.NewTicker(eventStreamHeartbeat)
+		defer heartbeat.Stop()
+		refresh := time.NewTicker(eventStreamMembershipRefresh)
+		defer refresh.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-sub.C:
+				if !ok {
+					// Dropped for falling behind; the client reconnects
+					// with Last-Event-ID and resumes from the buffer.
+					return
+				}
+				writeEvent(c, e)
+				c.Writer.Flush()
+			case <-heartbeat.C:
+				fmt.Fprint(c.Writer, ": keepalive\n\n")
+				c.Writer.Flush()
+			case <-refresh.C:
+				if !admin {
+					refreshMembers()
+				}
+			}
+		}
+	}
+}
+
+func writeEvent(c *gin.Context, e events.Event) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Topic, data)
+}
@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"context"
+	"database/sql"
+	"log"
 	"net/http"
 	"time"
 
+	"devplatform/platform-api/internal/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -14,6 +18,13 @@ type HealthResponse struct {
 	Service   string    `json:"service"`
 }
 
+type ReadinessResponse struct {
+	Status    string          `json:"status"`
+	Timestamp time.Time       `json:"timestamp"`
+	Database  string          `json:"database"`
+	Caches    map[string]bool `json:"caches"`
+}
+
 func HealthCheck(c *gin.Context) {
 	response := HealthResponse{
 		Status:    "healthy",
@@ -24,3 +35,37 @@ func HealthCheck(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// ReadinessCheck reports ready once the database answers and the default
+// cluster's informer cache has synced. Other clusters are listed but do not
+// hold back readiness, so one unreachable cluster cannot take the API down.
+func ReadinessCheck(db *sql.DB, clusters *services.ClusterRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response := ReadinessResponse{
+			Status:    "ready",
+			Timestamp: time.Now(),
+			Database:  "ok",
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+		defer cancel()
+		if err := db.PingContext(ctx); err != nil {
+			// The endpoint is public; the error names the database host.
+			log.Printf("Readiness check: database ping failed: %v", err)
+			response.Status = "not ready"
+			response.Database = "unavailable"
+		}
+
+		client, err := clusters.Client("")
+		if err != nil || !client.CacheSynced() {
+			response.Status = "not ready"
+		}
+		response.Caches = clusters.CacheStatus()
+
+		if response.Status != "ready" {
+			c.JSON(http.StatusServiceUnavailable, response)
+			return
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
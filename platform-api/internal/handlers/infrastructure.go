@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"devplatform/platform-api/internal/middleware"
+	"devplatform/platform-api/internal/models"
+	"devplatform/platform-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func ListInfrastructureRuns(infraService *services.InfrastructureService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		runs, err := infraService.ListRuns(c.Request.Context(), id)
+		if err != nil {
+			infrastructureError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"runs":  runs,
+			"count": len(runs),
+		})
+	}
+}
+
+func CreateInfrastructureRun(infraService *services.InfrastructureService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		var req models.CreateInfrastructureRunRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		run, err := infraService.StartRun(c.Request.Context(), id, &req, c.GetString("username"))
+		if err != nil {
+			infrastructureError(c, err)
+			return
+		}
+
+		middleware.AuditAfter(c, run)
+
+		c.JSON(http.StatusAccepted, gin.H{"run": run})
+	}
+}
+
+func GetInfrastructureRun(infraService *services.InfrastructureService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		run, err := infraService.GetRun(c.Request.Context(), id, c.Param("run_id"))
+		if err != nil {
+			infrastructureError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"run": run})
+	}
+}
+
+func GetInfrastructureRunLogs(infraService *services.InfrastructureService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		logs, err := infraService.RunLogs(c.Request.Context(), id, c.Param("run_id"))
+		if err != nil {
+			infrastructureError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, logs)
+	}
+}
+
+// GetInfrastructureRunPlan returns the run's plan as `terraform show -json`
+// output. The plan carries sensitive variables and prior state unredacted,
+// so like revealing outputs it is limited to admins and tenant owners and
+// every request is audited.
+func GetInfrastructureRunPlan(infraService *services.InfrastructureService, tenantService *services.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		middleware.AuditRead(c, "infrastructure.run.plan", "tenant", id.String())
+		if !middleware.HasRole(c, "admin") {
+			owner, err := tenantService.IsTenantOwner(c.Request.Context(), id, c.GetString("user_id"), c.GetString("username"))
+			if err != nil {
+				infrastructureError(c, err)
+				return
+			}
+			if !owner {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Only tenant owners may read run plans"})
+				return
+			}
+		}
+
+		plan, err := infraService.PlanJSON(c.Request.Context(), id, c.Param("run_id"))
+		if err != nil {
+			infrastructureError(c, err)
+			return
+		}
+
+		c.Data(http.StatusOK, "application/json", plan)
+	}
+}
+
+// ConfirmInfrastructureRun applies a run waiting for confirmation, or
+// discards it when apply is false.
+func ConfirmInfrastructureRun(infraService *services.InfrastructureService, apply bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		var req models.InfrastructureRunAction
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		var run *models.InfrastructureRun
+		if apply {
+			run, err = infraService.ApplyRun(c.Request.Context(), id, c.Param("run_id"), req.Comment)
+		} else {
+			run, err = infraService.DiscardRun(c.Request.Context(), id, c.Param("run_id"), req.Comment)
+		}
+		if err != nil {
+			infrastructureError(c, err)
+			return
+		}
+
+		middleware.AuditAfter(c, run)
+
+		c.JSON(http.StatusAccepted, gin.H{"run": run})
+	}
+}
+
+// GetInfrastructureOutputs returns the tenant's Terraform outputs. Sensitive
+// values are only revealed with ?reveal=true, and only to admins and tenant
+// owners; every attempt to reveal them is audited.
+func GetInfrastructureOutputs(infraService *services.InfrastructureService, tenantService *services.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		reveal := c.Query("reveal") == "true"
+		if reveal {
+			middleware.AuditRead(c, "infrastructure.outputs.reveal", "tenant", id.String())
+		}
+		if reveal && !middleware.HasRole(c, "admin") {
+			owner, err := tenantService.IsTenantOwner(c.Request.Context(), id, c.GetString("user_id"), c.GetString("username"))
+			if err != nil {
+				infrastructureError(c, err)
+				return
+			}
+			if !owner {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Only tenant owners may reveal sensitive outputs"})
+				return
+			}
+		}
+
+		outputs, err := infraService.Outputs(c.Request.Context(), id, reveal)
+		if err != nil {
+			infrastructureError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"outputs": outputs,
+			"count":   len(outputs),
+		})
+	}
+}
+
+func SyncInfrastructureOutputs(infraService *services.InfrastructureService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		keys, err := infraService.SyncOutputs(c.Request.Context(), id)
+		if err != nil {
+			infrastructureError(c, err)
+			return
+		}
+
+		middleware.AuditAfter(c, gin.H{"keys": keys})
+
+		c.JSON(http.StatusOK, gin.H{
+			"config_map": "infrastructure-outputs",
+			"keys":       keys,
+		})
+	}
+}
+
+// GetInfrastructureDrift returns the results of scheduled drift detection.
+func GetInfrastructureDrift(infraService *services.InfrastructureService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		drift, err := infraService.Drift(c.Request.Context(), id)
+		if err != nil {
+			infrastructureError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"drift": drift})
+	}
+}
+
+func infrastructureError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "tenant not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tenant not found"})
+	case errors.Is(err, services.ErrRunNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Run not found"})
+	case errors.Is(err, services.ErrRunNotConfirmable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPlanNotReady), errors.Is(err, services.ErrPolicyFailed), errors.Is(err, services.ErrConfigurationConflict),
+		errors.Is(err, services.ErrTenantBeingDeleted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidConfiguration):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInfrastructureDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"devplatform/platform-api/internal/middleware"
+	"devplatform/platform-api/internal/models"
+	"devplatform/platform-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func CordonNode(nodeService *services.NodeService, unschedulable bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clusterName, nodeName := c.Param("name"), c.Param("node")
+		auditNode(c, nodeService, clusterName, nodeName)
+
+		node, err := nodeService.Cordon(c.Request.Context(), clusterName, nodeName, unschedulable)
+		if err != nil {
+			nodeError(c, err)
+			return
+		}
+
+		middleware.AuditAfter(c, node)
+
+		c.JSON(http.StatusOK, gin.H{"node": node})
+	}
+}
+
+func DrainNode(nodeService *services.NodeService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clusterName, nodeName := c.Param("name"), c.Param("node")
+
+		var req models.DrainRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		auditNode(c, nodeService, clusterName, nodeName)
+
+		op, err := nodeService.Drain(c.Request.Context(), clusterName, nodeName, &req, c.GetString("username"))
+		if err != nil {
+			nodeError(c, err)
+			return
+		}
+
+		middleware.AuditAfter(c, op)
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"operation": op,
+			"message":   "Drain started",
+		})
+	}
+}
+
+func ListNodeOperations(nodeService *services.NodeService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ops, err := nodeService.ListOperations(c.Param("name"), c.Param("node"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"operations": ops,
+			"count":      len(ops),
+		})
+	}
+}
+
+func GetNodeOperation(nodeService *services.NodeService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("operation"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid operation ID"})
+			return
+		}
+
+		op, err := nodeService.GetOperation(c.Param("name"), c.Param("node"), id)
+		if err != nil {
+			nodeError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"operation": op})
+	}
+}
+
+func PatchNodeLabels(nodeService *services.NodeService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clusterName, nodeName := c.Param("name"), c.Param("node")
+
+		var req models.NodeLabelsPatch
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		auditNode(c, nodeService, clusterName, nodeName)
+
+		node, err := nodeService.PatchLabels(c.Request.Context(), clusterName, nodeName, req.Labels)
+		if err != nil {
+			nodeError(c, err)
+			return
+		}
+
+		middleware.AuditAfter(c, node)
+
+		c.JSON(http.StatusOK, gin.H{"node": node})
+	}
+}
+
+func PatchNodeTaints(nodeService *services.NodeService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clusterName, nodeName := c.Param("name"), c.Param("node")
+
+		var req models.NodeTaintsPatch
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(req.Add) == 0 && len(req.Remove) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to add or remove"})
+			return
+		}
+
+		auditNode(c, nodeService, clusterName, nodeName)
+
+		node, err := nodeService.PatchTaints(c.Request.Context(), clusterName, nodeName, &req)
+		if err != nil {
+			nodeError(c, err)
+			return
+		}
+
+		middleware.AuditAfter(c, node)
+
+		c.JSON(http.StatusOK, gin.H{"node": node})
+	}
+}
+
+// auditNode names the node by cluster in the audit record and snapshots its
+// state before the change.
+func auditNode(c *gin.Context, nodeService *services.NodeService, clusterName, nodeName string) {
+	middleware.AuditResourceID(c, clusterName+"/"+nodeName)
+	if node, err := nodeService.GetNode(c.Request.Context(), clusterName, nodeName); err == nil {
+		middleware.AuditBefore(c, node)
+	}
+}
+
+func nodeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrClusterNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cluster not found"})
+	case errors.Is(err, services.ErrNodeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Node not found"})
+	case errors.Is(err, services.ErrNodeOperationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Operation not found"})
+	case errors.Is(err, services.ErrDrainBlocked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
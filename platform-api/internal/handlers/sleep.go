@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"devplatform/platform-api/internal/middleware"
+	"devplatform/platform-api/internal/models"
+	"devplatform/platform-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func ListSleepSchedules(sleepService *services.SleepService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		schedules, err := sleepService.ListSchedules()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"schedules": schedules,
+			"count":     len(schedules),
+		})
+	}
+}
+
+func SaveSleepSchedule(sleepService *services.SleepService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.SleepScheduleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		schedule, err := sleepService.SaveSchedule(c.Request.Context(), &req)
+		if err != nil {
+			sleepError(c, err)
+			return
+		}
+
+		middleware.AuditResourceID(c, schedule.ID.String())
+		middleware.AuditAfter(c, schedule)
+
+		c.JSON(http.StatusOK, gin.H{"schedule": schedule})
+	}
+}
+
+func DeleteSleepSchedule(sleepService *services.SleepService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+			return
+		}
+
+		if err := sleepService.DeleteSchedule(id); err != nil {
+			sleepError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Sleep schedule deleted successfully"})
+	}
+}
+
+func GetTenantSleep(sleepService *services.SleepService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		status, err := sleepService.Status(c.Request.Context(), id)
+		if err != nil {
+			sleepError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, status)
+	}
+}
+
+// SetTenantSleep puts the tenant to sleep or wakes it, depending on asleep.
+func SetTenantSleep(sleepService *services.SleepService, asleep bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		var result *models.SleepResult
+		if asleep {
+			result, err = sleepService.Sleep(c.Request.Context(), id)
+		} else {
+			result, err = sleepService.Wake(c.Request.Context(), id)
+		}
+		if err != nil {
+			sleepError(c, err)
+			return
+		}
+
+		middleware.AuditAfter(c, result)
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+func sleepError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "tenant not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tenant not found"})
+	case errors.Is(err, services.ErrSleepScheduleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sleep schedule not found"})
+	case errors.Is(err, services.ErrInvalidSleepSchedule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTenantNotActive):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrClusterNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cluster not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"devplatform/platform-api/internal/middleware"
+	"devplatform/platform-api/internal/models"
+	"devplatform/platform-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func ListTemplates(templateService *services.TemplateService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		templates, err := templateService.ListTemplates(c.Request.Context(), c.Query("name"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"templates": templates,
+			"count":     len(templates),
+		})
+	}
+}
+
+// GetTemplate returns the latest version of a template, or the one given by
+// the version query parameter.
+func GetTemplate(templateService *services.TemplateService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		template, err := templateService.GetTemplate(c.Request.Context(), c.Param("name"), c.Query("version"))
+		if err != nil {
+			templateError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"template": template})
+	}
+}
+
+func CreateTemplate(templateService *services.TemplateService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.CreateTemplateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		template, err := templateService.CreateTemplate(c.Request.Context(), &req, c.GetString("username"))
+		if err != nil {
+			templateError(c, err)
+			return
+		}
+
+		middleware.AuditResourceID(c, template.Name+"@"+template.Version)
+		middleware.AuditAfter(c, template)
+
+		c.JSON(http.StatusCreated, gin.H{"template": template})
+	}
+}
+
+func ListTenantResources(templateService *services.TemplateService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		resources, err := templateService.ListResources(c.Request.Context(), id)
+		if err != nil {
+			templateError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"resources": resources,
+			"count":     len(resources),
+		})
+	}
+}
+
+// CreateTenantResource provisions a template for the tenant and returns the
+// run that applies it.
+func CreateTenantResource(templateService *services.TemplateService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		var req models.CreateTenantResourceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		resource, run, err := templateService.CreateResource(c.Request.Context(), id, &req, c.GetString("username"))
+		if err != nil {
+			templateError(c, err)
+			return
+		}
+
+		middleware.AuditAfter(c, resource)
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"resource": resource,
+			"run":      run,
+		})
+	}
+}
+
+// DeleteTenantResource removes a resource from the tenant and returns the run
+// that destroys it. auto_apply=true applies the run without confirmation.
+func DeleteTenantResource(templateService *services.TemplateService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		resource, run, err := templateService.DeleteResource(c.Request.Context(), id, c.Param("name"), c.Query("auto_apply") == "true", c.GetString("username"))
+		if err != nil {
+			templateError(c, err)
+			return
+		}
+
+		middleware.AuditBefore(c, resource)
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"resource": resource,
+			"run":      run,
+		})
+	}
+}
+
+func templateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrTemplateNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+	case errors.Is(err, services.ErrResourceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
+	case errors.Is(err, services.ErrTemplateExists), errors.Is(err, services.ErrResourceExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTemplateNotAllowed):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidTemplate), errors.Is(err, services.ErrInvalidInputs):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		infrastructureError(c, err)
+	}
+}
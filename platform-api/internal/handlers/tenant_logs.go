@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"devplatform/platform-api/internal/models"
+	"devplatform/platform-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func GetTenantEvents(tenantService *services.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		var q models.EventQuery
+		if err := c.ShouldBindQuery(&q); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		events, total, err := tenantService.GetTenantEvents(c.Request.Context(), id, &q)
+		if err != nil {
+			tenantStreamError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"events": events,
+			"count":  len(events),
+			"total":  total,
+		})
+	}
+}
+
+// StreamPodLogs relays a pod's logs as chunked plain text, or as server-sent
+// events when the client asks for text/event-stream or format=sse.
+func StreamPodLogs(tenantService *services.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		var q models.PodLogQuery
+		if err := c.ShouldBindQuery(&q); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		stream, err := tenantService.StreamPodLogs(c.Request.Context(), id, c.Param("pod"), &q)
+		if err != nil {
+			tenantStreamError(c, err)
+			return
+		}
+		defer stream.Close()
+
+		sse := c.Query("format") == "sse" || strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+		if sse {
+			c.Header("Content-Type", "text/event-stream")
+			c.Header("Cache-Control", "no-cache")
+		} else {
+			c.Header("Content-Type", "text/plain; charset=utf-8")
+		}
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+
+		reader := bufio.NewReader(stream)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				if sse {
+					writeLogEvent(c.Writer, line)
+				} else {
+					c.Writer.Write(line)
+				}
+				c.Writer.Flush()
+			}
+			if err != nil {
+				if sse && errors.Is(err, io.EOF) {
+					io.WriteString(c.Writer, "event: end\ndata: \n\n")
+					c.Writer.Flush()
+				}
+				return
+			}
+		}
+	}
+}
+
+func writeLogEvent(w io.Writer, line []byte) {
+	line = bytes.TrimRight(line, "\r\n")
+	io.WriteString(w, "data: ")
+	w.Write(line)
+	io.WriteString(w, "\n\n")
+}
+
+func tenantStreamError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "tenant not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tenant not found"})
+	case errors.Is(err, services.ErrPodNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pod not found"})
+	case errors.Is(err, services.ErrInvalidQuery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
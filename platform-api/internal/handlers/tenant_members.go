@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"devplatform/platform-api/internal/middleware"
+	"devplatform/platform-api/internal/models"
+	"devplatform/platform-api/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func ListTenantMembers(tenantService *services.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		members, err := tenantService.ListMembers(id)
+		if err != nil {
+			if err.Error() == "tenant not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Tenant not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"members": members,
+			"count":   len(members),
+		})
+	}
+}
+
+func AddTenantMember(tenantService *services.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		var member models.TenantMember
+		if err := c.ShouldBindJSON(&member); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		member.TenantID = id
+
+		if err := tenantService.AddMember(&member); err != nil {
+			if err.Error() == "tenant not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Tenant not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		middleware.AuditAfter(c, member)
+
+		c.JSON(http.StatusCreated, gin.H{"member": member})
+	}
+}
+
+func RemoveTenantMember(tenantService *services.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		if err := tenantService.RemoveMember(id, c.Param("user")); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+	}
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
+	"devplatform/platform-api/internal/middleware"
 	"devplatform/platform-api/internal/models"
 	"devplatform/platform-api/internal/services"
 	"github.com/gin-gonic/gin"
@@ -11,7 +13,7 @@ import (
 
 func ListTenants(tenantService *services.TenantService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tenants, err := tenantService.ListTenants()
+		tenants, err := tenantService.ListTenants(c.Request.Context())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -32,12 +34,19 @@ func CreateTenant(tenantService *services.TenantService) gin.HandlerFunc {
 			return
 		}
 
-		tenant, err := tenantService.CreateTenant(&req)
+		tenant, err := tenantService.CreateTenant(c.Request.Context(), &req)
 		if err != nil {
+			if errors.Is(err, services.ErrClusterNotFound) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown cluster"})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
+		middleware.AuditResourceID(c, tenant.ID.String())
+		middleware.AuditAfter(c, tenant)
+
 		c.JSON(http.StatusCreated, gin.H{
 			"tenant":  tenant,
 			"message": "Tenant created successfully",
@@ -54,7 +63,7 @@ func GetTenant(tenantService *services.TenantService) gin.HandlerFunc {
 			return
 		}
 
-		tenant, err := tenantService.GetTenant(id)
+		tenant, err := tenantService.GetTenant(c.Request.Context(), id)
 		if err != nil {
 			if err.Error() == "tenant not found" {
 				c.JSON(http.StatusNotFound, gin.H{"error": "Tenant not found"})
@@ -77,18 +86,56 @@ func DeleteTenant(tenantService *services.TenantService) gin.HandlerFunc {
 			return
 		}
 
-		err = tenantService.DeleteTenant(id)
+		if tenant, err := tenantService.GetTenant(c.Request.Context(), id); err == nil {
+			middleware.AuditBefore(c, tenant)
+		}
+
+		deleted, err := tenantService.DeleteTenant(c.Request.Context(), id, c.GetString("username"))
 		if err != nil {
 			if err.Error() == "tenant not found" {
 				c.JSON(http.StatusNotFound, gin.H{"error": "Tenant not found"})
 				return
 			}
+			if errors.Is(err, services.ErrTenantDeleting) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
+		if !deleted {
+			c.JSON(http.StatusAccepted, gin.H{
+				"message": "Tenant deletion started; its infrastructure is being destroyed",
+			})
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"message": "Tenant deleted successfully",
 		})
 	}
 }
+
+func GetTenantWorkloads(tenantService *services.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idStr := c.Param("id")
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			return
+		}
+
+		workloads, err := tenantService.GetTenantWorkloads(c.Request.Context(), id)
+		if err != nil {
+			if err.Error() == "tenant not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Tenant not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"workloads": workloads})
+	}
+}
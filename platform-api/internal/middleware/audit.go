@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"devplatform/platform-api/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	auditActionKey       = "audit_action"
+	auditResourceTypeKey = "audit_resource_type"
+	auditResourceIDKey   = "audit_resource_id"
+	auditBeforeKey       = "audit_before"
+	auditAfterKey        = "audit_after"
+	auditReadKey         = "audit_read"
+)
+
+type AuditRecorder interface {
+	Record(entry *models.AuditEntry) error
+}
+
+// Audit records every mutating request once the handler chain has finished,
+// and reads that a handler marks with AuditRead. It must run before
+// AuthRequired so rejected attempts are captured as well.
+func Audit(recorder AuditRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if !isMutatingMethod(c.Request.Method) && !c.GetBool(auditReadKey) {
+			return
+		}
+
+		entry := &models.AuditEntry{
+			Actor:        c.GetString("username"),
+			ActorID:      c.GetString("user_id"),
+			Action:       c.GetString(auditActionKey),
+			ResourceType: c.GetString(auditResourceTypeKey),
+			ResourceID:   c.GetString(auditResourceIDKey),
+			RequestID:    c.GetString("request_id"),
+			SourceIP:     c.ClientIP(),
+			Outcome:      auditOutcome(c.Writer.Status()),
+			StatusCode:   c.Writer.Status(),
+		}
+
+		if entry.Actor == "" {
+			entry.Actor = "anonymous"
+		}
+		if entry.Action == "" {
+			entry.Action = c.Request.Method + " " + c.FullPath()
+		}
+		if before, ok := c.Get(auditBeforeKey); ok {
+			entry.Before = before.(json.RawMessage)
+		}
+		if after, ok := c.Get(auditAfterKey); ok {
+			entry.After = after.(json.RawMessage)
+		}
+
+		if err := recorder.Record(entry); err != nil {
+			log.Printf("Failed to record audit entry for request %s: %v", entry.RequestID, err)
+		}
+	}
+}
+
+// AuditAs names the action and target of a route. idParam is the path
+// parameter holding the resource identifier and may be empty for creates,
+// where the handler reports the new ID through AuditResourceID.
+func AuditAs(action, resourceType, idParam string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(auditActionKey, action)
+		c.Set(auditResourceTypeKey, resourceType)
+		if idParam != "" {
+			c.Set(auditResourceIDKey, c.Param(idParam))
+		}
+		c.Next()
+	}
+}
+
+// AuditRead records a read that exposes something sensitive, such as
+// secret values, which Audit otherwise skips. The outcome is taken from the
+// response as for other requests, so call it before any permission check.
+func AuditRead(c *gin.Context, action, resourceType, id string) {
+	c.Set(auditReadKey, true)
+	c.Set(auditActionKey, action)
+	c.Set(auditResourceTypeKey, resourceType)
+	c.Set(auditResourceIDKey, id)
+}
+
+func AuditResourceID(c *gin.Context, id string) {
+	c.Set(auditResourceIDKey, id)
+}
+
+func AuditBefore(c *gin.Context, state interface{}) {
+	setAuditSnapshot(c, auditBeforeKey, state)
+}
+
+func AuditAfter(c *gin.Context, state interface{}) {
+	setAuditSnapshot(c, auditAfterKey, state)
+}
+
+func setAuditSnapshot(c *gin.Context, key string, state interface{}) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		log.Printf("Failed to marshal audit snapshot: %v", err)
+		return
+	}
+	c.Set(key, json.RawMessage(data))
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func auditOutcome(status int) string {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return "denied"
+	case status >= 400:
+		return "failure"
+	}
+	return "success"
+}
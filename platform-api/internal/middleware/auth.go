@@ -12,6 +12,11 @@ import (
 
 func AuthRequired(jwtSecret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if c.GetString("auth_method") == "mtls" {
+			c.Next()
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
@@ -50,6 +55,8 @@ func AuthRequired(jwtSecret string) gin.HandlerFunc {
 
 			c.Set("user_id", claims["sub"])
 			c.Set("username", claims["username"])
+			c.Set("roles", claimRoles(claims))
+			c.Set("auth_method", "jwt")
 			c.Next()
 		} else {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
@@ -59,13 +66,79 @@ func AuthRequired(jwtSecret string) gin.HandlerFunc {
 	}
 }
 
-func GenerateToken(userID, username, jwtSecret string) (string, error) {
+// ClientCertAuth maps a verified TLS client certificate onto the same context
+// keys AuthRequired sets from a JWT. identities maps certificate common names
+// to roles; when it is non-empty, certificates with other names are ignored
+// and the caller must still present a bearer token.
+func ClientCertAuth(identities map[string][]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+			c.Next()
+			return
+		}
+
+		leaf := c.Request.TLS.VerifiedChains[0][0]
+		name := leaf.Subject.CommonName
+		if name == "" && len(leaf.DNSNames) > 0 {
+			name = leaf.DNSNames[0]
+		}
+
+		roles, known := identities[name]
+		if name == "" || (len(identities) > 0 && !known) {
+			c.Next()
+			return
+		}
+
+		c.Set("user_id", "cert:"+name)
+		c.Set("username", name)
+		c.Set("roles", roles)
+		c.Set("auth_method", "mtls")
+		c.Next()
+	}
+}
+
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasRole(c, role) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func HasRole(c *gin.Context, role string) bool {
+	for _, r := range c.GetStringSlice("roles") {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func claimRoles(claims jwt.MapClaims) []string {
+	var roles []string
+	if values, ok := claims["roles"].([]interface{}); ok {
+		for _, v := range values {
+			if role, ok := v.(string); ok {
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles
+}
+
+func GenerateToken(userID, username, jwtSecret string, roles ...string) (string, error) {
 	claims := jwt.MapClaims{
 		"sub":      userID,
 		"username": username,
 		"iat":      time.Now().Unix(),
 		"exp":      time.Now().Add(24 * time.Hour).Unix(),
 	}
+	if len(roles) > 0 {
+		claims["roles"] = roles
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(jwtSecret))
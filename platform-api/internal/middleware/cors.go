@@ -1,27 +1,138 @@
 package middleware
 
 import (
-    "github.com/gin-gonic/gin"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
 )
 
-func CORS() gin.HandlerFunc {
-    return gin.HandlerFunc(func(c *gin.Context) {
-        // Replace with your actual domain when deploying
-        origin := "https://iasolutions.co.uk"
-        if gin.Mode() == gin.DebugMode {
-            origin = "*" // Only allow * in development
-        }
-        
-        c.Header("Access-Control-Allow-Origin", origin)
-        c.Header("Access-Control-Allow-Credentials", "true")
-        c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
-        c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-
-        if c.Request.Method == "OPTIONS" {
-            c.AbortWithStatus(204)
-            return
-        }
-
-        c.Next()
-    })
+type CORSOptions struct {
+	// AllowedOrigins holds exact origins such as "https://portal.example.com"
+	// or wildcard subdomain patterns such as "https://*.example.com".
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           int
+}
+
+type originPattern struct {
+	scheme string
+	host   string
+	port   string
+	suffix bool
+}
+
+func CORS(opts CORSOptions) gin.HandlerFunc {
+	var patterns []originPattern
+	allowAny := false
+	for _, origin := range opts.AllowedOrigins {
+		if origin == "*" {
+			allowAny = true
+			continue
+		}
+		if p, ok := parseOriginPattern(origin); ok {
+			patterns = append(patterns, p)
+		}
+	}
+
+	// A literal "*" cannot be combined with credentials, so it is only
+	// honoured when credentials are disabled.
+	allowAny = allowAny && !opts.AllowCredentials
+
+	allowMethods := strings.Join(opts.AllowedMethods, ", ")
+	allowHeaders := strings.Join(opts.AllowedHeaders, ", ")
+	exposeHeaders := strings.Join(opts.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(opts.MaxAge)
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		if preflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		allowed := allowAny || matchOrigin(patterns, origin)
+		if !allowed {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if allowAny {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if opts.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", allowMethods)
+			c.Header("Access-Control-Allow-Headers", allowHeaders)
+			if opts.MaxAge > 0 {
+				c.Header("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if exposeHeaders != "" {
+			c.Header("Access-Control-Expose-Headers", exposeHeaders)
+		}
+
+		c.Next()
+	}
+}
+
+func parseOriginPattern(origin string) (originPattern, bool) {
+	u, err := url.Parse(strings.ToLower(strings.TrimSuffix(origin, "/")))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return originPattern{}, false
+	}
+
+	p := originPattern{scheme: u.Scheme, host: u.Hostname(), port: u.Port()}
+	if strings.HasPrefix(p.host, "*.") {
+		p.suffix = true
+		p.host = p.host[1:]
+	}
+	return p, true
+}
+
+func matchOrigin(patterns []originPattern, origin string) bool {
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Host == "" || u.Path != "" {
+		return false
+	}
+
+	host := u.Hostname()
+	for _, p := range patterns {
+		if u.Scheme != p.scheme || u.Port() != p.port {
+			continue
+		}
+		if p.suffix {
+			if strings.HasSuffix(host, p.host) && len(host) > len(p.host) {
+				return true
+			}
+		} else if host == p.host {
+			return true
+		}
+	}
+	return false
 }
@@ -9,8 +9,15 @@ import (
 
 func RequestLogger() gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		return fmt.Sprintf("%s - [%s] \"%s %s %s %d %s \"%s\" %s\"\n",
+		user := "-"
+		if username, ok := param.Keys["username"].(string); ok && username != "" {
+			user = username
+		}
+		requestID, _ := param.Keys["request_id"].(string)
+
+		return fmt.Sprintf("%s - %s [%s] \"%s %s %s %d %s \"%s\" %s\" %s\n",
 			param.ClientIP,
+			user,
 			param.TimeStamp.Format(time.RFC1123),
 			param.Method,
 			param.Path,
@@ -19,6 +26,7 @@ func RequestLogger() gin.HandlerFunc {
 			param.Latency,
 			param.Request.UserAgent(),
 			param.ErrorMessage,
+			requestID,
 		)
 	})
 }
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"devplatform/platform-api/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+type RateLimitOptions struct {
+	Read  ratelimit.Limit
+	Write ratelimit.Limit
+	Cost  ratelimit.Limit
+}
+
+// RateLimit applies a token bucket per identity and route class. It must run
+// after AuthRequired so that authenticated callers are keyed by user.
+// Requests with no identity at all are rejected rather than sharing a bucket.
+func RateLimit(store ratelimit.Store, opts RateLimitOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity := rateLimitIdentity(c)
+		if identity == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unable to identify caller"})
+			c.Abort()
+			return
+		}
+		class, limit := routeClass(c, opts)
+		key := class + ":" + identity
+
+		result, err := store.Allow(key, limit)
+		if err != nil {
+			// Fail open: an unavailable store should not take the API down.
+			log.Printf("Rate limit store error for %s: %v", key, err)
+			c.Next()
+			return
+		}
+
+		window := int(math.Ceil(float64(limit.Burst) / limit.Rate))
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Burst, window))
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":       "Rate limit exceeded",
+				"class":       class,
+				"retry_after": retryAfter,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func routeClass(c *gin.Context, opts RateLimitOptions) (string, ratelimit.Limit) {
+	if strings.Contains(c.FullPath(), "/costs") {
+		return "cost", opts.Cost
+	}
+	if isMutatingMethod(c.Request.Method) {
+		return "write", opts.Write
+	}
+	return "read", opts.Read
+}
+
+// rateLimitIdentity keys buckets by the authenticated user, falling back to
+// the API key and then the client IP for callers without one, such as a JWT
+// whose sub claim is missing or not a string. It returns "" when none is
+// known.
+func rateLimitIdentity(c *gin.Context) string {
+	if userID := c.GetString("user_id"); userID != "" {
+		return "user:" + userID
+	}
+	if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+		sum := sha256.Sum256([]byte(apiKey))
+		return "key:" + hex.EncodeToString(sum[:8])
+	}
+	if ip := c.ClientIP(); ip != "" {
+		return "ip:" + ip
+	}
+	return ""
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TenantMembershipChecker interface {
+	IsTenantMember(ctx context.Context, tenantID uuid.UUID, userID, username string) (bool, error)
+}
+
+// RequireTenantMember restricts a route with an :id tenant parameter to the
+// tenant's owner and members. Admins are always allowed.
+func RequireTenantMember(checker TenantMembershipChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if HasRole(c, "admin") {
+			c.Next()
+			return
+		}
+
+		tenantID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant ID"})
+			c.Abort()
+			return
+		}
+
+		member, err := checker.IsTenantMember(c.Request.Context(), tenantID, c.GetString("user_id"), c.GetString("username"))
+		if err != nil {
+			if err.Error() == "tenant not found" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Tenant not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			c.Abort()
+			return
+		}
+
+		if !member {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not a member of this tenant"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditEntry struct {
+	ID           int64           `json:"id" db:"id"`
+	Timestamp    time.Time       `json:"timestamp" db:"timestamp"`
+	Actor        string          `json:"actor" db:"actor"`
+	ActorID      string          `json:"actor_id" db:"actor_id"`
+	Action       string          `json:"action" db:"action"`
+	ResourceType string          `json:"resource_type" db:"resource_type"`
+	ResourceID   string          `json:"resource_id" db:"resource_id"`
+	RequestID    string          `json:"request_id" db:"request_id"`
+	SourceIP     string          `json:"source_ip" db:"source_ip"`
+	Before       json.RawMessage `json:"before,omitempty" db:"before_state"`
+	After        json.RawMessage `json:"after,omitempty" db:"after_state"`
+	Outcome      string          `json:"outcome" db:"outcome"`
+	StatusCode   int             `json:"status_code" db:"status_code"`
+	PrevHash     string          `json:"prev_hash" db:"prev_hash"`
+	Hash         string          `json:"hash" db:"hash"`
+}
+
+type AuditQuery struct {
+	Actor        string `form:"actor"`
+	Action       string `form:"action"`
+	ResourceType string `form:"resource_type"`
+	ResourceID   string `form:"resource_id"`
+	RequestID    string `form:"request_id"`
+	Outcome      string `form:"outcome"`
+	Since        string `form:"since"`
+	Until        string `form:"until"`
+	Limit        int    `form:"limit"`
+	Offset       int    `form:"offset"`
+	Format       string `form:"format"`
+}
+
+type AuditVerification struct {
+	Valid     bool      `json:"valid"`
+	Entries   int       `json:"entries"`
+	BrokenAt  int64     `json:"broken_at,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
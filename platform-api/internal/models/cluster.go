@@ -2,29 +2,98 @@ package models
 
 import (
 	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ClusterSourceKubeconfig = "kubeconfig"
+	ClusterSourceEKS        = "eks"
+	ClusterSourceInCluster  = "in-cluster"
 )
 
+// ClusterRegistration describes how to reach a cluster: a kubeconfig file and
+// context, an EKS cluster looked up through the AWS API, or the cluster the
+// API itself runs in.
+type ClusterRegistration struct {
+	Name           string    `json:"name" db:"name" binding:"required"`
+	Source         string    `json:"source" db:"source" binding:"required,oneof=kubeconfig eks in-cluster"`
+	KubeConfig     string    `json:"kubeconfig,omitempty" db:"kubeconfig"`
+	Context        string    `json:"context,omitempty" db:"context"`
+	EKSClusterName string    `json:"eks_cluster_name,omitempty" db:"eks_cluster_name"`
+	Region         string    `json:"region,omitempty" db:"region"`
+	Default        bool      `json:"default" db:"is_default"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
 type ClusterStatus struct {
-	Name       string    `json:"name"`
-	Status     string    `json:"status"`
-	Version    string    `json:"version"`
-	Endpoint   string    `json:"endpoint"`
-	NodeCount  int       `json:"node_count"`
-	LastUpdate time.Time `json:"last_update"`
+	Name       string      `json:"name"`
+	Status     string      `json:"status"`
+	Version    string      `json:"version"`
+	Endpoint   string      `json:"endpoint"`
+	NodeCount  int         `json:"node_count"`
+	LastUpdate time.Time   `json:"last_update"`
+	EKS        *EKSDetails `json:"eks,omitempty"`
+	EKSError   string      `json:"eks_error,omitempty"`
+}
+
+type EKSDetails struct {
+	ClusterName       string          `json:"cluster_name"`
+	ARN               string          `json:"arn"`
+	Status            string          `json:"status"`
+	KubernetesVersion string          `json:"kubernetes_version"`
+	PlatformVersion   string          `json:"platform_version"`
+	Endpoint          string          `json:"endpoint"`
+	EndpointAccess    string          `json:"endpoint_access"`
+	PublicAccessCIDRs []string        `json:"public_access_cidrs,omitempty"`
+	Logging           []EKSLogSetting `json:"logging"`
+	Addons            []EKSAddon      `json:"addons"`
+	NodeGroups        []EKSNodeGroup  `json:"node_groups"`
+	CreatedAt         time.Time       `json:"created_at"`
+}
+
+type EKSLogSetting struct {
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
+
+type EKSAddon struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Status  string `json:"status"`
+}
+
+type EKSNodeGroup struct {
+	Name           string           `json:"name"`
+	Status         string           `json:"status"`
+	Version        string           `json:"version"`
+	ReleaseVersion string           `json:"release_version"`
+	AMIType        string           `json:"ami_type"`
+	CapacityType   string           `json:"capacity_type"`
+	InstanceTypes  []string         `json:"instance_types"`
+	Scaling        EKSScalingConfig `json:"scaling"`
+}
+
+type EKSScalingConfig struct {
+	MinSize     int32 `json:"min_size"`
+	MaxSize     int32 `json:"max_size"`
+	DesiredSize int32 `json:"desired_size"`
 }
 
 type NodeInfo struct {
-	Name         string            `json:"name"`
-	Status       string            `json:"status"`
-	Role         string            `json:"role"`
-	Version      string            `json:"version"`
-	InstanceType string            `json:"instance_type"`
-	Zone         string            `json:"zone"`
-	CPU          ResourceUsage     `json:"cpu"`
-	Memory       ResourceUsage     `json:"memory"`
-	Labels       map[string]string `json:"labels"`
-	Taints       []TaintInfo       `json:"taints"`
-	CreatedAt    time.Time         `json:"created_at"`
+	Name          string            `json:"name"`
+	Status        string            `json:"status"`
+	Role          string            `json:"role"`
+	Version       string            `json:"version"`
+	InstanceType  string            `json:"instance_type"`
+	Zone          string            `json:"zone"`
+	CPU           ResourceUsage     `json:"cpu"`
+	Memory        ResourceUsage     `json:"memory"`
+	Labels        map[string]string `json:"labels"`
+	Taints        []TaintInfo       `json:"taints"`
+	Unschedulable bool              `json:"unschedulable"`
+	CreatedAt     time.Time         `json:"created_at"`
 }
 
 type ResourceUsage struct {
@@ -32,12 +101,13 @@ type ResourceUsage struct {
 	Allocatable string `json:"allocatable"`
 	Used        string `json:"used"`
 	Percentage  int    `json:"percentage"`
+	Source      string `json:"source,omitempty"`
 }
 
 type TaintInfo struct {
-	Key    string `json:"key"`
+	Key    string `json:"key" binding:"required"`
 	Value  string `json:"value"`
-	Effect string `json:"effect"`
+	Effect string `json:"effect" binding:"required,oneof=NoSchedule PreferNoSchedule NoExecute"`
 }
 
 type NamespaceInfo struct {
@@ -49,10 +119,12 @@ type NamespaceInfo struct {
 }
 
 type ClusterOverview struct {
-	Cluster    ClusterStatus   `json:"cluster"`
-	Nodes      []NodeInfo      `json:"nodes"`
-	Namespaces []NamespaceInfo `json:"namespaces"`
-	Metrics    ClusterMetrics  `json:"metrics"`
+	Cluster    ClusterStatus     `json:"cluster"`
+	Nodes      []NodeInfo        `json:"nodes"`
+	Namespaces []NamespaceInfo   `json:"namespaces"`
+	Metrics    ClusterMetrics    `json:"metrics"`
+	Partial    bool              `json:"partial"`
+	Errors     map[string]string `json:"errors,omitempty"`
 }
 
 type ClusterMetrics struct {
@@ -67,3 +139,52 @@ type ClusterMetrics struct {
 	CPUPercentage    int    `json:"cpu_percentage"`
 	MemoryPercentage int    `json:"memory_percentage"`
 }
+
+const (
+	NodeOperationDrain = "drain"
+
+	NodeOperationRunning   = "running"
+	NodeOperationSucceeded = "succeeded"
+	NodeOperationFailed    = "failed"
+)
+
+type NodeOperation struct {
+	ID          uuid.UUID  `json:"id"`
+	Cluster     string     `json:"cluster"`
+	Node        string     `json:"node"`
+	Operation   string     `json:"operation"`
+	Status      string     `json:"status"`
+	TotalPods   int        `json:"total_pods"`
+	EvictedPods int        `json:"evicted_pods"`
+	Message     string     `json:"message,omitempty"`
+	RequestedBy string     `json:"requested_by"`
+	StartedAt   time.Time  `json:"started_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// DrainRequest tunes a drain. Force also evicts pods that no controller will
+// recreate; TimeoutSeconds bounds the wait for evictions blocked by
+// PodDisruptionBudgets.
+type DrainRequest struct {
+	Force              bool   `json:"force"`
+	GracePeriodSeconds *int64 `json:"grace_period_seconds"`
+	TimeoutSeconds     int    `json:"timeout_seconds"`
+}
+
+// NodeLabelsPatch sets labels; a null value removes the label.
+type NodeLabelsPatch struct {
+	Labels map[string]*string `json:"labels" binding:"required"`
+}
+
+type NodeTaintsPatch struct {
+	Add    []TaintInfo     `json:"add" binding:"dive"`
+	Remove []TaintSelector `json:"remove" binding:"dive"`
+}
+
+// TaintSelector matches taints to remove. An empty Effect matches every
+// taint with the key.
+type TaintSelector struct {
+	Key    string `json:"key" binding:"required"`
+	Effect string `json:"effect"`
+}
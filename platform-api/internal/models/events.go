@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TenantLifecycleEvent struct {
+	TenantID       uuid.UUID `json:"tenant_id"`
+	Name           string    `json:"name"`
+	Namespace      string    `json:"namespace"`
+	Cluster        string    `json:"cluster"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	DeleteError    string    `json:"delete_error,omitempty"`
+	DeleteRunURL   string    `json:"delete_run_url,omitempty"`
+}
+
+type NodeReadinessEvent struct {
+	Node    string `json:"node"`
+	Ready   bool   `json:"ready"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type QuotaBreachEvent struct {
+	Namespace string `json:"namespace"`
+	Quota     string `json:"quota"`
+	Resource  string `json:"resource"`
+	Used      string `json:"used"`
+	Hard      string `json:"hard"`
+}
+
+type BudgetAlertEvent struct {
+	Scope      string    `json:"scope"`
+	TenantName string    `json:"tenant_name,omitempty"`
+	Spend      float64   `json:"spend"`
+	Budget     float64   `json:"budget"`
+	Threshold  int       `json:"threshold_percent"`
+	Currency   string    `json:"currency"`
+	Period     string    `json:"period"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
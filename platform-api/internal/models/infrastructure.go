@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InfrastructureRun is a Terraform run against a tenant's workspace. RunID is
+// the executor's run ID; Status uses Terraform Cloud's run statuses.
+// PolicyStatus is passed or failed once the plan has been checked against
+// the platform policy; a failed run is discarded and lists its violations.
+// ConfigSource is "upload" or "templates" when the run brought a new
+// configuration, and empty when it ran the latest one again.
+type InfrastructureRun struct {
+	ID                     uuid.UUID         `json:"id"`
+	TenantID               uuid.UUID         `json:"tenant_id"`
+	RunID                  string            `json:"run_id"`
+	WorkspaceID            string            `json:"workspace_id"`
+	ConfigurationVersionID string            `json:"configuration_version_id,omitempty"`
+	Message                string            `json:"message"`
+	ConfigSource           string            `json:"config_source,omitempty"`
+	IsDestroy              bool              `json:"is_destroy"`
+	Status                 string            `json:"status"`
+	HasChanges             bool              `json:"has_changes"`
+	ResourceAdditions      int               `json:"resource_additions"`
+	ResourceChanges        int               `json:"resource_changes"`
+	ResourceDestructions   int               `json:"resource_destructions"`
+	Confirmable            bool              `json:"confirmable"`
+	AutoApply              bool              `json:"auto_apply"`
+	PolicyStatus           string            `json:"policy_status,omitempty"`
+	PolicyViolations       []PolicyViolation `json:"policy_violations,omitempty"`
+	URL                    string            `json:"url,omitempty"`
+	RequestedBy            string            `json:"requested_by"`
+	CreatedAt              time.Time         `json:"created_at"`
+	UpdatedAt              time.Time         `json:"updated_at"`
+	FinishedAt             *time.Time        `json:"finished_at,omitempty"`
+}
+
+// CreateInfrastructureRunRequest queues a run. Files, keyed by path, replace
+// the workspace configuration; without them the latest configuration is run
+// again.
+type CreateInfrastructureRunRequest struct {
+	Message   string            `json:"message"`
+	Files     map[string]string `json:"files"`
+	IsDestroy bool              `json:"is_destroy"`
+	AutoApply bool              `json:"auto_apply"`
+}
+
+// InfrastructureOutput is a root module output. Redacted is set when a
+// sensitive value was withheld from the caller.
+type InfrastructureOutput struct {
+	Name      string      `json:"name"`
+	Type      string      `json:"type"`
+	Sensitive bool        `json:"sensitive"`
+	Value     interface{} `json:"value"`
+	Redacted  bool        `json:"redacted,omitempty"`
+}
+
+type PolicyViolation struct {
+	Rule     string `json:"rule"`
+	Resource string `json:"resource,omitempty"`
+	Message  string `json:"message"`
+}
+
+type InfrastructureRunAction struct {
+	Comment string `json:"comment"`
+}
+
+// DriftCheck is a scheduled plan-only run that compares a tenant's
+// infrastructure with its Terraform state. Status is pending while the plan
+// runs, then drifted, in_sync or errored. DriftedSince is when the current
+// spell of drift was first detected.
+type DriftCheck struct {
+	ID           uuid.UUID         `json:"id"`
+	TenantID     uuid.UUID         `json:"tenant_id"`
+	RunID        string            `json:"run_id"`
+	URL          string            `json:"url,omitempty"`
+	Status       string            `json:"status"`
+	Resources    []DriftedResource `json:"resources"`
+	Error        string            `json:"error,omitempty"`
+	DriftedSince *time.Time        `json:"drifted_since,omitempty"`
+	StartedAt    time.Time         `json:"started_at"`
+	FinishedAt   *time.Time        `json:"finished_at,omitempty"`
+}
+
+type DriftedResource struct {
+	Address string   `json:"address"`
+	Type    string   `json:"type"`
+	Actions []string `json:"actions"`
+}
+
+// InfrastructureDrift summarises drift detection for a tenant. Latest is the
+// most recent check that completed, errored checks aside; Checks lists the
+// recent checks, newest first.
+type InfrastructureDrift struct {
+	Drifted bool         `json:"drifted"`
+	Latest  *DriftCheck  `json:"latest"`
+	Checks  []DriftCheck `json:"checks"`
+}
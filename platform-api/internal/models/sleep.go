@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// A SleepSchedule applies either to one tenant or to every tenant in an
+// environment; a tenant schedule takes precedence over its environment's.
+type SleepSchedule struct {
+	ID          uuid.UUID  `json:"id"`
+	TenantID    *uuid.UUID `json:"tenant_id,omitempty"`
+	Environment string     `json:"environment,omitempty"`
+	SleepCron   string     `json:"sleep_cron"`
+	WakeCron    string     `json:"wake_cron"`
+	Timezone    string     `json:"timezone"`
+	Enabled     bool       `json:"enabled"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type SleepScheduleRequest struct {
+	TenantID    *uuid.UUID `json:"tenant_id"`
+	Environment string     `json:"environment" binding:"omitempty,oneof=dev staging prod"`
+	SleepCron   string     `json:"sleep_cron" binding:"required"`
+	WakeCron    string     `json:"wake_cron" binding:"required"`
+	Timezone    string     `json:"timezone"`
+	Enabled     *bool      `json:"enabled"`
+}
+
+type SleepWorkload struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Replicas int32  `json:"replicas"`
+}
+
+type SleepResult struct {
+	TenantID  uuid.UUID       `json:"tenant_id"`
+	Action    string          `json:"action"`
+	Workloads []SleepWorkload `json:"workloads"`
+	At        time.Time       `json:"at"`
+}
+
+type SleepSavings struct {
+	HourlyCost        float64 `json:"hourly_cost"`
+	MonthlySavings    float64 `json:"estimated_monthly_savings"`
+	SleepHoursPerWeek float64 `json:"sleep_hours_per_week"`
+	Currency          string  `json:"currency"`
+	Basis             string  `json:"basis"`
+}
+
+type SleepStatus struct {
+	TenantID      uuid.UUID      `json:"tenant_id"`
+	Environment   string         `json:"environment"`
+	Sleeping      bool           `json:"sleeping"`
+	SleepingSince *time.Time     `json:"sleeping_since,omitempty"`
+	Schedule      *SleepSchedule `json:"schedule,omitempty"`
+	NextSleep     *time.Time     `json:"next_sleep,omitempty"`
+	NextWake      *time.Time     `json:"next_wake,omitempty"`
+	Savings       *SleepSavings  `json:"savings,omitempty"`
+	SavingsError  string         `json:"savings_error,omitempty"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Template is a versioned Terraform module tenants can provision from the
+// service catalog. InputSchema is the JSON Schema of the module inputs, which
+// the Backstage scaffolder renders as a form. AllowedTiers lists the tenant
+// environments that may use the template; empty allows all of them. Outputs
+// names the module outputs exposed as <resource>_<output>.
+type Template struct {
+	ID            uuid.UUID         `json:"id"`
+	Name          string            `json:"name"`
+	Version       string            `json:"version"`
+	Description   string            `json:"description"`
+	ModuleSource  string            `json:"module_source"`
+	ModuleVersion string            `json:"module_version,omitempty"`
+	InputSchema   json.RawMessage   `json:"input_schema"`
+	DefaultTags   map[string]string `json:"default_tags"`
+	AllowedTiers  []string          `json:"allowed_tiers"`
+	Outputs       []string          `json:"outputs"`
+	CreatedBy     string            `json:"created_by"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+type CreateTemplateRequest struct {
+	Name          string            `json:"name" binding:"required"`
+	Version       string            `json:"version" binding:"required"`
+	Description   string            `json:"description"`
+	ModuleSource  string            `json:"module_source" binding:"required"`
+	ModuleVersion string            `json:"module_version"`
+	InputSchema   json.RawMessage   `json:"input_schema" binding:"required"`
+	DefaultTags   map[string]string `json:"default_tags"`
+	AllowedTiers  []string          `json:"allowed_tiers" binding:"dive,oneof=dev staging prod"`
+	Outputs       []string          `json:"outputs"`
+}
+
+// TenantResource is an instance of a template in a tenant's infrastructure.
+// RunID is the infrastructure run that last provisioned it.
+type TenantResource struct {
+	ID              uuid.UUID       `json:"id"`
+	TenantID        uuid.UUID       `json:"tenant_id"`
+	Name            string          `json:"name"`
+	TemplateID      uuid.UUID       `json:"template_id"`
+	TemplateName    string          `json:"template_name"`
+	TemplateVersion string          `json:"template_version"`
+	Inputs          json.RawMessage `json:"inputs"`
+	RunID           string          `json:"run_id,omitempty"`
+	CreatedBy       string          `json:"created_by"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+// CreateTenantResourceRequest provisions a template. An empty Version uses
+// the latest version of the template.
+type CreateTenantResourceRequest struct {
+	Name      string          `json:"name" binding:"required"`
+	Template  string          `json:"template" binding:"required"`
+	Version   string          `json:"version"`
+	Inputs    json.RawMessage `json:"inputs"`
+	AutoApply bool            `json:"auto_apply"`
+}
@@ -6,25 +6,35 @@ import (
 )
 
 type Tenant struct {
-	ID          uuid.UUID `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name"`
-	Namespace   string    `json:"namespace" db:"namespace"`
-	Description string    `json:"description" db:"description"`
-	Owner       string    `json:"owner" db:"owner"`
-	Email       string    `json:"email" db:"email"`
-	Status      string    `json:"status" db:"status"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID            uuid.UUID  `json:"id" db:"id"`
+	Name          string     `json:"name" db:"name"`
+	Namespace     string     `json:"namespace" db:"namespace"`
+	ClusterName   string     `json:"cluster" db:"cluster_name"`
+	Environment   string     `json:"environment" db:"environment"`
+	Description   string     `json:"description" db:"description"`
+	Owner         string     `json:"owner" db:"owner"`
+	Email         string     `json:"email" db:"email"`
+	Status        string     `json:"status" db:"status"`
+	SleepingSince *time.Time `json:"sleeping_since,omitempty" db:"sleeping_since"`
+	DeleteError   string     `json:"delete_error,omitempty" db:"delete_error"`
+	DeleteRunURL  string     `json:"delete_run_url,omitempty" db:"delete_run_url"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
 }
 
+// TenantResources summarises a tenant's namespace. The usage percentages are
+// of the cluster's allocatable CPU and memory.
 type TenantResources struct {
-	TenantID    uuid.UUID `json:"tenant_id"`
-	Namespace   string    `json:"namespace"`
-	Pods        int       `json:"pods"`
-	Services    int       `json:"services"`
-	Deployments int       `json:"deployments"`
-	CPUUsage    string    `json:"cpu_usage"`
-	MemoryUsage string    `json:"memory_usage"`
+	TenantID         uuid.UUID `json:"tenant_id"`
+	Namespace        string    `json:"namespace"`
+	Pods             int       `json:"pods"`
+	Services         int       `json:"services"`
+	Deployments      int       `json:"deployments"`
+	CPUUsage         string    `json:"cpu_usage"`
+	MemoryUsage      string    `json:"memory_usage"`
+	CPUPercentage    int       `json:"cpu_percentage"`
+	MemoryPercentage int       `json:"memory_percentage"`
+	UsageSource      string    `json:"usage_source"`
 }
 
 type CreateTenantRequest struct {
@@ -32,17 +42,32 @@ type CreateTenantRequest struct {
 	Description string `json:"description"`
 	Owner       string `json:"owner" binding:"required"`
 	Email       string `json:"email" binding:"required,email"`
+	Cluster     string `json:"cluster"`
+	Environment string `json:"environment" binding:"omitempty,oneof=dev staging prod"`
 }
 
 type TenantResponse struct {
-	ID          uuid.UUID        `json:"id"`
-	Name        string           `json:"name"`
-	Namespace   string           `json:"namespace"`
-	Description string           `json:"description"`
-	Owner       string           `json:"owner"`
-	Email       string           `json:"email"`
-	Status      string           `json:"status"`
-	Resources   *TenantResources `json:"resources,omitempty"`
-	CreatedAt   time.Time        `json:"created_at"`
-	UpdatedAt   time.Time        `json:"updated_at"`
+	ID            uuid.UUID        `json:"id"`
+	Name          string           `json:"name"`
+	Namespace     string           `json:"namespace"`
+	Cluster       string           `json:"cluster"`
+	Environment   string           `json:"environment"`
+	Description   string           `json:"description"`
+	Owner         string           `json:"owner"`
+	Email         string           `json:"email"`
+	Status        string           `json:"status"`
+	Health        string           `json:"health,omitempty"`
+	SleepingSince *time.Time       `json:"sleeping_since,omitempty"`
+	DeleteError   string           `json:"delete_error,omitempty"`
+	DeleteRunURL  string           `json:"delete_run_url,omitempty"`
+	Resources     *TenantResources `json:"resources,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+type TenantMember struct {
+	TenantID  uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	UserID    string    `json:"user_id" db:"user_id" binding:"required"`
+	Role      string    `json:"role" db:"role"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
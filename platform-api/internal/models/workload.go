@@ -0,0 +1,130 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	HealthHealthy   = "healthy"
+	HealthDegraded  = "degraded"
+	HealthUnhealthy = "unhealthy"
+	HealthEmpty     = "empty"
+	HealthUnknown   = "unknown"
+)
+
+type TenantWorkloads struct {
+	TenantID     uuid.UUID         `json:"tenant_id"`
+	Namespace    string            `json:"namespace"`
+	Cluster      string            `json:"cluster"`
+	Health       string            `json:"health"`
+	Deployments  []DeploymentInfo  `json:"deployments"`
+	StatefulSets []StatefulSetInfo `json:"statefulsets"`
+	Services     []ServiceInfo     `json:"services"`
+	Ingresses    []IngressInfo     `json:"ingresses"`
+	Pods         []PodInfo         `json:"pods"`
+}
+
+type DeploymentInfo struct {
+	Name              string    `json:"name"`
+	Replicas          int32     `json:"replicas"`
+	ReadyReplicas     int32     `json:"ready_replicas"`
+	UpdatedReplicas   int32     `json:"updated_replicas"`
+	AvailableReplicas int32     `json:"available_replicas"`
+	Images            []string  `json:"images"`
+	Health            string    `json:"health"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type StatefulSetInfo struct {
+	Name            string    `json:"name"`
+	Replicas        int32     `json:"replicas"`
+	ReadyReplicas   int32     `json:"ready_replicas"`
+	UpdatedReplicas int32     `json:"updated_replicas"`
+	Images          []string  `json:"images"`
+	Health          string    `json:"health"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type ServiceInfo struct {
+	Name         string            `json:"name"`
+	Type         string            `json:"type"`
+	ClusterIP    string            `json:"cluster_ip"`
+	Ports        []ServicePortInfo `json:"ports"`
+	ExternalHost []string          `json:"external_hosts,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+type ServicePortInfo struct {
+	Name       string `json:"name,omitempty"`
+	Protocol   string `json:"protocol"`
+	Port       int32  `json:"port"`
+	TargetPort string `json:"target_port"`
+	NodePort   int32  `json:"node_port,omitempty"`
+}
+
+type IngressInfo struct {
+	Name      string    `json:"name"`
+	ClassName string    `json:"class_name,omitempty"`
+	Hosts     []string  `json:"hosts"`
+	Addresses []string  `json:"addresses"`
+	TLS       bool      `json:"tls"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PodInfo struct {
+	Name       string                `json:"name"`
+	Phase      string                `json:"phase"`
+	Node       string                `json:"node"`
+	Ready      bool                  `json:"ready"`
+	Restarts   int32                 `json:"restarts"`
+	Health     string                `json:"health"`
+	Containers []ContainerStatusInfo `json:"containers"`
+	CreatedAt  time.Time             `json:"created_at"`
+}
+
+type ContainerStatusInfo struct {
+	Name         string `json:"name"`
+	Image        string `json:"image"`
+	ImageTag     string `json:"image_tag"`
+	Ready        bool   `json:"ready"`
+	RestartCount int32  `json:"restart_count"`
+	State        string `json:"state"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+type NamespaceEvent struct {
+	Type           string          `json:"type"`
+	Reason         string          `json:"reason"`
+	Message        string          `json:"message"`
+	InvolvedObject ObjectReference `json:"involved_object"`
+	Count          int32           `json:"count"`
+	Source         string          `json:"source,omitempty"`
+	FirstSeen      time.Time       `json:"first_seen"`
+	LastSeen       time.Time       `json:"last_seen"`
+}
+
+type ObjectReference struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+type EventQuery struct {
+	Type   string `form:"type"`
+	Reason string `form:"reason"`
+	Kind   string `form:"kind"`
+	Name   string `form:"name"`
+	Since  string `form:"since"`
+	Limit  int    `form:"limit"`
+	Offset int    `form:"offset"`
+}
+
+type PodLogQuery struct {
+	Container  string `form:"container"`
+	Follow     bool   `form:"follow"`
+	Tail       *int64 `form:"tail"`
+	Since      string `form:"since"`
+	Previous   bool   `form:"previous"`
+	Timestamps bool   `form:"timestamps"`
+}
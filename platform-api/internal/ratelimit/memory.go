@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+const memorySweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+// MemoryStore keeps buckets in process memory. Each replica enforces its own
+// limits, so use PostgresStore when running more than one.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryStore) Allow(key string, limit Limit) (*Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) > memorySweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+
+	tokens, result := take(b.tokens, b.last, now, limit)
+	b.tokens = tokens
+	b.last = now
+	b.limit = limit
+
+	return result, nil
+}
+
+// sweep drops buckets that have refilled completely, since they are
+// indistinguishable from a new bucket.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		refill := secondsToDuration((float64(b.limit.Burst) - b.tokens) / b.limit.Rate)
+		if now.Sub(b.last) > refill {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PostgresStore shares buckets between replicas through the rate_limit_buckets
+// table. Rows are locked for the duration of a single take.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{
+		db: db,
+	}
+}
+
+func (s *PostgresStore) Allow(key string, limit Limit) (*Result, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin rate limit transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (key) DO NOTHING
+	`, key, limit.Burst)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise rate limit bucket: %v", err)
+	}
+
+	var tokens float64
+	var last, now time.Time
+	err = tx.QueryRow(`
+		SELECT tokens, updated_at, NOW() FROM rate_limit_buckets WHERE key = $1 FOR UPDATE
+	`, key).Scan(&tokens, &last, &now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate limit bucket: %v", err)
+	}
+
+	tokens, result := take(tokens, last, now, limit)
+
+	_, err = tx.Exec(`UPDATE rate_limit_buckets SET tokens = $1, updated_at = $2 WHERE key = $3`, tokens, now, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update rate limit bucket: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit rate limit bucket: %v", err)
+	}
+
+	return result, nil
+}
+
+// Cleanup removes buckets that have not been touched for longer than maxIdle.
+func (s *PostgresStore) Cleanup(maxIdle time.Duration) error {
+	_, err := s.db.Exec(`DELETE FROM rate_limit_buckets WHERE updated_at < $1`, time.Now().Add(-maxIdle))
+	if err != nil {
+		return fmt.Errorf("failed to clean up rate limit buckets: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// Limit describes a token bucket that refills at Rate tokens per second and
+// holds at most Burst tokens.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func PerMinute(n int) Limit {
+	return Limit{Rate: float64(n) / 60, Burst: n}
+}
+
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
+
+type Store interface {
+	Allow(key string, limit Limit) (*Result, error)
+}
+
+// take refills a bucket holding tokens at time last up to now and tries to
+// remove one token from it. It returns the new token count and the outcome.
+func take(tokens float64, last, now time.Time, limit Limit) (float64, *Result) {
+	elapsed := now.Sub(last).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	tokens = math.Min(float64(limit.Burst), tokens+elapsed*limit.Rate)
+
+	result := &Result{Limit: limit.Burst}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - tokens) / limit.Rate)
+	}
+
+	result.Remaining = int(math.Floor(tokens))
+	result.ResetAfter = secondsToDuration((float64(limit.Burst) - tokens) / limit.Rate)
+
+	return tokens, result
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
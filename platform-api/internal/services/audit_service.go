@@ -0,0 +1,238 @@
+package services
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"devplatform/platform-api/internal/models"
+)
+
+// auditLockID serialises writers across replicas so that every row is chained
+// to exactly one predecessor.
+const auditLockID = 7210526
+
+var genesisHash = strings.Repeat("0", 64)
+
+type AuditService struct {
+	db *sql.DB
+}
+
+func NewAuditService(db *sql.DB) *AuditService {
+	return &AuditService{
+		db: db,
+	}
+}
+
+func (s *AuditService) Record(entry *models.AuditEntry) error {
+	entry.Timestamp = time.Now().UTC().Truncate(time.Microsecond)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin audit transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, auditLockID); err != nil {
+		return fmt.Errorf("failed to lock audit log: %v", err)
+	}
+
+	err = tx.QueryRow(`SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1`).Scan(&entry.PrevHash)
+	if err == sql.ErrNoRows {
+		entry.PrevHash = genesisHash
+	} else if err != nil {
+		return fmt.Errorf("failed to read previous audit hash: %v", err)
+	}
+
+	entry.Hash = computeAuditHash(entry)
+
+	query := `
+		INSERT INTO audit_log (timestamp, actor, actor_id, action, resource_type, resource_id,
+			request_id, source_ip, before_state, after_state, outcome, status_code, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id
+	`
+
+	err = tx.QueryRow(query, entry.Timestamp, entry.Actor, entry.ActorID, entry.Action,
+		entry.ResourceType, entry.ResourceID, entry.RequestID, entry.SourceIP,
+		nullableJSON(entry.Before), nullableJSON(entry.After), entry.Outcome,
+		entry.StatusCode, entry.PrevHash, entry.Hash).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit entry: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit entry: %v", err)
+	}
+
+	return nil
+}
+
+func (s *AuditService) List(q *models.AuditQuery) ([]models.AuditEntry, error) {
+	var conditions []string
+	var args []interface{}
+
+	addCondition := func(column, op string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("%s %s $%d", column, op, len(args)))
+	}
+
+	if q.Actor != "" {
+		addCondition("actor", "=", q.Actor)
+	}
+	if q.Action != "" {
+		addCondition("action", "=", q.Action)
+	}
+	if q.ResourceType != "" {
+		addCondition("resource_type", "=", q.ResourceType)
+	}
+	if q.ResourceID != "" {
+		addCondition("resource_id", "=", q.ResourceID)
+	}
+	if q.RequestID != "" {
+		addCondition("request_id", "=", q.RequestID)
+	}
+	if q.Outcome != "" {
+		addCondition("outcome", "=", q.Outcome)
+	}
+	if q.Since != "" {
+		since, err := time.Parse(time.RFC3339, q.Since)
+		if err != nil {
+			return nil, fmt.Errorf("invalid since: %v", err)
+		}
+		addCondition("timestamp", ">=", since)
+	}
+	if q.Until != "" {
+		until, err := time.Parse(time.RFC3339, q.Until)
+		if err != nil {
+			return nil, fmt.Errorf("invalid until: %v", err)
+		}
+		addCondition("timestamp", "<", until)
+	}
+
+	query := `
+		SELECT id, timestamp, actor, actor_id, action, resource_type, resource_id, request_id,
+			source_ip, before_state, after_state, outcome, status_code, prev_hash, hash
+		FROM audit_log
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, q.Limit, q.Offset)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %v", err)
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// Verify walks the whole chain in insertion order and reports the first row
+// whose stored hash or back-link does not match.
+func (s *AuditService) Verify() (*models.AuditVerification, error) {
+	rows, err := s.db.Query(`
+		SELECT id, timestamp, actor, actor_id, action, resource_type, resource_id, request_id,
+			source_ip, before_state, after_state, outcome, status_code, prev_hash, hash
+		FROM audit_log ORDER BY id ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %v", err)
+	}
+	defer rows.Close()
+
+	result := &models.AuditVerification{Valid: true, CheckedAt: time.Now()}
+	prevHash := genesisHash
+
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		result.Entries++
+
+		if entry.PrevHash != prevHash {
+			result.Valid = false
+			result.BrokenAt = entry.ID
+			result.Reason = "previous hash does not match preceding entry"
+			return result, nil
+		}
+		if computeAuditHash(entry) != entry.Hash {
+			result.Valid = false
+			result.BrokenAt = entry.ID
+			result.Reason = "entry hash does not match its contents"
+			return result, nil
+		}
+		prevHash = entry.Hash
+	}
+
+	return result, rows.Err()
+}
+
+func scanAuditEntry(rows *sql.Rows) (*models.AuditEntry, error) {
+	var entry models.AuditEntry
+	var before, after []byte
+
+	err := rows.Scan(&entry.ID, &entry.Timestamp, &entry.Actor, &entry.ActorID, &entry.Action,
+		&entry.ResourceType, &entry.ResourceID, &entry.RequestID, &entry.SourceIP,
+		&before, &after, &entry.Outcome, &entry.StatusCode, &entry.PrevHash, &entry.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan audit entry: %v", err)
+	}
+
+	entry.Timestamp = entry.Timestamp.UTC()
+	entry.Before = before
+	entry.After = after
+
+	return &entry, nil
+}
+
+func computeAuditHash(entry *models.AuditEntry) string {
+	fields := []string{
+		entry.PrevHash,
+		entry.Timestamp.UTC().Format(time.RFC3339Nano),
+		entry.Actor,
+		entry.ActorID,
+		entry.Action,
+		entry.ResourceType,
+		entry.ResourceID,
+		entry.RequestID,
+		entry.SourceIP,
+		string(entry.Before),
+		string(entry.After),
+		entry.Outcome,
+		strconv.Itoa(entry.StatusCode),
+	}
+
+	h := sha256.New()
+	for _, field := range fields {
+		// Length-prefix each field so that shifting bytes between adjacent
+		// fields changes the digest.
+		fmt.Fprintf(h, "%d:%s;", len(field), field)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func nullableJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"devplatform/platform-api/internal/events"
+	"devplatform/platform-api/internal/models"
+)
+
+// budgetThresholds are the percentages of a monthly budget that raise an
+// alert. Each is reported at most once per scope per month.
+var budgetThresholds = []int{80, 100}
+
+// BudgetMonitor periodically compares month-to-date spend with the configured
+// budgets and publishes budget.alert events when a threshold is crossed.
+type BudgetMonitor struct {
+	db            *sql.DB
+	costs         *CostService
+	events        *events.Bus
+	platformLimit float64
+	tenantLimit   float64
+
+	mu       sync.Mutex
+	month    string
+	notified map[string]int
+}
+
+func NewBudgetMonitor(db *sql.DB, costs *CostService, bus *events.Bus, platformLimit, tenantLimit int) *BudgetMonitor {
+	return &BudgetMonitor{
+		db:            db,
+		costs:         costs,
+		events:        bus,
+		platformLimit: float64(platformLimit),
+		tenantLimit:   float64(tenantLimit),
+		notified:      make(map[string]int),
+	}
+}
+
+// Run checks budgets every interval until stop is closed.
+func (m *BudgetMonitor) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m.Check()
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (m *BudgetMonitor) Check() {
+	now := time.Now().UTC()
+	req := &models.CostRequest{
+		StartDate:   now.Format("2006-01") + "-01",
+		EndDate:     now.AddDate(0, 0, 1).Format("2006-01-02"),
+		Granularity: "MONTHLY",
+	}
+
+	m.mu.Lock()
+	if month := now.Format("2006-01"); month != m.month {
+		m.month = month
+		m.notified = make(map[string]int)
+	}
+	m.mu.Unlock()
+
+	if m.platformLimit > 0 {
+		overview, err := m.costs.GetPlatformCostOverview(req)
+		if err != nil {
+			log.Printf("Warning: budget check failed for platform: %v", err)
+		} else {
+			m.evaluate("platform", "", "", overview.TotalCost, m.platformLimit, overview.Currency, overview.Period)
+		}
+	}
+
+	if m.tenantLimit <= 0 {
+		return
+	}
+
+	rows, err := m.db.Query(`SELECT id, name FROM tenants`)
+	if err != nil {
+		log.Printf("Warning: budget check failed to list tenants: %v", err)
+		return
+	}
+	var tenants []models.Tenant
+	for rows.Next() {
+		var t models.Tenant
+		if err := rows.Scan(&t.ID, &t.Name); err == nil {
+			tenants = append(tenants, t)
+		}
+	}
+	rows.Close()
+
+	for _, t := range tenants {
+		summary, err := m.costs.GetTenantCosts(t.ID, req)
+		if err != nil {
+			log.Printf("Warning: budget check failed for tenant %s: %v", t.Name, err)
+			continue
+		}
+		m.evaluate("tenant", t.ID.String(), t.Name, summary.TotalCost, m.tenantLimit, summary.Currency, summary.Period)
+	}
+}
+
+// evaluate publishes an alert for the highest threshold crossed that has not
+// been reported yet this month. Platform alerts are only shown to admins.
+func (m *BudgetMonitor) evaluate(scope, tenantID, tenantName string, spend, budget float64, currency, period string) {
+	crossed := 0
+	for _, threshold := range budgetThresholds {
+		if spend >= budget*float64(threshold)/100 {
+			crossed = threshold
+		}
+	}
+	if crossed == 0 {
+		return
+	}
+
+	key := scope + ":" + tenantID
+	m.mu.Lock()
+	if m.notified[key] >= crossed {
+		m.mu.Unlock()
+		return
+	}
+	m.notified[key] = crossed
+	month := m.month
+	m.mu.Unlock()
+
+	eventType := "threshold_reached"
+	if crossed >= 100 {
+		eventType = "exceeded"
+	}
+
+	m.events.Publish(events.Event{
+		Topic:     events.TopicBudgetAlert,
+		Type:      eventType,
+		TenantID:  tenantID,
+		AdminOnly: tenantID == "",
+		Key:       fmt.Sprintf("budget/%s/%s/%s/%d", scope, tenantID, month, crossed),
+		Data: models.BudgetAlertEvent{
+			Scope:      scope,
+			TenantName: tenantName,
+			Spend:      spend,
+			Budget:     budget,
+			Threshold:  crossed,
+			Currency:   currency,
+			Period:     period,
+			CheckedAt:  time.Now(),
+		},
+	})
+}
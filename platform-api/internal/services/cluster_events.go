@@ -0,0 +1,158 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"devplatform/platform-api/internal/events"
+	"devplatform/platform-api/internal/models"
+	"devplatform/platform-api/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+// watchCluster publishes node readiness changes and resource quota breaches
+// from the cluster's informers. Objects seen in the initial list are not
+// reported, so a restart does not replay the current state as changes.
+func (r *ClusterRegistry) watchCluster(clusterName string, cache *k8s.Cache) {
+	if r.events == nil {
+		return
+	}
+
+	err := cache.OnNodeChange(toolscache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			if node, ok := obj.(*corev1.Node); ok && !isInInitialList {
+				r.publishNode(clusterName, "added", node)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldNode, ok1 := oldObj.(*corev1.Node)
+			newNode, ok2 := newObj.(*corev1.Node)
+			if !ok1 || !ok2 {
+				return
+			}
+			oldReady, _ := nodeReadyCondition(oldNode)
+			newReady, _ := nodeReadyCondition(newNode)
+			if oldReady == newReady {
+				return
+			}
+			eventType := "not_ready"
+			if newReady {
+				eventType = "ready"
+			}
+			r.publishNode(clusterName, eventType, newNode)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if node, ok := obj.(*corev1.Node); ok {
+				r.publishNode(clusterName, "removed", node)
+			}
+		},
+	})
+	if err != nil {
+		log.Printf("Warning: failed to watch nodes on cluster %s: %v", clusterName, err)
+	}
+
+	err = cache.OnResourceQuotaChange(toolscache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			if quota, ok := obj.(*corev1.ResourceQuota); ok && !isInInitialList {
+				r.publishQuotaBreaches(clusterName, nil, quota)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldQuota, ok1 := oldObj.(*corev1.ResourceQuota)
+			newQuota, ok2 := newObj.(*corev1.ResourceQuota)
+			if ok1 && ok2 {
+				r.publishQuotaBreaches(clusterName, oldQuota, newQuota)
+			}
+		},
+	})
+	if err != nil {
+		log.Printf("Warning: failed to watch resource quotas on cluster %s: %v", clusterName, err)
+	}
+}
+
+func (r *ClusterRegistry) publishNode(clusterName, eventType string, node *corev1.Node) {
+	ready, condition := nodeReadyCondition(node)
+	data := models.NodeReadinessEvent{Node: node.Name, Ready: ready}
+	if condition != nil {
+		data.Reason = condition.Reason
+		data.Message = condition.Message
+	}
+
+	r.events.Publish(events.Event{
+		Topic:   events.TopicNodeReadiness,
+		Type:    eventType,
+		Cluster: clusterName,
+		Key:     fmt.Sprintf("node/%s/%s/%s/%s", clusterName, node.Name, eventType, node.ResourceVersion),
+		Data:    data,
+	})
+}
+
+// publishQuotaBreaches reports each resource that has reached its hard limit
+// in quota and had not in old.
+func (r *ClusterRegistry) publishQuotaBreaches(clusterName string, old, quota *corev1.ResourceQuota) {
+	var tenantID string
+	looked := false
+
+	for name, hard := range quota.Status.Hard {
+		if !quotaExhausted(quota, name) || (old != nil && quotaExhausted(old, name)) {
+			continue
+		}
+
+		if !looked {
+			tenantID = r.tenantForNamespace(clusterName, quota.Namespace)
+			looked = true
+		}
+
+		used := quota.Status.Used[name]
+		r.events.Publish(events.Event{
+			Topic:     events.TopicQuotaBreach,
+			Type:      "exhausted",
+			TenantID:  tenantID,
+			Cluster:   clusterName,
+			AdminOnly: tenantID == "",
+			Key:       fmt.Sprintf("quota/%s/%s/%s/%s/%s", clusterName, quota.Namespace, quota.Name, name, quota.ResourceVersion),
+			Data: models.QuotaBreachEvent{
+				Namespace: quota.Namespace,
+				Quota:     quota.Name,
+				Resource:  string(name),
+				Used:      used.String(),
+				Hard:      hard.String(),
+			},
+		})
+	}
+}
+
+// tenantForNamespace returns the ID of the tenant that owns namespace on the
+// cluster, or "" for namespaces the platform does not manage. Tenants created
+// before clusters were registered have an empty cluster name.
+func (r *ClusterRegistry) tenantForNamespace(clusterName, namespace string) string {
+	var id string
+	query := `SELECT id FROM tenants WHERE namespace = $1 AND (cluster_name = $2 OR cluster_name = '') LIMIT 1`
+	if err := r.db.QueryRow(query, namespace, clusterName).Scan(&id); err != nil {
+		return ""
+	}
+	return id
+}
+
+func nodeReadyCondition(node *corev1.Node) (bool, *corev1.NodeCondition) {
+	for i := range node.Status.Conditions {
+		condition := &node.Status.Conditions[i]
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue, condition
+		}
+	}
+	return false, nil
+}
+
+func quotaExhausted(quota *corev1.ResourceQuota, name corev1.ResourceName) bool {
+	hard, ok := quota.Status.Hard[name]
+	if !ok {
+		return false
+	}
+	used, ok := quota.Status.Used[name]
+	return ok && used.Cmp(hard) >= 0
+}
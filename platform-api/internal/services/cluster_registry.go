@@ -0,0 +1,358 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"devplatform/platform-api/internal/events"
+	"devplatform/platform-api/internal/models"
+	platformaws "devplatform/platform-api/pkg/aws"
+	"devplatform/platform-api/pkg/k8s"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/lib/pq"
+)
+
+// cacheResyncPeriod is how often informers replay their cache to handlers.
+// Reads do not depend on it; the watch keeps listers current.
+const cacheResyncPeriod = 10 * time.Minute
+
+const eksDescribeTimeout = 30 * time.Second
+
+var (
+	ErrClusterNotFound = errors.New("cluster not found")
+	ErrClusterExists   = errors.New("cluster already exists")
+	ErrClusterInUse    = errors.New("cluster still has tenants")
+)
+
+// ClusterRegistry resolves cluster names to Kubernetes clients. Registrations
+// live in the clusters table; clients are created on first use and reused,
+// each with its own informer cache.
+type ClusterRegistry struct {
+	db        *sql.DB
+	awsConfig aws.Config
+	newEKS    func(aws.Config) platformaws.EKSAPI
+	events    *events.Bus
+
+	mu      sync.Mutex
+	clients map[string]*k8s.Client
+}
+
+func NewClusterRegistry(db *sql.DB, awsConfig aws.Config, bus *events.Bus) *ClusterRegistry {
+	return &ClusterRegistry{
+		db:        db,
+		awsConfig: awsConfig,
+		newEKS:    func(cfg aws.Config) platformaws.EKSAPI { return platformaws.NewEKSClient(cfg) },
+		events:    bus,
+		clients:   make(map[string]*k8s.Client),
+	}
+}
+
+// Seed upserts clusters from static configuration. Configuration wins over
+// earlier values, so editing the config file and restarting updates an entry.
+func (r *ClusterRegistry) Seed(clusters []models.ClusterRegistration) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin cluster seed: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, cluster := range clusters {
+		if cluster.Default {
+			if _, err := tx.Exec(`UPDATE clusters SET is_default = FALSE WHERE is_default AND name <> $1`, cluster.Name); err != nil {
+				return fmt.Errorf("failed to clear default cluster: %v", err)
+			}
+		}
+
+		query := `
+			INSERT INTO clusters (name, source, kubeconfig, context, eks_cluster_name, region, is_default, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+			ON CONFLICT (name) DO UPDATE SET
+				source = EXCLUDED.source,
+				kubeconfig = EXCLUDED.kubeconfig,
+				context = EXCLUDED.context,
+				eks_cluster_name = EXCLUDED.eks_cluster_name,
+				region = EXCLUDED.region,
+				is_default = EXCLUDED.is_default,
+				updated_at = NOW()
+		`
+		_, err := tx.Exec(query, cluster.Name, cluster.Source, cluster.KubeConfig, cluster.Context,
+			cluster.EKSClusterName, cluster.Region, cluster.Default)
+		if err != nil {
+			return fmt.Errorf("failed to seed cluster %s: %v", cluster.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit cluster seed: %v", err)
+	}
+
+	r.mu.Lock()
+	for name, client := range r.clients {
+		if name != "" && client.Cache != nil {
+			client.Cache.Stop()
+		}
+	}
+	r.clients = make(map[string]*k8s.Client)
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *ClusterRegistry) Register(cluster *models.ClusterRegistration) error {
+	cluster.CreatedAt = time.Now()
+	cluster.UpdatedAt = cluster.CreatedAt
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin cluster registration: %v", err)
+	}
+	defer tx.Rollback()
+
+	if cluster.Default {
+		if _, err := tx.Exec(`UPDATE clusters SET is_default = FALSE WHERE is_default`); err != nil {
+			return fmt.Errorf("failed to clear default cluster: %v", err)
+		}
+	}
+
+	query := `
+		INSERT INTO clusters (name, source, kubeconfig, context, eks_cluster_name, region, is_default, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err = tx.Exec(query, cluster.Name, cluster.Source, cluster.KubeConfig, cluster.Context,
+		cluster.EKSClusterName, cluster.Region, cluster.Default, cluster.CreatedAt, cluster.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return ErrClusterExists
+		}
+		return fmt.Errorf("failed to register cluster: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit cluster registration: %v", err)
+	}
+
+	if cluster.Default {
+		r.mu.Lock()
+		delete(r.clients, "")
+		r.mu.Unlock()
+	}
+
+	return nil
+}
+
+func (r *ClusterRegistry) Remove(name string) error {
+	var tenants int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM tenants WHERE cluster_name = $1`, name).Scan(&tenants); err != nil {
+		return fmt.Errorf("failed to count tenants on cluster: %v", err)
+	}
+	if tenants > 0 {
+		return ErrClusterInUse
+	}
+
+	result, err := r.db.Exec(`DELETE FROM clusters WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("failed to remove cluster: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrClusterNotFound
+	}
+
+	r.mu.Lock()
+	if client, ok := r.clients[name]; ok {
+		if client.Cache != nil {
+			client.Cache.Stop()
+		}
+		if r.clients[""] == client {
+			delete(r.clients, "")
+		}
+	}
+	delete(r.clients, name)
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *ClusterRegistry) List() ([]models.ClusterRegistration, error) {
+	rows, err := r.db.Query(`
+		SELECT name, source, kubeconfig, context, eks_cluster_name, region, is_default, created_at, updated_at
+		FROM clusters ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clusters: %v", err)
+	}
+	defer rows.Close()
+
+	clusters := []models.ClusterRegistration{}
+	for rows.Next() {
+		var c models.ClusterRegistration
+		err := rows.Scan(&c.Name, &c.Source, &c.KubeConfig, &c.Context, &c.EKSClusterName,
+			&c.Region, &c.Default, &c.CreatedAt, &c.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan cluster: %v", err)
+		}
+		clusters = append(clusters, c)
+	}
+
+	return clusters, rows.Err()
+}
+
+// Get returns the registration for name. An empty name selects the default
+// cluster.
+func (r *ClusterRegistry) Get(name string) (*models.ClusterRegistration, error) {
+	query := `
+		SELECT name, source, kubeconfig, context, eks_cluster_name, region, is_default, created_at, updated_at
+		FROM clusters WHERE name = $1
+	`
+	arg := interface{}(name)
+	if name == "" {
+		query = `
+			SELECT name, source, kubeconfig, context, eks_cluster_name, region, is_default, created_at, updated_at
+			FROM clusters WHERE is_default = $1
+		`
+		arg = true
+	}
+
+	var c models.ClusterRegistration
+	err := r.db.QueryRow(query, arg).Scan(&c.Name, &c.Source, &c.KubeConfig, &c.Context,
+		&c.EKSClusterName, &c.Region, &c.Default, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrClusterNotFound
+		}
+		return nil, fmt.Errorf("failed to get cluster: %v", err)
+	}
+
+	return &c, nil
+}
+
+// Client returns a Kubernetes client for the named cluster, creating it on
+// first use. An empty name selects the default cluster. The lookup and the
+// connection happen without holding the lock, so a slow or unreachable
+// cluster does not block callers of other clusters; when two callers race
+// to create the same client, the first one stored wins.
+func (r *ClusterRegistry) Client(name string) (*k8s.Client, error) {
+	r.mu.Lock()
+	client, ok := r.clients[name]
+	r.mu.Unlock()
+	if ok {
+		return client, nil
+	}
+
+	cluster, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// The default alias may have been dropped while the cluster's own
+	// client, and its informers, are still cached.
+	r.mu.Lock()
+	client, ok = r.clients[cluster.Name]
+	r.mu.Unlock()
+	if !ok {
+		client, err = r.buildClient(cluster)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to cluster %s: %v", cluster.Name, err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.clients[cluster.Name]; ok {
+		client = existing
+	} else {
+		client.StartCache(cacheResyncPeriod)
+		r.watchCluster(cluster.Name, client.Cache)
+		r.clients[cluster.Name] = client
+	}
+	if name == "" {
+		r.clients[""] = client
+	}
+
+	return client, nil
+}
+
+func (r *ClusterRegistry) buildClient(cluster *models.ClusterRegistration) (*k8s.Client, error) {
+	switch cluster.Source {
+	case models.ClusterSourceKubeconfig:
+		return k8s.NewClientForContext(cluster.KubeConfig, cluster.Context)
+	case models.ClusterSourceInCluster:
+		// NewClient falls back to ~/.kube/config outside a pod, which keeps
+		// local development working with the default registration.
+		return k8s.NewClient("")
+	case models.ClusterSourceEKS:
+		return r.buildEKSClient(cluster)
+	}
+	return nil, fmt.Errorf("unknown cluster source %q", cluster.Source)
+}
+
+// EKS returns an EKS client for the cluster's region and the EKS cluster name
+// to query. ok is false for clusters that are not backed by EKS.
+func (r *ClusterRegistry) EKS(cluster *models.ClusterRegistration) (api platformaws.EKSAPI, eksName string, ok bool) {
+	eksName = cluster.EKSClusterName
+	if eksName == "" {
+		if cluster.Source != models.ClusterSourceEKS {
+			return nil, "", false
+		}
+		eksName = cluster.Name
+	}
+
+	return r.newEKS(r.regionConfig(cluster)), eksName, true
+}
+
+func (r *ClusterRegistry) regionConfig(cluster *models.ClusterRegistration) aws.Config {
+	awsConfig := r.awsConfig.Copy()
+	if cluster.Region != "" {
+		awsConfig.Region = cluster.Region
+	}
+	return awsConfig
+}
+
+func (r *ClusterRegistry) buildEKSClient(cluster *models.ClusterRegistration) (*k8s.Client, error) {
+	api, eksName, _ := r.EKS(cluster)
+	awsConfig := r.regionConfig(cluster)
+
+	// Client has no caller context; bound the lookup so a hung AWS endpoint
+	// does not hold up every request for the cluster.
+	ctx, cancel := context.WithTimeout(context.Background(), eksDescribeTimeout)
+	defer cancel()
+
+	output, err := api.DescribeCluster(ctx, eksName)
+	if err != nil {
+		return nil, err
+	}
+	if output.Cluster == nil || output.Cluster.Endpoint == nil || output.Cluster.CertificateAuthority == nil {
+		return nil, fmt.Errorf("EKS cluster %s has no endpoint yet", eksName)
+	}
+
+	caData, err := base64.StdEncoding.DecodeString(aws.ToString(output.Cluster.CertificateAuthority.Data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate authority for EKS cluster %s: %v", eksName, err)
+	}
+
+	config := k8s.RESTConfigWithToken(aws.ToString(output.Cluster.Endpoint), caData, func() (string, time.Time, error) {
+		return platformaws.EKSToken(awsConfig, eksName)
+	})
+
+	return k8s.NewClientFromRESTConfig(config)
+}
+
+// CacheStatus reports whether each connected cluster's informer cache has
+// synced. Clusters that have not been used yet are not listed.
+func (r *ClusterRegistry) CacheStatus() map[string]bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := make(map[string]bool, len(r.clients))
+	for name, client := range r.clients {
+		if name != "" {
+			status[name] = client.CacheSynced()
+		}
+	}
+	return status
+}
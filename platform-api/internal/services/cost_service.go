@@ -13,6 +13,14 @@ import (
 	"github.com/google/uuid"
 )
 
+// Costs are attributed by AWS cost allocation tags: TenantID for tenant spend
+// and Project for the platform as a whole.
+const (
+	tenantCostTag  = "TenantID"
+	projectCostTag = "Project"
+	costProject    = "devplatform"
+)
+
 type CostService struct {
 	costExplorer *costexplorer.Client
 }
@@ -44,7 +52,7 @@ func (s *CostService) GetTenantCosts(tenantID uuid.UUID, req *models.CostRequest
 		GroupBy: []types.GroupDefinition{
 			{
 				Type: types.GroupDefinitionTypeTag,
-				Key:  aws.String("TenantID"),
+				Key:  aws.String(tenantCostTag),
 			},
 			{
 				Type: types.GroupDefinitionTypeDimension,
@@ -53,7 +61,7 @@ func (s *CostService) GetTenantCosts(tenantID uuid.UUID, req *models.CostRequest
 		},
 		Filter: &types.Expression{
 			Tags: &types.TagValues{
-				Key:    aws.String("TenantID"),
+				Key:    aws.String(tenantCostTag),
 				Values: []string{tenantID.String()},
 			},
 		},
@@ -124,8 +132,8 @@ func (s *CostService) GetPlatformCostOverview(req *models.CostRequest) (*models.
 		},
 		Filter: &types.Expression{
 			Tags: &types.TagValues{
-				Key:    aws.String("Project"),
-				Values: []string{"devplatform"},
+				Key:    aws.String(projectCostTag),
+				Values: []string{costProject},
 			},
 		},
 	}
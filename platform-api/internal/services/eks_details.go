@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"devplatform/platform-api/internal/models"
+	platformaws "devplatform/platform-api/pkg/aws"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+)
+
+// eksDetailsTTL is how long EKS details are reused. Describing a cluster
+// costs a call per add-on and node group, and the status page is polled.
+const eksDetailsTTL = time.Minute
+
+type cachedEKSDetails struct {
+	details   *models.EKSDetails
+	fetchedAt time.Time
+}
+
+// describeEKS returns the EKS details of a registered cluster, reusing a
+// result fetched within eksDetailsTTL. Failures are not cached.
+func (s *K8sService) describeEKS(ctx context.Context, clusterName string, api platformaws.EKSAPI, eksName string) (*models.EKSDetails, error) {
+	s.eksMu.Lock()
+	cached, ok := s.eksDetails[clusterName]
+	s.eksMu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < eksDetailsTTL {
+		return cached.details, nil
+	}
+
+	details, err := describeEKS(ctx, api, eksName)
+	if err != nil {
+		return nil, err
+	}
+
+	s.eksMu.Lock()
+	s.eksDetails[clusterName] = cachedEKSDetails{details: details, fetchedAt: time.Now()}
+	s.eksMu.Unlock()
+	return details, nil
+}
+
+// describeEKS collects control-plane, add-on and node group details for an
+// EKS cluster.
+func describeEKS(ctx context.Context, api platformaws.EKSAPI, eksName string) (*models.EKSDetails, error) {
+	output, err := api.DescribeCluster(ctx, eksName)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &models.EKSDetails{
+		ClusterName: eksName,
+		Logging:     []models.EKSLogSetting{},
+		Addons:      []models.EKSAddon{},
+		NodeGroups:  []models.EKSNodeGroup{},
+	}
+
+	if cluster := output.Cluster; cluster != nil {
+		details.ARN = aws.ToString(cluster.Arn)
+		details.Status = string(cluster.Status)
+		details.KubernetesVersion = aws.ToString(cluster.Version)
+		details.PlatformVersion = aws.ToString(cluster.PlatformVersion)
+		details.Endpoint = aws.ToString(cluster.Endpoint)
+		details.CreatedAt = aws.ToTime(cluster.CreatedAt)
+
+		if vpc := cluster.ResourcesVpcConfig; vpc != nil {
+			details.EndpointAccess = endpointAccess(vpc.EndpointPublicAccess, vpc.EndpointPrivateAccess)
+			if vpc.EndpointPublicAccess {
+				details.PublicAccessCIDRs = vpc.PublicAccessCidrs
+			}
+		}
+
+		if cluster.Logging != nil {
+			for _, setup := range cluster.Logging.ClusterLogging {
+				for _, logType := range setup.Types {
+					details.Logging = append(details.Logging, models.EKSLogSetting{
+						Type:    string(logType),
+						Enabled: aws.ToBool(setup.Enabled),
+					})
+				}
+			}
+		}
+	}
+
+	addons, err := api.DescribeAddons(ctx, eksName)
+	if err != nil {
+		return nil, err
+	}
+	for _, addon := range addons {
+		details.Addons = append(details.Addons, models.EKSAddon{
+			Name:    aws.ToString(addon.AddonName),
+			Version: aws.ToString(addon.AddonVersion),
+			Status:  string(addon.Status),
+		})
+	}
+
+	nodegroups, err := api.DescribeNodegroups(ctx, eksName)
+	if err != nil {
+		return nil, err
+	}
+	for _, ng := range nodegroups {
+		details.NodeGroups = append(details.NodeGroups, nodeGroupDetails(ng))
+	}
+
+	return details, nil
+}
+
+func nodeGroupDetails(ng types.Nodegroup) models.EKSNodeGroup {
+	group := models.EKSNodeGroup{
+		Name:           aws.ToString(ng.NodegroupName),
+		Status:         string(ng.Status),
+		Version:        aws.ToString(ng.Version),
+		ReleaseVersion: aws.ToString(ng.ReleaseVersion),
+		AMIType:        string(ng.AmiType),
+		CapacityType:   string(ng.CapacityType),
+		InstanceTypes:  ng.InstanceTypes,
+	}
+
+	if ng.ScalingConfig != nil {
+		group.Scaling = models.EKSScalingConfig{
+			MinSize:     aws.ToInt32(ng.ScalingConfig.MinSize),
+			MaxSize:     aws.ToInt32(ng.ScalingConfig.MaxSize),
+			DesiredSize: aws.ToInt32(ng.ScalingConfig.DesiredSize),
+		}
+	}
+
+	return group
+}
+
+func endpointAccess(public, private bool) string {
+	switch {
+	case public && private:
+		return "public-and-private"
+	case public:
+		return "public"
+	case private:
+		return "private"
+	}
+	return "none"
+}
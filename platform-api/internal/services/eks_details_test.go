@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"devplatform/platform-api/internal/models"
+	platformaws "devplatform/platform-api/pkg/aws"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+)
+
+// stubEKS serves DescribeCluster outputs by EKS cluster name and counts the
+// calls made for each.
+type stubEKS struct {
+	mu       sync.Mutex
+	clusters map[string]*types.Cluster
+	groups   []types.Nodegroup
+	err      error
+	calls    map[string]int
+}
+
+func (s *stubEKS) DescribeCluster(ctx context.Context, name string) (*eks.DescribeClusterOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[name]++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &eks.DescribeClusterOutput{Cluster: s.clusters[name]}, nil
+}
+
+func (s *stubEKS) DescribeAddons(ctx context.Context, name string) ([]types.Addon, error) {
+	return []types.Addon{{AddonName: aws.String("vpc-cni"), AddonVersion: aws.String("v1.15.0"), Status: types.AddonStatusActive}}, nil
+}
+
+func (s *stubEKS) DescribeNodegroups(ctx context.Context, name string) ([]types.Nodegroup, error) {
+	return s.groups, nil
+}
+
+func (s *stubEKS) callCount(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[name]
+}
+
+// testEKSService returns a service whose registry hands out stub for every
+// region, recording the regions asked for.
+func testEKSService(stub *stubEKS) (*K8sService, *[]string) {
+	var regions []string
+	registry := &ClusterRegistry{
+		newEKS: func(cfg aws.Config) platformaws.EKSAPI {
+			regions = append(regions, cfg.Region)
+			return stub
+		},
+	}
+	return NewK8sService(registry), &regions
+}
+
+func describeRegistered(t *testing.T, s *K8sService, cluster *models.ClusterRegistration) (*models.EKSDetails, error) {
+	t.Helper()
+	api, eksName, ok := s.clusters.EKS(cluster)
+	if !ok {
+		t.Fatalf("EKS(%s) reported no EKS cluster", cluster.Name)
+	}
+	return s.describeEKS(context.Background(), cluster.Name, api, eksName)
+}
+
+func TestDescribeEKSCachesPerCluster(t *testing.T) {
+	stub := &stubEKS{
+		clusters: map[string]*types.Cluster{
+			"prod-eks":    {Name: aws.String("prod-eks"), Endpoint: aws.String("https://prod.example"), Version: aws.String("1.28")},
+			"staging-eks": {Name: aws.String("staging-eks"), Endpoint: aws.String("https://staging.example"), Version: aws.String("1.29")},
+		},
+		calls: map[string]int{},
+	}
+	s, regions := testEKSService(stub)
+	prod := &models.ClusterRegistration{Name: "prod", Source: models.ClusterSourceEKS, EKSClusterName: "prod-eks", Region: "eu-west-1"}
+	staging := &models.ClusterRegistration{Name: "staging", Source: models.ClusterSourceKubeconfig, EKSClusterName: "staging-eks", Region: "us-east-1"}
+
+	for i := 0; i < 3; i++ {
+		details, err := describeRegistered(t, s, prod)
+		if err != nil {
+			t.Fatalf("describeEKS(prod): %v", err)
+		}
+		if details.Endpoint != "https://prod.example" || details.KubernetesVersion != "1.28" {
+			t.Errorf("prod details = %+v", details)
+		}
+	}
+	details, err := describeRegistered(t, s, staging)
+	if err != nil {
+		t.Fatalf("describeEKS(staging): %v", err)
+	}
+	if details.Endpoint != "https://staging.example" {
+		t.Errorf("staging endpoint = %q, want the staging cluster's", details.Endpoint)
+	}
+
+	if got := stub.callCount("prod-eks"); got != 1 {
+		t.Errorf("prod described %d times, want 1 within the TTL", got)
+	}
+	if got := stub.callCount("staging-eks"); got != 1 {
+		t.Errorf("staging described %d times, want 1", got)
+	}
+	if len(*regions) == 0 || (*regions)[0] != "eu-west-1" || (*regions)[len(*regions)-1] != "us-east-1" {
+		t.Errorf("regions = %v, want each cluster's own", *regions)
+	}
+
+	// Once the TTL has passed the cluster is described again.
+	s.eksMu.Lock()
+	cached := s.eksDetails["prod"]
+	cached.fetchedAt = time.Now().Add(-eksDetailsTTL - time.Second)
+	s.eksDetails["prod"] = cached
+	s.eksMu.Unlock()
+
+	if _, err := describeRegistered(t, s, prod); err != nil {
+		t.Fatalf("describeEKS(prod): %v", err)
+	}
+	if got := stub.callCount("prod-eks"); got != 2 {
+		t.Errorf("prod described %d times, want 2 after the TTL", got)
+	}
+}
+
+func TestDescribeEKSDoesNotCacheFailures(t *testing.T) {
+	stub := &stubEKS{err: errors.New("access denied"), calls: map[string]int{}}
+	s, _ := testEKSService(stub)
+	cluster := &models.ClusterRegistration{Name: "prod", Source: models.ClusterSourceEKS}
+
+	for i := 0; i < 2; i++ {
+		if _, err := describeRegistered(t, s, cluster); err == nil {
+			t.Fatal("describeEKS succeeded, want the API error")
+		}
+	}
+	if got := stub.callCount("prod"); got != 2 {
+		t.Errorf("described %d times, want 2 with failures not cached", got)
+	}
+}
+
+func TestDescribeEKSHandlesMissingFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		cluster *types.Cluster
+		groups  []types.Nodegroup
+		check   func(t *testing.T, details *models.EKSDetails)
+	}{
+		{
+			name: "no cluster in the output",
+			check: func(t *testing.T, details *models.EKSDetails) {
+				if details.Endpoint != "" || details.EndpointAccess != "" {
+					t.Errorf("details = %+v, want no control-plane fields", details)
+				}
+			},
+		},
+		{
+			name:    "cluster without VPC or logging config",
+			cluster: &types.Cluster{Name: aws.String("prod"), Status: types.ClusterStatusActive},
+			check: func(t *testing.T, details *models.EKSDetails) {
+				if details.Status != "ACTIVE" || details.EndpointAccess != "" || len(details.Logging) != 0 {
+					t.Errorf("details = %+v", details)
+				}
+			},
+		},
+		{
+			name: "node group without scaling config",
+			groups: []types.Nodegroup{{
+				NodegroupName: aws.String("default"),
+				InstanceTypes: []string{"m5.large"},
+			}},
+			check: func(t *testing.T, details *models.EKSDetails) {
+				if len(details.NodeGroups) != 1 {
+					t.Fatalf("got %d node groups, want 1", len(details.NodeGroups))
+				}
+				if group := details.NodeGroups[0]; group.Name != "default" || group.Scaling != (models.EKSScalingConfig{}) {
+					t.Errorf("node group = %+v", group)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubEKS{clusters: map[string]*types.Cluster{"prod": tt.cluster}, groups: tt.groups, calls: map[string]int{}}
+			s, _ := testEKSService(stub)
+
+			details, err := describeRegistered(t, s, &models.ClusterRegistration{Name: "prod", Source: models.ClusterSourceEKS})
+			if err != nil {
+				t.Fatalf("describeEKS: %v", err)
+			}
+			if details.ClusterName != "prod" || len(details.Addons) != 1 {
+				t.Errorf("details = %+v", details)
+			}
+			tt.check(t, details)
+		})
+	}
+}
+
+func TestEKSSkipsOtherClusters(t *testing.T) {
+	s, regions := testEKSService(&stubEKS{calls: map[string]int{}})
+
+	if _, _, ok := s.clusters.EKS(&models.ClusterRegistration{Name: "local", Source: models.ClusterSourceKubeconfig}); ok {
+		t.Error("EKS reported an EKS cluster for a kubeconfig registration without an EKS name")
+	}
+	if len(*regions) != 0 {
+		t.Errorf("built %d EKS clients, want none", len(*regions))
+	}
+}
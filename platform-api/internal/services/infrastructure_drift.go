@@ -0,0 +1,348 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"devplatform/platform-api/internal/events"
+	"devplatform/platform-api/internal/models"
+	"devplatform/platform-api/pkg/terraform"
+	"github.com/google/uuid"
+)
+
+const (
+	// driftPollInterval is how often pending checks are collected and due
+	// tenants get a new one.
+	driftPollInterval = time.Minute
+	driftHistoryLimit = 20
+
+	// driftLockID is the Postgres advisory lock that keeps more than one
+	// replica from collecting and starting checks in the same poll.
+	driftLockID = 0x6472696674
+
+	driftPending = "pending"
+	driftFound   = "drifted"
+	driftInSync  = "in_sync"
+	driftErrored = "errored"
+)
+
+// RunDriftDetection checks every active tenant that has applied
+// infrastructure for drift once per interval, until stop is closed. A check
+// is a plan-only run, so it never changes anything; it is recorded when the
+// plan finishes and an infrastructure.drift event is published when drift
+// first appears and when it is resolved. Each poll runs on one replica only.
+func (s *InfrastructureService) RunDriftDetection(interval time.Duration, stop <-chan struct{}) {
+	if !s.Enabled() {
+		return
+	}
+
+	ticker := time.NewTicker(driftPollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.checkDrift(interval)
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (s *InfrastructureService) checkDrift(interval time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*driftPollInterval)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Warning: drift detection failed to start: %v", err)
+		return
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, driftLockID).Scan(&locked); err != nil || !locked {
+		return
+	}
+
+	s.collectDriftChecks(ctx)
+	s.startDriftChecks(ctx, interval)
+}
+
+// Drift returns the drift detection results of a tenant.
+func (s *InfrastructureService) Drift(ctx context.Context, tenantID uuid.UUID) (*models.InfrastructureDrift, error) {
+	if _, err := findTenant(ctx, s.db, tenantID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + driftCheckColumns + ` FROM drift_checks WHERE tenant_id = $1 ORDER BY started_at DESC LIMIT $2`
+	rows, err := s.db.QueryContext(ctx, query, tenantID, driftHistoryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list drift checks: %v", err)
+	}
+	defer rows.Close()
+
+	drift := &models.InfrastructureDrift{Checks: []models.DriftCheck{}}
+	for rows.Next() {
+		check, err := scanDriftCheck(rows)
+		if err != nil {
+			return nil, err
+		}
+		drift.Checks = append(drift.Checks, *check)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list drift checks: %v", err)
+	}
+
+	latest, err := s.latestDriftCheck(ctx, tenantID, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	drift.Latest = latest
+	drift.Drifted = latest != nil && latest.Status == driftFound
+	return drift, nil
+}
+
+// startDriftChecks queues a check for each tenant without one started within
+// the interval. A tenant whose run cannot be queued gets an errored check,
+// so it is retried at the next interval rather than every poll.
+func (s *InfrastructureService) startDriftChecks(ctx context.Context, interval time.Duration) {
+	query := `
+		SELECT t.id FROM tenants t
+		WHERE t.status = 'active'
+			AND EXISTS (SELECT 1 FROM infrastructure_runs r WHERE r.tenant_id = t.id AND r.status = $1)
+			AND NOT EXISTS (SELECT 1 FROM drift_checks d WHERE d.tenant_id = t.id AND (d.finished_at IS NULL OR d.started_at > $2))
+	`
+	rows, err := s.db.QueryContext(ctx, query, terraform.RunApplied, time.Now().Add(-interval))
+	if err != nil {
+		log.Printf("Warning: drift detection failed to list tenants: %v", err)
+		return
+	}
+	var tenantIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err == nil {
+			tenantIDs = append(tenantIDs, id)
+		}
+	}
+	rows.Close()
+
+	for _, tenantID := range tenantIDs {
+		check := &models.DriftCheck{
+			ID:        uuid.New(),
+			TenantID:  tenantID,
+			Status:    driftPending,
+			Resources: []models.DriftedResource{},
+			StartedAt: time.Now(),
+		}
+
+		run, err := s.startDriftRun(ctx, tenantID)
+		if err != nil {
+			log.Printf("Warning: failed to start drift check for tenant %s: %v", tenantID, err)
+			check.Status = driftErrored
+			check.Error = err.Error()
+			check.FinishedAt = &check.StartedAt
+		} else {
+			check.RunID = run.ID
+			check.URL = run.URL
+		}
+
+		query := `
+			INSERT INTO drift_checks (id, tenant_id, run_id, url, status, resources, error, started_at, finished_at)
+			VALUES ($1, $2, $3, $4, $5, '[]', $6, $7, $8)
+		`
+		_, err = s.db.ExecContext(ctx, query, check.ID, check.TenantID, check.RunID, check.URL, check.Status,
+			check.Error, check.StartedAt, check.FinishedAt)
+		if err != nil {
+			log.Printf("Warning: failed to record drift check for tenant %s: %v", tenantID, err)
+		}
+	}
+}
+
+func (s *InfrastructureService) startDriftRun(ctx context.Context, tenantID uuid.UUID) (*terraform.RunState, error) {
+	tenant, err := findTenant(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.executor.StartRun(ctx, terraform.RunRequest{
+		Workspace: workspaceName(tenant),
+		Message:   "Scheduled drift detection",
+		PlanOnly:  true,
+	})
+}
+
+// collectDriftChecks records the result of every pending check whose plan
+// has finished. A check still running after runTrackTimeout is errored.
+func (s *InfrastructureService) collectDriftChecks(ctx context.Context) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+driftCheckColumns+` FROM drift_checks WHERE finished_at IS NULL`)
+	if err != nil {
+		log.Printf("Warning: drift detection failed to list pending checks: %v", err)
+		return
+	}
+	var pending []*models.DriftCheck
+	for rows.Next() {
+		check, err := scanDriftCheck(rows)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			continue
+		}
+		pending = append(pending, check)
+	}
+	rows.Close()
+
+	for _, check := range pending {
+		done, err := s.evaluateDriftCheck(ctx, check)
+		if err != nil {
+			log.Printf("Warning: failed to collect drift check %s: %v", check.RunID, err)
+			continue
+		}
+		if !done {
+			continue
+		}
+		if err := s.finishDriftCheck(ctx, check); err != nil {
+			log.Printf("Warning: failed to record drift check %s: %v", check.RunID, err)
+		}
+	}
+}
+
+// evaluateDriftCheck fills in the result of a check, reporting false while
+// its run is still going.
+func (s *InfrastructureService) evaluateDriftCheck(ctx context.Context, check *models.DriftCheck) (bool, error) {
+	run, err := s.executor.GetRun(ctx, check.RunID)
+	if terraform.IsNotFound(err) {
+		check.Status = driftErrored
+		check.Error = "drift check run no longer exists"
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if !run.Final {
+		if time.Since(check.StartedAt) < runTrackTimeout {
+			return false, nil
+		}
+		check.Status = driftErrored
+		check.Error = fmt.Sprintf("drift check did not finish within %s", runTrackTimeout)
+		return true, nil
+	}
+	if run.Status != terraform.RunPlannedAndFinished {
+		check.Status = driftErrored
+		check.Error = fmt.Sprintf("drift check run ended with status %s", run.Status)
+		return true, nil
+	}
+
+	plan, err := s.executor.PlanJSON(ctx, check.RunID)
+	if err != nil {
+		return false, fmt.Errorf("failed to read plan: %v", err)
+	}
+	drifted, err := terraform.ResourceDrift(plan)
+	if err != nil {
+		return false, err
+	}
+
+	check.Status = driftInSync
+	check.Resources = make([]models.DriftedResource, 0, len(drifted))
+	for _, r := range drifted {
+		check.Resources = append(check.Resources, models.DriftedResource(r))
+	}
+	if len(check.Resources) > 0 {
+		check.Status = driftFound
+	}
+	return true, nil
+}
+
+// finishDriftCheck saves a completed check and publishes a change from the
+// tenant's previous result. Errored checks leave the previous result
+// standing.
+func (s *InfrastructureService) finishDriftCheck(ctx context.Context, check *models.DriftCheck) error {
+	now := time.Now()
+	check.FinishedAt = &now
+
+	previous, err := s.latestDriftCheck(ctx, check.TenantID, check.ID)
+	if err != nil {
+		return err
+	}
+	wasDrifted := previous != nil && previous.Status == driftFound
+	if check.Status == driftFound {
+		check.DriftedSince = &check.StartedAt
+		if wasDrifted && previous.DriftedSince != nil {
+			check.DriftedSince = previous.DriftedSince
+		}
+	}
+
+	resources, err := json.Marshal(check.Resources)
+	if err != nil {
+		return fmt.Errorf("failed to encode drifted resources: %v", err)
+	}
+	query := `
+		UPDATE drift_checks SET status = $1, resources = $2, error = $3, drifted_since = $4, finished_at = $5
+		WHERE id = $6
+	`
+	_, err = s.db.ExecContext(ctx, query, check.Status, resources, check.Error, check.DriftedSince, check.FinishedAt, check.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update drift check: %v", err)
+	}
+
+	switch {
+	case check.Status == driftFound && !wasDrifted:
+		s.publishDrift(check, "detected")
+	case check.Status == driftInSync && wasDrifted:
+		s.publishDrift(check, "resolved")
+	}
+	return nil
+}
+
+// latestDriftCheck returns the tenant's most recent conclusive check other
+// than exclude, or nil if it has none.
+func (s *InfrastructureService) latestDriftCheck(ctx context.Context, tenantID, exclude uuid.UUID) (*models.DriftCheck, error) {
+	query := `
+		SELECT ` + driftCheckColumns + ` FROM drift_checks
+		WHERE tenant_id = $1 AND id <> $2 AND status IN ($3, $4)
+		ORDER BY started_at DESC LIMIT 1
+	`
+	check, err := scanDriftCheck(s.db.QueryRowContext(ctx, query, tenantID, exclude, driftFound, driftInSync))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return check, err
+}
+
+func (s *InfrastructureService) publishDrift(check *models.DriftCheck, eventType string) {
+	s.events.Publish(events.Event{
+		Topic:    events.TopicInfraDrift,
+		Type:     eventType,
+		TenantID: check.TenantID.String(),
+		Data:     check,
+	})
+}
+
+const driftCheckColumns = `id, tenant_id, run_id, url, status, resources, error, drifted_since, started_at, finished_at`
+
+func scanDriftCheck(row rowScanner) (*models.DriftCheck, error) {
+	var check models.DriftCheck
+	var resources []byte
+	var driftedSince, finishedAt sql.NullTime
+	err := row.Scan(&check.ID, &check.TenantID, &check.RunID, &check.URL, &check.Status, &resources, &check.Error,
+		&driftedSince, &check.StartedAt, &finishedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan drift check: %v", err)
+	}
+	if err := json.Unmarshal(resources, &check.Resources); err != nil {
+		return nil, fmt.Errorf("failed to decode drifted resources: %v", err)
+	}
+	if driftedSince.Valid {
+		check.DriftedSince = &driftedSince.Time
+	}
+	if finishedAt.Valid {
+		check.FinishedAt = &finishedAt.Time
+	}
+	return &check, nil
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"devplatform/platform-api/internal/models"
+	"devplatform/platform-api/pkg/terraform"
+	"github.com/google/uuid"
+)
+
+// Outputs returns the root module outputs of the tenant's current state.
+// Sensitive values are redacted unless reveal is set; callers decide who may
+// reveal them. A tenant that has never applied has no outputs.
+func (s *InfrastructureService) Outputs(ctx context.Context, tenantID uuid.UUID, reveal bool) ([]models.InfrastructureOutput, error) {
+	if !s.Enabled() {
+		return nil, ErrInfrastructureDisabled
+	}
+
+	tenant, err := findTenant(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := s.currentOutputs(ctx, tenant, reveal)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := []models.InfrastructureOutput{}
+	for _, o := range remote {
+		output := models.InfrastructureOutput{
+			Name:      o.Name,
+			Type:      o.Type,
+			Sensitive: o.Sensitive,
+		}
+		if o.Sensitive && !reveal {
+			output.Redacted = true
+			outputs = append(outputs, output)
+			continue
+		}
+
+		if len(o.Value) > 0 {
+			if err := json.Unmarshal(o.Value, &output.Value); err != nil {
+				return nil, fmt.Errorf("failed to decode output %s: %v", o.Name, err)
+			}
+		}
+		outputs = append(outputs, output)
+	}
+
+	return outputs, nil
+}
+
+// SyncOutputs writes the tenant's non-sensitive outputs into the
+// infrastructure-outputs ConfigMap of its namespace, replacing what was there,
+// and returns the keys written. Strings are stored as-is and other values as
+// JSON.
+func (s *InfrastructureService) SyncOutputs(ctx context.Context, tenantID uuid.UUID) ([]string, error) {
+	if !s.Enabled() {
+		return nil, ErrInfrastructureDisabled
+	}
+
+	tenant, err := findTenant(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := s.currentOutputs(ctx, tenant, false)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]string)
+	keys := []string{}
+	for _, o := range remote {
+		if o.Sensitive || len(o.Value) == 0 {
+			continue
+		}
+		var str string
+		if json.Unmarshal(o.Value, &str) == nil {
+			data[o.Name] = str
+		} else {
+			data[o.Name] = string(o.Value)
+		}
+		keys = append(keys, o.Name)
+	}
+
+	client, err := s.clusters.Client(tenant.ClusterName)
+	if err != nil {
+		return nil, err
+	}
+	labels := map[string]string{"created-by": "platform-api"}
+	if err := client.ApplyConfigMap(ctx, tenant.Namespace, outputsConfigMap, labels, data); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (s *InfrastructureService) currentOutputs(ctx context.Context, tenant *models.Tenant, sensitive bool) ([]terraform.Output, error) {
+	outputs, err := s.executor.Outputs(ctx, workspaceName(tenant), sensitive)
+	if terraform.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outputs: %v", err)
+	}
+	return outputs, nil
+}
@@ -0,0 +1,749 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"devplatform/platform-api/internal/events"
+	"devplatform/platform-api/internal/models"
+	"devplatform/platform-api/pkg/policy"
+	"devplatform/platform-api/pkg/terraform"
+	"github.com/google/uuid"
+)
+
+const (
+	runPollInterval = 5 * time.Second
+	runTrackTimeout = 2 * time.Hour
+
+	// outputsConfigMap receives a tenant's non-sensitive outputs.
+	outputsConfigMap = "infrastructure-outputs"
+
+	policyPassed = "passed"
+	policyFailed = "failed"
+
+	// A tenant's configuration is either uploaded with runs or generated
+	// from its template resources, never both, so neither replaces the
+	// other.
+	configSourceUpload    = "upload"
+	configSourceTemplates = "templates"
+)
+
+var (
+	ErrInfrastructureDisabled = errors.New("terraform is not configured")
+	ErrRunNotFound            = errors.New("infrastructure run not found")
+	ErrRunNotConfirmable      = errors.New("infrastructure run is not waiting for confirmation")
+	ErrInvalidConfiguration   = terraform.ErrInvalidConfiguration
+	ErrPlanNotReady           = errors.New("plan is not available yet")
+	ErrPolicyFailed           = errors.New("infrastructure run failed policy checks")
+	ErrTeardownFailed         = errors.New("infrastructure teardown failed")
+	ErrConfigurationConflict  = errors.New("workspace configuration is managed by another source")
+	ErrTenantBeingDeleted     = errors.New("tenant is being deleted")
+)
+
+// InfrastructureService manages the Terraform workspace that holds each
+// tenant's cloud resources and the runs against it. The executor decides
+// where Terraform runs: Terraform Cloud or the local binary. A nil service,
+// or one without an executor, means Terraform is not configured: workspace
+// housekeeping is skipped and run requests fail with
+// ErrInfrastructureDisabled.
+type InfrastructureService struct {
+	db       *sql.DB
+	clusters *ClusterRegistry
+	executor terraform.Executor
+	opts     InfrastructureOptions
+	events   *events.Bus
+}
+
+type InfrastructureOptions struct {
+	// SyncOutputs copies non-sensitive outputs into a ConfigMap in the
+	// tenant namespace after every successful apply.
+	SyncOutputs bool
+	// Policy, when set, is checked against every plan before it can be
+	// applied, including runs that asked to auto-apply.
+	Policy *policy.Policy
+}
+
+func NewInfrastructureService(db *sql.DB, clusters *ClusterRegistry, executor terraform.Executor, opts InfrastructureOptions, bus *events.Bus) *InfrastructureService {
+	return &InfrastructureService{
+		db:       db,
+		clusters: clusters,
+		executor: executor,
+		opts:     opts,
+		events:   bus,
+	}
+}
+
+func (s *InfrastructureService) Enabled() bool {
+	return s != nil && s.executor != nil
+}
+
+// EnsureWorkspace returns the tenant's workspace, creating it if needed, and
+// brings its seeded variables up to date. It is safe to call again after a
+// partial failure. A tenant being deleted is refused, so its workspace is
+// not recreated behind the teardown.
+func (s *InfrastructureService) EnsureWorkspace(ctx context.Context, tenant *models.Tenant) (*terraform.WorkspaceInfo, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+	if err := checkNotDeleting(tenant); err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.executor.EnsureWorkspace(ctx, terraform.WorkspaceSpec{
+		Name:        workspaceName(tenant),
+		Description: fmt.Sprintf("Infrastructure for tenant %s", tenant.Name),
+		Tags:        []string{"platform-tenant"},
+		Variables:   workspaceVariables(tenant),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to ensure workspace: %v", err)
+	}
+	return workspace, nil
+}
+
+// workspaceVariables are the variables every tenant configuration can rely
+// on. default_tags holds the cost allocation tags CostService reports by;
+// configurations pass it to the AWS provider's default_tags so every
+// resource is attributed to the tenant.
+func workspaceVariables(tenant *models.Tenant) []terraform.VariableAttributes {
+	defaultTags := fmt.Sprintf("{\n  %s = %q\n  %s = %q\n}", tenantCostTag, tenant.ID.String(), projectCostTag, costProject)
+
+	return []terraform.VariableAttributes{
+		{Key: "tenant_id", Value: tenant.ID.String(), Category: terraform.CategoryTerraform, Description: "Platform tenant ID"},
+		{Key: "namespace", Value: tenant.Namespace, Category: terraform.CategoryTerraform, Description: "Kubernetes namespace of the tenant"},
+		{Key: "cluster_name", Value: tenant.ClusterName, Category: terraform.CategoryTerraform, Description: "Cluster the tenant runs on"},
+		{Key: "default_tags", Value: defaultTags, Category: terraform.CategoryTerraform, HCL: true, Description: "Cost allocation tags for the AWS provider's default_tags"},
+	}
+}
+
+// GetWorkspace returns the tenant's workspace, or nil if it has none.
+func (s *InfrastructureService) GetWorkspace(ctx context.Context, tenant *models.Tenant) (*terraform.WorkspaceInfo, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+
+	workspace, err := s.executor.GetWorkspace(ctx, workspaceName(tenant))
+	if terraform.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %v", err)
+	}
+	return workspace, nil
+}
+
+func (s *InfrastructureService) DeleteWorkspace(ctx context.Context, tenant *models.Tenant) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	err := s.executor.DeleteWorkspace(ctx, workspaceName(tenant))
+	if err != nil && !terraform.IsNotFound(err) {
+		return fmt.Errorf("failed to delete workspace: %v", err)
+	}
+	return nil
+}
+
+// Teardown destroys the resources in the tenant's workspace, waits for the
+// destroy run to finish and then deletes the workspace. Runs waiting for
+// confirmation are discarded first so the destroy is not queued behind them.
+// The destroy is queued whenever the workspace exists, whether or not the
+// platform recorded runs on it; a resumed teardown waits for the destroy a
+// previous attempt left unfinished instead of queueing another. When the
+// destroy does not succeed the workspace is kept and the run is returned
+// with an error wrapping ErrTeardownFailed.
+func (s *InfrastructureService) Teardown(ctx context.Context, tenant *models.Tenant, requestedBy string) (*models.InfrastructureRun, error) {
+	workspace, err := s.GetWorkspace(ctx, tenant)
+	if err != nil || workspace == nil {
+		return nil, err
+	}
+
+	if err := s.discardPendingRuns(ctx, tenant.ID); err != nil {
+		return nil, err
+	}
+
+	run, err := s.unfinishedDestroyRun(ctx, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		// startRun skips the check StartRun makes against tenants being deleted.
+		run, err = s.startRun(ctx, tenant, &models.CreateInfrastructureRunRequest{
+			Message:   fmt.Sprintf("Destroy infrastructure of deleted tenant %s", tenant.Name),
+			IsDestroy: true,
+			AutoApply: true,
+		}, requestedBy, "")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrTeardownFailed, err)
+		}
+	}
+	if err := s.awaitRun(ctx, run); err != nil {
+		return run, fmt.Errorf("%w: destroy run %s: %v", ErrTeardownFailed, run.RunID, err)
+	}
+	if run.Status != terraform.RunApplied && run.Status != terraform.RunPlannedAndFinished {
+		return run, fmt.Errorf("%w: destroy run %s ended with status %s", ErrTeardownFailed, run.RunID, run.Status)
+	}
+
+	if err := s.DeleteWorkspace(ctx, tenant); err != nil {
+		return run, err
+	}
+	return run, nil
+}
+
+// unfinishedDestroyRun returns the tenant's latest destroy run that applies
+// itself and has not finished, or nil.
+func (s *InfrastructureService) unfinishedDestroyRun(ctx context.Context, tenantID uuid.UUID) (*models.InfrastructureRun, error) {
+	query := `
+		SELECT ` + infrastructureRunColumns + ` FROM infrastructure_runs
+		WHERE tenant_id = $1 AND is_destroy AND auto_apply AND finished_at IS NULL
+		ORDER BY created_at DESC LIMIT 1
+	`
+	run, err := scanInfrastructureRun(s.db.QueryRowContext(ctx, query, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return run, err
+}
+
+// discardPendingRuns discards the tenant's runs waiting for confirmation.
+// Destroy runs that apply themselves are left alone: with a policy they wait
+// for confirmation while the service checks them, and Teardown reuses them.
+func (s *InfrastructureService) discardPendingRuns(ctx context.Context, tenantID uuid.UUID) error {
+	query := `
+		SELECT ` + infrastructureRunColumns + ` FROM infrastructure_runs
+		WHERE tenant_id = $1 AND confirmable AND finished_at IS NULL AND NOT (is_destroy AND auto_apply)
+	`
+	rows, err := s.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to list pending runs: %v", err)
+	}
+	var pending []*models.InfrastructureRun
+	for rows.Next() {
+		run, err := scanInfrastructureRun(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		pending = append(pending, run)
+	}
+	rows.Close()
+
+	for _, run := range pending {
+		err := s.executor.DiscardRun(ctx, run.RunID, "Discarded because the tenant is being deleted")
+		if err != nil && !errors.Is(err, terraform.ErrNotConfirmable) {
+			return fmt.Errorf("failed to discard run %s: %v", run.RunID, err)
+		}
+		if err := s.refresh(ctx, run); err != nil {
+			log.Printf("Warning: failed to refresh run %s: %v", run.RunID, err)
+		}
+	}
+	return nil
+}
+
+// awaitRun waits for run to finish, reloading it as track records its
+// progress.
+func (s *InfrastructureService) awaitRun(ctx context.Context, run *models.InfrastructureRun) error {
+	for run.FinishedAt == nil {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("did not finish: %v", ctx.Err())
+		case <-time.After(runPollInterval):
+		}
+
+		latest, err := s.loadRun(ctx, run.RunID)
+		if err != nil {
+			return err
+		}
+		*run = *latest
+	}
+	return nil
+}
+
+// StartRun queues a run on the tenant's workspace, first uploading a new
+// configuration when the request carries files. Files are refused with
+// ErrConfigurationConflict for a tenant with template resources, whose
+// configuration is generated by TemplateService, and every run is refused
+// with ErrTenantBeingDeleted for a tenant being deleted. The run is then
+// tracked in the background until it finishes or waits for confirmation.
+func (s *InfrastructureService) StartRun(ctx context.Context, tenantID uuid.UUID, req *models.CreateInfrastructureRunRequest, requestedBy string) (*models.InfrastructureRun, error) {
+	if !s.Enabled() {
+		return nil, ErrInfrastructureDisabled
+	}
+
+	tenant, err := findTenant(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkNotDeleting(tenant); err != nil {
+		return nil, err
+	}
+
+	source := ""
+	if len(req.Files) > 0 {
+		var hasResources bool
+		query := `SELECT EXISTS (SELECT 1 FROM tenant_resources WHERE tenant_id = $1)`
+		if err := s.db.QueryRowContext(ctx, query, tenantID).Scan(&hasResources); err != nil {
+			return nil, fmt.Errorf("failed to check resources: %v", err)
+		}
+		if hasResources {
+			return nil, fmt.Errorf("%w: the tenant's configuration is generated from its resources", ErrConfigurationConflict)
+		}
+		source = configSourceUpload
+	}
+
+	return s.startRun(ctx, tenant, req, requestedBy, source)
+}
+
+// startRun queues a run without checking the request against the tenant's
+// configuration source, which is recorded as source.
+func (s *InfrastructureService) startRun(ctx context.Context, tenant *models.Tenant, req *models.CreateInfrastructureRunRequest, requestedBy, source string) (*models.InfrastructureRun, error) {
+	workspace, err := s.GetWorkspace(ctx, tenant)
+	if err == nil && workspace == nil {
+		workspace, err = s.EnsureWorkspace(ctx, tenant)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte, len(req.Files))
+	for name, content := range req.Files {
+		files[name] = []byte(content)
+	}
+	if err := s.checkFiles(files); err != nil {
+		return nil, err
+	}
+
+	message := req.Message
+	if message == "" {
+		message = fmt.Sprintf("Queued by %s via platform API", requestedBy)
+	}
+	// With a policy the service applies the run itself once the plan has
+	// passed, so the executor must wait for confirmation.
+	run, err := s.executor.StartRun(ctx, terraform.RunRequest{
+		Workspace: workspace.Name,
+		Files:     files,
+		Message:   message,
+		IsDestroy: req.IsDestroy,
+		AutoApply: req.AutoApply && s.opts.Policy == nil,
+	})
+	if errors.Is(err, ErrInvalidConfiguration) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to queue run: %v", err)
+	}
+
+	now := time.Now()
+	record := &models.InfrastructureRun{
+		ID:                     uuid.New(),
+		TenantID:               tenant.ID,
+		RunID:                  run.ID,
+		WorkspaceID:            run.WorkspaceID,
+		ConfigurationVersionID: run.ConfigurationVersionID,
+		Message:                message,
+		ConfigSource:           source,
+		IsDestroy:              req.IsDestroy,
+		AutoApply:              req.AutoApply,
+		Status:                 run.Status,
+		URL:                    run.URL,
+		RequestedBy:            requestedBy,
+		CreatedAt:              now,
+		UpdatedAt:              now,
+	}
+
+	query := `
+		INSERT INTO infrastructure_runs (id, tenant_id, run_id, workspace_id, configuration_version_id, message, config_source, is_destroy, auto_apply, status, url, requested_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+	_, err = s.db.ExecContext(ctx, query, record.ID, record.TenantID, record.RunID, record.WorkspaceID, record.ConfigurationVersionID,
+		record.Message, record.ConfigSource, record.IsDestroy, record.AutoApply, record.Status, record.URL, record.RequestedBy, record.CreatedAt, record.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record run: %v", err)
+	}
+	s.publishRun(record, "queued")
+
+	go s.track(record.RunID)
+
+	return record, nil
+}
+
+func (s *InfrastructureService) ListRuns(ctx context.Context, tenantID uuid.UUID) ([]models.InfrastructureRun, error) {
+	if _, err := findTenant(ctx, s.db, tenantID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + infrastructureRunColumns + `
+		FROM infrastructure_runs WHERE tenant_id = $1
+		ORDER BY created_at DESC LIMIT 100
+	`
+	rows, err := s.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %v", err)
+	}
+	defer rows.Close()
+
+	runs := []models.InfrastructureRun{}
+	for rows.Next() {
+		run, err := scanInfrastructureRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+
+	return runs, rows.Err()
+}
+
+// GetRun returns a tenant's run, refreshed from the executor unless it has
+// already finished.
+func (s *InfrastructureService) GetRun(ctx context.Context, tenantID uuid.UUID, runID string) (*models.InfrastructureRun, error) {
+	run, err := s.loadRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run.TenantID != tenantID {
+		return nil, ErrRunNotFound
+	}
+
+	if run.FinishedAt == nil && s.Enabled() {
+		if err := s.refresh(ctx, run); err != nil {
+			log.Printf("Warning: failed to refresh run %s: %v", run.RunID, err)
+		}
+	}
+	return run, nil
+}
+
+// RunLogs returns the plan and apply logs of a tenant's run.
+func (s *InfrastructureService) RunLogs(ctx context.Context, tenantID uuid.UUID, runID string) (*terraform.RunLogs, error) {
+	if !s.Enabled() {
+		return nil, ErrInfrastructureDisabled
+	}
+
+	run, err := s.GetRun(ctx, tenantID, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	logs, err := s.executor.RunLogs(ctx, run.RunID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run logs: %v", err)
+	}
+	return logs, nil
+}
+
+// PlanJSON returns the plan of a tenant's run in `terraform show -json`
+// format, or ErrPlanNotReady while the run is still planning.
+func (s *InfrastructureService) PlanJSON(ctx context.Context, tenantID uuid.UUID, runID string) (json.RawMessage, error) {
+	if !s.Enabled() {
+		return nil, ErrInfrastructureDisabled
+	}
+
+	run, err := s.GetRun(ctx, tenantID, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := s.executor.PlanJSON(ctx, run.RunID)
+	if terraform.IsNotFound(err) {
+		return nil, ErrPlanNotReady
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %v", err)
+	}
+	return plan, nil
+}
+
+func (s *InfrastructureService) ApplyRun(ctx context.Context, tenantID uuid.UUID, runID, comment string) (*models.InfrastructureRun, error) {
+	return s.confirmRun(ctx, tenantID, runID, comment, true)
+}
+
+func (s *InfrastructureService) DiscardRun(ctx context.Context, tenantID uuid.UUID, runID, comment string) (*models.InfrastructureRun, error) {
+	return s.confirmRun(ctx, tenantID, runID, comment, false)
+}
+
+func (s *InfrastructureService) confirmRun(ctx context.Context, tenantID uuid.UUID, runID, comment string, apply bool) (*models.InfrastructureRun, error) {
+	if !s.Enabled() {
+		return nil, ErrInfrastructureDisabled
+	}
+
+	if apply {
+		tenant, err := findTenant(ctx, s.db, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkNotDeleting(tenant); err != nil {
+			return nil, err
+		}
+	}
+
+	run, err := s.GetRun(ctx, tenantID, runID)
+	if err != nil {
+		return nil, err
+	}
+	if apply && run.PolicyStatus == policyFailed {
+		return nil, ErrPolicyFailed
+	}
+	if !run.Confirmable || apply && s.opts.Policy != nil && run.PolicyStatus != policyPassed {
+		return nil, ErrRunNotConfirmable
+	}
+
+	action := s.executor.DiscardRun
+	if apply {
+		action = s.executor.ApplyRun
+	}
+	if err := action(ctx, run.RunID, comment); err != nil {
+		if errors.Is(err, terraform.ErrNotConfirmable) {
+			return nil, ErrRunNotConfirmable
+		}
+		return nil, fmt.Errorf("failed to update run: %v", err)
+	}
+
+	if err := s.refresh(ctx, run); err != nil {
+		log.Printf("Warning: failed to refresh run %s: %v", run.RunID, err)
+	}
+	go s.track(run.RunID)
+
+	return run, nil
+}
+
+// ResumeTracking restarts tracking of runs left in progress by a previous
+// process. Runs waiting for confirmation stop being tracked on their first
+// refresh.
+func (s *InfrastructureService) ResumeTracking() {
+	if !s.Enabled() {
+		return
+	}
+
+	rows, err := s.db.Query(`SELECT run_id FROM infrastructure_runs WHERE finished_at IS NULL`)
+	if err != nil {
+		log.Printf("Warning: failed to resume run tracking: %v", err)
+		return
+	}
+	var runIDs []string
+	for rows.Next() {
+		var runID string
+		if err := rows.Scan(&runID); err == nil {
+			runIDs = append(runIDs, runID)
+		}
+	}
+	rows.Close()
+
+	for _, runID := range runIDs {
+		go s.track(runID)
+	}
+}
+
+// track polls a run until it finishes or waits for confirmation.
+func (s *InfrastructureService) track(runID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), runTrackTimeout)
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(runPollInterval):
+		}
+
+		run, err := s.loadRun(ctx, runID)
+		if err != nil {
+			return
+		}
+		if err := s.refresh(ctx, run); err != nil {
+			log.Printf("Warning: failed to refresh run %s: %v", runID, err)
+			continue
+		}
+		if run.FinishedAt != nil || s.awaitingConfirmation(run) {
+			return
+		}
+	}
+}
+
+// refresh updates run from the executor, saves it and publishes a change of
+// status.
+func (s *InfrastructureService) refresh(ctx context.Context, run *models.InfrastructureRun) error {
+	remote, err := s.executor.GetRun(ctx, run.RunID)
+	if err != nil {
+		return err
+	}
+
+	previous := run.Status
+	run.Status = remote.Status
+	run.HasChanges = remote.HasChanges
+	run.Confirmable = remote.Confirmable
+	run.ResourceAdditions = remote.ResourceAdditions
+	run.ResourceChanges = remote.ResourceChanges
+	run.ResourceDestructions = remote.ResourceDestructions
+
+	if run.Confirmable && run.PolicyStatus == "" && s.opts.Policy != nil {
+		if err := s.checkPolicy(ctx, run); err != nil {
+			log.Printf("Warning: failed to check policy for run %s: %v", run.RunID, err)
+		}
+	}
+
+	run.UpdatedAt = time.Now()
+	if remote.Final && run.FinishedAt == nil {
+		run.FinishedAt = &run.UpdatedAt
+	}
+
+	query := `
+		UPDATE infrastructure_runs SET status = $1, has_changes = $2, resource_additions = $3, resource_changes = $4,
+			resource_destructions = $5, confirmable = $6, policy_status = $7, policy_violations = $8, updated_at = $9, finished_at = $10
+		WHERE id = $11
+	`
+	violations, err := json.Marshal(run.PolicyViolations)
+	if err != nil {
+		return fmt.Errorf("failed to encode policy violations: %v", err)
+	}
+	_, err = s.db.ExecContext(ctx, query, run.Status, run.HasChanges, run.ResourceAdditions, run.ResourceChanges,
+		run.ResourceDestructions, run.Confirmable, run.PolicyStatus, violations, run.UpdatedAt, run.FinishedAt, run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update run: %v", err)
+	}
+
+	if run.Status != previous {
+		s.publishRun(run, run.Status)
+		if run.Status == terraform.RunApplied && s.opts.SyncOutputs {
+			if _, err := s.SyncOutputs(ctx, run.TenantID); err != nil {
+				log.Printf("Warning: failed to sync outputs for tenant %s: %v", run.TenantID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkPolicy evaluates the plan of a run waiting for confirmation. A run
+// that fails is discarded; one that passes is applied if it asked to be.
+func (s *InfrastructureService) checkPolicy(ctx context.Context, run *models.InfrastructureRun) error {
+	plan, err := s.executor.PlanJSON(ctx, run.RunID)
+	if err != nil {
+		return fmt.Errorf("failed to read plan: %v", err)
+	}
+	violations, err := s.opts.Policy.Evaluate(plan, run.IsDestroy)
+	if err != nil {
+		return err
+	}
+
+	if len(violations) > 0 {
+		run.PolicyStatus = policyFailed
+		run.PolicyViolations = make([]models.PolicyViolation, 0, len(violations))
+		for _, v := range violations {
+			run.PolicyViolations = append(run.PolicyViolations, models.PolicyViolation(v))
+		}
+		comment := fmt.Sprintf("Blocked by %d platform policy violation(s)", len(violations))
+		if err := s.executor.DiscardRun(ctx, run.RunID, comment); err != nil && !errors.Is(err, terraform.ErrNotConfirmable) {
+			return fmt.Errorf("failed to discard run: %v", err)
+		}
+		run.Confirmable = false
+		s.publishRun(run, "policy_failed")
+		return nil
+	}
+
+	run.PolicyStatus = policyPassed
+	if run.AutoApply {
+		if err := s.executor.ApplyRun(ctx, run.RunID, "Applied automatically after policy checks passed"); err != nil && !errors.Is(err, terraform.ErrNotConfirmable) {
+			return fmt.Errorf("failed to apply run: %v", err)
+		}
+		run.Confirmable = false
+	}
+	return nil
+}
+
+// checkFiles refuses configuration declaring data sources the policy does
+// not allow. Data sources are read while planning, so the plan's own policy
+// check would come after they had run.
+func (s *InfrastructureService) checkFiles(files map[string][]byte) error {
+	if s.opts.Policy == nil || len(files) == 0 {
+		return nil
+	}
+	violations, err := s.opts.Policy.CheckFiles(files)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfiguration, err)
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(violations))
+	for _, v := range violations {
+		messages = append(messages, v.Message)
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidConfiguration, strings.Join(messages, "; "))
+}
+
+// awaitingConfirmation reports whether a run is waiting for a user to apply
+// or discard it, rather than for the policy check or the service acting on
+// its result.
+func (s *InfrastructureService) awaitingConfirmation(run *models.InfrastructureRun) bool {
+	return run.Confirmable && (s.opts.Policy == nil || run.PolicyStatus == policyPassed && !run.AutoApply)
+}
+
+// loadRun reads a run by its executor run ID.
+func (s *InfrastructureService) loadRun(ctx context.Context, runID string) (*models.InfrastructureRun, error) {
+	query := `SELECT ` + infrastructureRunColumns + ` FROM infrastructure_runs WHERE run_id = $1`
+	run, err := scanInfrastructureRun(s.db.QueryRowContext(ctx, query, runID))
+	if err == sql.ErrNoRows {
+		return nil, ErrRunNotFound
+	}
+	return run, err
+}
+
+func (s *InfrastructureService) publishRun(run *models.InfrastructureRun, eventType string) {
+	s.events.Publish(events.Event{
+		Topic:    events.TopicInfraRun,
+		Type:     eventType,
+		TenantID: run.TenantID.String(),
+		Key:      fmt.Sprintf("run/%s/%s", run.RunID, eventType),
+		Data:     run,
+	})
+}
+
+const infrastructureRunColumns = `id, tenant_id, run_id, workspace_id, configuration_version_id, message, config_source, is_destroy, status, has_changes,
+		resource_additions, resource_changes, resource_destructions, confirmable, auto_apply, policy_status, policy_violations,
+		url, requested_by, created_at, updated_at, finished_at`
+
+func scanInfrastructureRun(row rowScanner) (*models.InfrastructureRun, error) {
+	var run models.InfrastructureRun
+	var finishedAt sql.NullTime
+	var violations []byte
+	err := row.Scan(&run.ID, &run.TenantID, &run.RunID, &run.WorkspaceID, &run.ConfigurationVersionID, &run.Message,
+		&run.ConfigSource, &run.IsDestroy, &run.Status, &run.HasChanges, &run.ResourceAdditions, &run.ResourceChanges,
+		&run.ResourceDestructions, &run.Confirmable, &run.AutoApply, &run.PolicyStatus, &violations,
+		&run.URL, &run.RequestedBy, &run.CreatedAt, &run.UpdatedAt, &finishedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan run: %v", err)
+	}
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+	if len(violations) > 0 {
+		if err := json.Unmarshal(violations, &run.PolicyViolations); err != nil {
+			return nil, fmt.Errorf("failed to decode policy violations: %v", err)
+		}
+	}
+	return &run, nil
+}
+
+// checkNotDeleting refuses changes to the infrastructure of a tenant whose
+// teardown is in progress or has failed and is waiting to be retried.
+func checkNotDeleting(tenant *models.Tenant) error {
+	if tenant.Status == "deleting" || tenant.Status == "delete_failed" {
+		return ErrTenantBeingDeleted
+	}
+	return nil
+}
+
+// workspaceName is derived from the namespace, which is already unique and
+// restricted to characters workspace names allow.
+func workspaceName(tenant *models.Tenant) string {
+	return tenant.Namespace
+}
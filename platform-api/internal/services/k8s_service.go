@@ -3,43 +3,73 @@ package services
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"devplatform/platform-api/internal/models"
-	"devplatform/platform-api/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type K8sService struct {
-	client *k8s.Client
+	clusters *ClusterRegistry
+
+	eksMu      sync.Mutex
+	eksDetails map[string]cachedEKSDetails
 }
 
-func NewK8sService(client *k8s.Client) *K8sService {
+func NewK8sService(clusters *ClusterRegistry) *K8sService {
 	return &K8sService{
-		client: client,
+		clusters:   clusters,
+		eksDetails: make(map[string]cachedEKSDetails),
 	}
 }
 
-func (s *K8sService) GetClusterStatus(clusterName string) (*models.ClusterStatus, error) {
-	version, err := s.client.Clientset.Discovery().ServerVersion()
+func (s *K8sService) GetClusterStatus(ctx context.Context, clusterName string) (*models.ClusterStatus, error) {
+	cluster, err := s.clusters.Get(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.clusters.Client(cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := client.Clientset.Discovery().ServerVersion()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get server version: %v", err)
 	}
 
-	nodes, err := s.client.Clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	nodes, err := client.ListNodes(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list nodes: %v", err)
+		return nil, err
 	}
 
 	status := &models.ClusterStatus{
 		Name:       clusterName,
 		Status:     "Ready",
 		Version:    version.String(),
-		Endpoint:   "",
-		NodeCount:  len(nodes.Items),
+		NodeCount:  len(nodes),
 		LastUpdate: metav1.Now().Time,
 	}
+	if client.Config != nil {
+		status.Endpoint = client.Config.Host
+	}
+
+	// EKS details are best-effort: the Kubernetes view is still useful when
+	// the AWS API is unreachable or the caller lacks eks:Describe* access.
+	if api, eksName, ok := s.clusters.EKS(cluster); ok {
+		details, err := s.describeEKS(ctx, cluster.Name, api, eksName)
+		if err != nil {
+			status.EKSError = err.Error()
+		} else {
+			status.EKS = details
+			status.Endpoint = details.Endpoint
+		}
+	}
 
-	for _, node := range nodes.Items {
+	for _, node := range nodes {
 		for _, condition := range node.Status.Conditions {
 			if condition.Type == "Ready" && condition.Status != "True" {
 				status.Status = "NotReady"
@@ -51,84 +81,77 @@ func (s *K8sService) GetClusterStatus(clusterName string) (*models.ClusterStatus
 	return status, nil
 }
 
-func (s *K8sService) GetNodes() ([]models.NodeInfo, error) {
-	nodes, err := s.client.Clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+func (s *K8sService) GetNodes(ctx context.Context, clusterName string) ([]models.NodeInfo, error) {
+	client, err := s.clusters.Client(clusterName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list nodes: %v", err)
+		return nil, err
 	}
 
-	var nodeInfos []models.NodeInfo
-	for _, node := range nodes.Items {
-		nodeInfo := models.NodeInfo{
-			Name:         node.Name,
-			Status:       "Ready",
-			Role:         getNodeRole(node.Labels),
-			Version:      node.Status.NodeInfo.KubeletVersion,
-			InstanceType: node.Labels["node.kubernetes.io/instance-type"],
-			Zone:         node.Labels["topology.kubernetes.io/zone"],
-			Labels:       node.Labels,
-			CreatedAt:    node.CreationTimestamp.Time,
-		}
+	nodes, err := client.ListNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		for _, condition := range node.Status.Conditions {
-			if condition.Type == "Ready" {
-				if condition.Status == "True" {
-					nodeInfo.Status = "Ready"
-				} else {
-					nodeInfo.Status = "NotReady"
-				}
-				break
-			}
-		}
+	usage, source, err := client.NodeUsage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node usage: %v", err)
+	}
+
+	var nodeInfos []models.NodeInfo
+	for _, node := range nodes {
+		info := nodeInfo(node)
 
 		if node.Status.Capacity != nil {
-			nodeInfo.CPU = models.ResourceUsage{
+			used := usage[node.Name]
+			info.CPU = models.ResourceUsage{
 				Capacity:    node.Status.Capacity.Cpu().String(),
 				Allocatable: node.Status.Allocatable.Cpu().String(),
-				Used:        "0",
-				Percentage:  0,
+				Used:        formatCPU(used.Cpu()),
+				Percentage:  usagePercentage(used.Cpu(), node.Status.Allocatable.Cpu()),
+				Source:      source,
 			}
-			nodeInfo.Memory = models.ResourceUsage{
+			info.Memory = models.ResourceUsage{
 				Capacity:    node.Status.Capacity.Memory().String(),
 				Allocatable: node.Status.Allocatable.Memory().String(),
-				Used:        "0",
-				Percentage:  0,
+				Used:        formatMemory(used.Memory()),
+				Percentage:  usagePercentage(used.Memory(), node.Status.Allocatable.Memory()),
+				Source:      source,
 			}
 		}
 
-		for _, taint := range node.Spec.Taints {
-			nodeInfo.Taints = append(nodeInfo.Taints, models.TaintInfo{
-				Key:    taint.Key,
-				Value:  taint.Value,
-				Effect: string(taint.Effect),
-			})
-		}
-
-		nodeInfos = append(nodeInfos, nodeInfo)
+		nodeInfos = append(nodeInfos, info)
 	}
 
 	return nodeInfos, nil
 }
 
-func (s *K8sService) GetNamespaces() ([]models.NamespaceInfo, error) {
-	namespaces, err := s.client.Clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+func (s *K8sService) GetNamespaces(ctx context.Context, clusterName string) ([]models.NamespaceInfo, error) {
+	client, err := s.clusters.Client(clusterName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list namespaces: %v", err)
+		return nil, err
 	}
 
-	var namespaceInfos []models.NamespaceInfo
-	for _, ns := range namespaces.Items {
-		pods, err := s.client.Clientset.CoreV1().Pods(ns.Name).List(context.TODO(), metav1.ListOptions{})
-		podCount := 0
-		if err == nil {
-			podCount = len(pods.Items)
+	namespaces, err := client.ListNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pod counts come from one cluster-wide list rather than a list per
+	// namespace.
+	podCounts := make(map[string]int)
+	if pods, err := client.ListPods(ctx, ""); err == nil {
+		for _, pod := range pods {
+			podCounts[pod.Namespace]++
 		}
+	}
 
+	var namespaceInfos []models.NamespaceInfo
+	for _, ns := range namespaces {
 		namespaceInfo := models.NamespaceInfo{
 			Name:      ns.Name,
 			Status:    string(ns.Status.Phase),
 			Labels:    ns.Labels,
-			PodCount:  podCount,
+			PodCount:  podCounts[ns.Name],
 			CreatedAt: ns.CreationTimestamp.Time,
 		}
 
@@ -138,6 +161,183 @@ func (s *K8sService) GetNamespaces() ([]models.NamespaceInfo, error) {
 	return namespaceInfos, nil
 }
 
+// GetClusterOverview gathers status, nodes, namespaces and pod metrics in
+// parallel. A failing section is reported in Errors and the rest is still
+// returned; an error is only returned when every section failed.
+func (s *K8sService) GetClusterOverview(ctx context.Context, clusterName string) (*models.ClusterOverview, error) {
+	client, err := s.clusters.Client(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	overview := &models.ClusterOverview{
+		Nodes:      []models.NodeInfo{},
+		Namespaces: []models.NamespaceInfo{},
+		Errors:     map[string]string{},
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		pods []*corev1.Pod
+	)
+
+	fail := func(section string, err error) {
+		mu.Lock()
+		overview.Errors[section] = err.Error()
+		mu.Unlock()
+	}
+
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		status, err := s.GetClusterStatus(ctx, clusterName)
+		if err != nil {
+			fail("cluster", err)
+			return
+		}
+		overview.Cluster = *status
+	}()
+	go func() {
+		defer wg.Done()
+		nodes, err := s.GetNodes(ctx, clusterName)
+		if err != nil {
+			fail("nodes", err)
+			return
+		}
+		if nodes != nil {
+			overview.Nodes = nodes
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		namespaces, err := s.GetNamespaces(ctx, clusterName)
+		if err != nil {
+			fail("namespaces", err)
+			return
+		}
+		if namespaces != nil {
+			overview.Namespaces = namespaces
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		list, err := client.ListPods(ctx, "")
+		if err != nil {
+			fail("pods", err)
+			return
+		}
+		pods = list
+	}()
+	wg.Wait()
+
+	if len(overview.Errors) == 4 {
+		return nil, fmt.Errorf("failed to get cluster overview: %s", overview.Errors["cluster"])
+	}
+
+	if overview.Cluster.Name == "" {
+		overview.Cluster.Name = clusterName
+		overview.Cluster.Status = "Unknown"
+	}
+	overview.Metrics = computeClusterMetrics(overview.Nodes, pods)
+	overview.Partial = len(overview.Errors) > 0
+
+	return overview, nil
+}
+
+func computeClusterMetrics(nodes []models.NodeInfo, pods []*corev1.Pod) models.ClusterMetrics {
+	var metrics models.ClusterMetrics
+
+	if pods != nil {
+		metrics.TotalPods = len(pods)
+		for _, pod := range pods {
+			switch pod.Status.Phase {
+			case corev1.PodRunning:
+				metrics.RunningPods++
+			case corev1.PodPending:
+				metrics.PendingPods++
+			case corev1.PodFailed:
+				metrics.FailedPods++
+			}
+		}
+	}
+
+	var totalCPU, totalMemory, usedCPU, usedMemory resource.Quantity
+	for _, node := range nodes {
+		addQuantity(&totalCPU, node.CPU.Allocatable)
+		addQuantity(&totalMemory, node.Memory.Allocatable)
+		addQuantity(&usedCPU, node.CPU.Used)
+		addQuantity(&usedMemory, node.Memory.Used)
+	}
+
+	metrics.TotalCPU = formatCPU(&totalCPU)
+	metrics.TotalMemory = formatMemory(&totalMemory)
+	metrics.UsedCPU = formatCPU(&usedCPU)
+	metrics.UsedMemory = formatMemory(&usedMemory)
+	metrics.CPUPercentage = usagePercentage(&usedCPU, &totalCPU)
+	metrics.MemoryPercentage = usagePercentage(&usedMemory, &totalMemory)
+
+	return metrics
+}
+
+func addQuantity(total *resource.Quantity, value string) {
+	if q, err := resource.ParseQuantity(value); err == nil {
+		total.Add(q)
+	}
+}
+
+func formatCPU(q *resource.Quantity) string {
+	return fmt.Sprintf("%dm", q.MilliValue())
+}
+
+func formatMemory(q *resource.Quantity) string {
+	return fmt.Sprintf("%dMi", q.Value()/(1024*1024))
+}
+
+func usagePercentage(used, allocatable *resource.Quantity) int {
+	if allocatable.IsZero() {
+		return 0
+	}
+	return int(used.MilliValue() * 100 / allocatable.MilliValue())
+}
+
+// nodeInfo describes a node without usage figures, which need a separate
+// metrics lookup.
+func nodeInfo(node *corev1.Node) models.NodeInfo {
+	info := models.NodeInfo{
+		Name:          node.Name,
+		Status:        "Ready",
+		Role:          getNodeRole(node.Labels),
+		Version:       node.Status.NodeInfo.KubeletVersion,
+		InstanceType:  node.Labels["node.kubernetes.io/instance-type"],
+		Zone:          node.Labels["topology.kubernetes.io/zone"],
+		Labels:        node.Labels,
+		Unschedulable: node.Spec.Unschedulable,
+		CreatedAt:     node.CreationTimestamp.Time,
+	}
+
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == "Ready" {
+			if condition.Status == "True" {
+				info.Status = "Ready"
+			} else {
+				info.Status = "NotReady"
+			}
+			break
+		}
+	}
+
+	for _, taint := range node.Spec.Taints {
+		info.Taints = append(info.Taints, models.TaintInfo{
+			Key:    taint.Key,
+			Value:  taint.Value,
+			Effect: string(taint.Effect),
+		})
+	}
+
+	return info
+}
+
 func getNodeRole(labels map[string]string) string {
 	if _, exists := labels["node-role.kubernetes.io/control-plane"]; exists {
 		return "control-plane"
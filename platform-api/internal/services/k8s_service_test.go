@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"devplatform/platform-api/pkg/k8s"
+	"devplatform/platform-api/pkg/k8s/k8stest"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+// testK8sService serves a single cached client under the name "test", so no
+// cluster registration needs to be looked up.
+func testK8sService(client *k8s.Client) *K8sService {
+	return NewK8sService(&ClusterRegistry{clients: map[string]*k8s.Client{"test": client}})
+}
+
+func testNode(name, capacityCPU, allocatableCPU, capacityMemory, allocatableMemory string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(capacityCPU),
+				corev1.ResourceMemory: resource.MustParse(capacityMemory),
+			},
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(allocatableCPU),
+				corev1.ResourceMemory: resource.MustParse(allocatableMemory),
+			},
+		},
+	}
+}
+
+func TestGetNodesUsagePercentages(t *testing.T) {
+	nodes := []runtime.Object{
+		testNode("node-1", "4", "2", "8Gi", "4Gi"),
+		testNode("node-2", "4", "4", "8Gi", "8Gi"),
+	}
+	pods := []runtime.Object{
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "web"},
+			Spec: corev1.PodSpec{
+				NodeName: "node-1",
+				Containers: []corev1.Container{{
+					Name: "app",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("500m"),
+							corev1.ResourceMemory: resource.MustParse("1Gi"),
+						},
+					},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	}
+	nodeMetrics := &metricsv1beta1.NodeMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Usage: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1500m"),
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+	}
+
+	type usage struct {
+		used       string
+		percentage int
+	}
+	tests := []struct {
+		name       string
+		metrics    func(*testing.T) *metricsfake.Clientset
+		wantSource string
+		wantCPU    map[string]usage
+		wantMemory map[string]usage
+	}{
+		{
+			name:       "metrics server",
+			metrics:    func(t *testing.T) *metricsfake.Clientset { return k8stest.FakeMetrics(t, nodeMetrics) },
+			wantSource: k8s.UsageSourceMetricsServer,
+			wantCPU:    map[string]usage{"node-1": {"1500m", 75}, "node-2": {"0m", 0}},
+			wantMemory: map[string]usage{"node-1": {"1024Mi", 25}, "node-2": {"0Mi", 0}},
+		},
+		{
+			name:       "summed pod requests",
+			wantSource: k8s.UsageSourceRequests,
+			wantCPU:    map[string]usage{"node-1": {"500m", 25}, "node-2": {"0m", 0}},
+			wantMemory: map[string]usage{"node-1": {"1024Mi", 25}, "node-2": {"0Mi", 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := k8s.NewClientFromClientsets(k8sfake.NewSimpleClientset(append(nodes, pods...)...), nil)
+			if tt.metrics != nil {
+				client.Metrics = tt.metrics(t)
+			}
+
+			infos, err := testK8sService(client).GetNodes(context.Background(), "test")
+			if err != nil {
+				t.Fatalf("GetNodes: %v", err)
+			}
+			if len(infos) != 2 {
+				t.Fatalf("got %d nodes, want 2", len(infos))
+			}
+			for _, info := range infos {
+				if info.CPU.Source != tt.wantSource || info.Memory.Source != tt.wantSource {
+					t.Errorf("%s: source = %q/%q, want %q", info.Name, info.CPU.Source, info.Memory.Source, tt.wantSource)
+				}
+				if want := tt.wantCPU[info.Name]; info.CPU.Used != want.used || info.CPU.Percentage != want.percentage {
+					t.Errorf("%s: cpu = %s (%d%%), want %s (%d%%)", info.Name, info.CPU.Used, info.CPU.Percentage, want.used, want.percentage)
+				}
+				if want := tt.wantMemory[info.Name]; info.Memory.Used != want.used || info.Memory.Percentage != want.percentage {
+					t.Errorf("%s: memory = %s (%d%%), want %s (%d%%)", info.Name, info.Memory.Used, info.Memory.Percentage, want.used, want.percentage)
+				}
+			}
+		})
+	}
+}
+
+func TestUsagePercentage(t *testing.T) {
+	tests := []struct {
+		used, allocatable string
+		want              int
+	}{
+		{"0", "2", 0},
+		{"500m", "2", 25},
+		{"1", "3", 33},
+		{"3", "2", 150},
+		{"1Gi", "0", 0},
+	}
+
+	for _, tt := range tests {
+		used, allocatable := resource.MustParse(tt.used), resource.MustParse(tt.allocatable)
+		if got := usagePercentage(&used, &allocatable); got != tt.want {
+			t.Errorf("usagePercentage(%s, %s) = %d, want %d", tt.used, tt.allocatable, got, tt.want)
+		}
+	}
+}
+
+func TestGetTenantResourcesPercentages(t *testing.T) {
+	objects := []runtime.Object{
+		testNode("node-1", "4", "2", "8Gi", "4Gi"),
+		testNode("node-2", "4", "2", "8Gi", "4Gi"),
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-a", Name: "web"},
+			Spec: corev1.PodSpec{
+				NodeName: "node-1",
+				Containers: []corev1.Container{{
+					Name: "app",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("1"),
+							corev1.ResourceMemory: resource.MustParse("2Gi"),
+						},
+					},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	}
+	podMetrics := &metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-a", Name: "web"},
+		Containers: []metricsv1beta1.ContainerMetrics{{
+			Name: "app",
+			Usage: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("400m"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		}},
+	}
+
+	tests := []struct {
+		name          string
+		metrics       bool
+		wantSource    string
+		wantCPU       string
+		wantCPUPct    int
+		wantMemory    string
+		wantMemoryPct int
+	}{
+		{
+			name:          "metrics server",
+			metrics:       true,
+			wantSource:    k8s.UsageSourceMetricsServer,
+			wantCPU:       "400m",
+			wantCPUPct:    10,
+			wantMemory:    "4096Mi",
+			wantMemoryPct: 50,
+		},
+		{
+			name:          "summed pod requests",
+			wantSource:    k8s.UsageSourceRequests,
+			wantCPU:       "1000m",
+			wantCPUPct:    25,
+			wantMemory:    "2048Mi",
+			wantMemoryPct: 25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := k8s.NewClientFromClientsets(k8sfake.NewSimpleClientset(objects...), nil)
+			if tt.metrics {
+				client.Metrics = k8stest.FakeMetrics(t, podMetrics)
+			}
+			s := &TenantService{clusters: &ClusterRegistry{clients: map[string]*k8s.Client{"test": client}}}
+
+			got, err := s.getTenantResources(context.Background(), "test", "tenant-a")
+			if err != nil {
+				t.Fatalf("getTenantResources: %v", err)
+			}
+			if got.UsageSource != tt.wantSource {
+				t.Errorf("source = %q, want %q", got.UsageSource, tt.wantSource)
+			}
+			if got.CPUUsage != tt.wantCPU || got.CPUPercentage != tt.wantCPUPct {
+				t.Errorf("cpu = %s (%d%%), want %s (%d%%)", got.CPUUsage, got.CPUPercentage, tt.wantCPU, tt.wantCPUPct)
+			}
+			if got.MemoryUsage != tt.wantMemory || got.MemoryPercentage != tt.wantMemoryPct {
+				t.Errorf("memory = %s (%d%%), want %s (%d%%)", got.MemoryUsage, got.MemoryPercentage, tt.wantMemory, tt.wantMemoryPct)
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"devplatform/platform-api/internal/models"
+	"devplatform/platform-api/pkg/k8s"
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultDrainTimeout = 10 * time.Minute
+	maxDrainTimeout     = time.Hour
+
+	// drainRetryInterval spaces eviction retries for pods protected by a
+	// PodDisruptionBudget and checks for evicted pods being gone.
+	drainRetryInterval = 5 * time.Second
+
+	// drainStaleAfter is how long a running operation may go without a
+	// progress update before it is considered abandoned. runDrain updates
+	// it every drainRetryInterval, so only a drain whose replica stopped
+	// goes this long.
+	drainStaleAfter = 2 * time.Minute
+)
+
+var (
+	ErrNodeNotFound          = errors.New("node not found")
+	ErrNodeOperationNotFound = errors.New("node operation not found")
+	ErrDrainBlocked          = errors.New("node has pods that cannot be drained")
+)
+
+// NodeService performs administrative node operations. Drains run in the
+// background and record their progress in node_operations so any replica
+// can report on them.
+type NodeService struct {
+	db       *sql.DB
+	clusters *ClusterRegistry
+}
+
+func NewNodeService(db *sql.DB, clusters *ClusterRegistry) *NodeService {
+	return &NodeService{
+		db:       db,
+		clusters: clusters,
+	}
+}
+
+func (s *NodeService) GetNode(ctx context.Context, clusterName, name string) (*models.NodeInfo, error) {
+	client, err := s.clusters.Client(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := client.Clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nodeError(err)
+	}
+
+	info := nodeInfo(node)
+	return &info, nil
+}
+
+func (s *NodeService) Cordon(ctx context.Context, clusterName, name string, unschedulable bool) (*models.NodeInfo, error) {
+	client, err := s.clusters.Client(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := client.SetUnschedulable(ctx, name, unschedulable)
+	if err != nil {
+		return nil, nodeError(err)
+	}
+
+	info := nodeInfo(node)
+	return &info, nil
+}
+
+func (s *NodeService) PatchLabels(ctx context.Context, clusterName, name string, labels map[string]*string) (*models.NodeInfo, error) {
+	client, err := s.clusters.Client(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := client.PatchNodeLabels(ctx, name, labels)
+	if err != nil {
+		return nil, nodeError(err)
+	}
+
+	info := nodeInfo(node)
+	return &info, nil
+}
+
+func (s *NodeService) PatchTaints(ctx context.Context, clusterName, name string, patch *models.NodeTaintsPatch) (*models.NodeInfo, error) {
+	client, err := s.clusters.Client(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	var add []corev1.Taint
+	for _, t := range patch.Add {
+		add = append(add, corev1.Taint{Key: t.Key, Value: t.Value, Effect: corev1.TaintEffect(t.Effect)})
+	}
+	remove := func(taint corev1.Taint) bool {
+		for _, sel := range patch.Remove {
+			if sel.Key == taint.Key && (sel.Effect == "" || sel.Effect == string(taint.Effect)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	node, err := client.UpdateNodeTaints(ctx, name, add, remove)
+	if err != nil {
+		return nil, nodeError(err)
+	}
+
+	info := nodeInfo(node)
+	return &info, nil
+}
+
+// Drain cordons the node and starts evicting its pods in the background. It
+// refuses up front, without cordoning, when the node runs pods that would be
+// lost (no controller to recreate them) unless req.Force is set.
+func (s *NodeService) Drain(ctx context.Context, clusterName, name string, req *models.DrainRequest, requestedBy string) (*models.NodeOperation, error) {
+	client, err := s.clusters.Client(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := s.clusters.Get(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := client.Clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{}); err != nil {
+		return nil, nodeError(err)
+	}
+
+	pods, err := client.PodsOnNode(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	evictable, blocked := drainablePods(pods, req.Force)
+	if len(blocked) > 0 {
+		return nil, fmt.Errorf("%w: %s are not managed by a controller; set force to evict them", ErrDrainBlocked, strings.Join(blocked, ", "))
+	}
+
+	if _, err := client.SetUnschedulable(ctx, name, true); err != nil {
+		return nil, nodeError(err)
+	}
+
+	now := time.Now()
+	op := &models.NodeOperation{
+		ID:          uuid.New(),
+		Cluster:     cluster.Name,
+		Node:        name,
+		Operation:   models.NodeOperationDrain,
+		Status:      models.NodeOperationRunning,
+		TotalPods:   len(evictable),
+		RequestedBy: requestedBy,
+		StartedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	query := `
+		INSERT INTO node_operations (id, cluster_name, node_name, operation, status, total_pods, requested_by, started_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err = s.db.Exec(query, op.ID, op.Cluster, op.Node, op.Operation, op.Status, op.TotalPods,
+		op.RequestedBy, op.StartedAt, op.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record node operation: %v", err)
+	}
+
+	timeout := defaultDrainTimeout
+	if req.TimeoutSeconds > 0 {
+		timeout = time.Duration(req.TimeoutSeconds) * time.Second
+		if timeout > maxDrainTimeout {
+			timeout = maxDrainTimeout
+		}
+	}
+
+	go s.runDrain(op.ID, client, evictable, req.GracePeriodSeconds, timeout)
+
+	return op, nil
+}
+
+// runDrain evicts pods until all are gone or the timeout passes. Evictions
+// refused by a PodDisruptionBudget are retried; an evicted pod only counts
+// once it has actually been deleted.
+func (s *NodeService) runDrain(id uuid.UUID, client *k8s.Client, pods []corev1.Pod, gracePeriod *int64, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	remaining := make(map[string]*corev1.Pod, len(pods))
+	for i := range pods {
+		remaining[pods[i].Namespace+"/"+pods[i].Name] = &pods[i]
+	}
+	evicted := make(map[string]bool)
+	lastErrors := make(map[string]string)
+
+	for {
+		for key, pod := range remaining {
+			if evicted[key] {
+				continue
+			}
+			err := client.EvictPod(ctx, pod, gracePeriod)
+			switch {
+			case err == nil || apierrors.IsNotFound(err):
+				evicted[key] = true
+				delete(lastErrors, key)
+			case apierrors.IsTooManyRequests(err):
+				lastErrors[key] = "eviction blocked by PodDisruptionBudget"
+			default:
+				lastErrors[key] = err.Error()
+			}
+		}
+
+		for key, pod := range remaining {
+			if !evicted[key] {
+				continue
+			}
+			current, err := client.Clientset.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) || (err == nil && current.UID != pod.UID) {
+				delete(remaining, key)
+			}
+		}
+
+		done := len(pods) - len(remaining)
+		if len(remaining) == 0 {
+			s.finishOperation(id, models.NodeOperationSucceeded, done, "")
+			return
+		}
+		s.updateProgress(id, done)
+
+		select {
+		case <-ctx.Done():
+			s.finishOperation(id, models.NodeOperationFailed, done, drainFailureMessage(remaining, lastErrors))
+			return
+		case <-time.After(drainRetryInterval):
+		}
+	}
+}
+
+// FailAbandonedOperations marks running operations without recent progress
+// as failed, at startup and then periodically until stop is closed. Drains
+// run in the replica that started them, so one that restarted leaves its
+// operations running forever otherwise. The check is by age rather than by
+// replica, which keeps drains of other replicas running.
+func (s *NodeService) FailAbandonedOperations(stop <-chan struct{}) {
+	ticker := time.NewTicker(drainStaleAfter)
+	defer ticker.Stop()
+
+	for {
+		s.failAbandonedOperations()
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (s *NodeService) failAbandonedOperations() {
+	query := `
+		UPDATE node_operations SET status = $1, message = $2, updated_at = NOW(), finished_at = NOW()
+		WHERE status = $3 AND updated_at < $4
+	`
+	result, err := s.db.Exec(query, models.NodeOperationFailed, "drain was interrupted by a server restart; cordon remains in place",
+		models.NodeOperationRunning, time.Now().Add(-drainStaleAfter))
+	if err != nil {
+		log.Printf("Warning: failed to fail abandoned node operations: %v", err)
+		return
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		log.Printf("Marked %d abandoned node operations as failed", n)
+	}
+}
+
+func (s *NodeService) GetOperation(clusterName, node string, id uuid.UUID) (*models.NodeOperation, error) {
+	query := `
+		SELECT id, cluster_name, node_name, operation, status, total_pods, evicted_pods, message, requested_by, started_at, updated_at, finished_at
+		FROM node_operations WHERE id = $1 AND cluster_name = $2 AND node_name = $3
+	`
+	op, err := scanNodeOperation(s.db.QueryRow(query, id, clusterName, node))
+	if err == sql.ErrNoRows {
+		return nil, ErrNodeOperationNotFound
+	}
+	return op, err
+}
+
+func (s *NodeService) ListOperations(clusterName, node string) ([]models.NodeOperation, error) {
+	query := `
+		SELECT id, cluster_name, node_name, operation, status, total_pods, evicted_pods, message, requested_by, started_at, updated_at, finished_at
+		FROM node_operations WHERE cluster_name = $1 AND node_name = $2
+		ORDER BY started_at DESC LIMIT 50
+	`
+	rows, err := s.db.Query(query, clusterName, node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list node operations: %v", err)
+	}
+	defer rows.Close()
+
+	ops := []models.NodeOperation{}
+	for rows.Next() {
+		op, err := scanNodeOperation(rows)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, *op)
+	}
+
+	return ops, rows.Err()
+}
+
+func (s *NodeService) updateProgress(id uuid.UUID, evicted int) {
+	query := `UPDATE node_operations SET evicted_pods = $1, updated_at = NOW() WHERE id = $2`
+	if _, err := s.db.Exec(query, evicted, id); err != nil {
+		log.Printf("Warning: failed to update node operation %s: %v", id, err)
+	}
+}
+
+func (s *NodeService) finishOperation(id uuid.UUID, status string, evicted int, message string) {
+	query := `
+		UPDATE node_operations SET status = $1, evicted_pods = $2, message = $3, updated_at = NOW(), finished_at = NOW()
+		WHERE id = $4
+	`
+	if _, err := s.db.Exec(query, status, evicted, message, id); err != nil {
+		log.Printf("Warning: failed to finish node operation %s: %v", id, err)
+	}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanNodeOperation(row rowScanner) (*models.NodeOperation, error) {
+	var op models.NodeOperation
+	var finishedAt sql.NullTime
+	err := row.Scan(&op.ID, &op.Cluster, &op.Node, &op.Operation, &op.Status, &op.TotalPods, &op.EvictedPods,
+		&op.Message, &op.RequestedBy, &op.StartedAt, &op.UpdatedAt, &finishedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan node operation: %v", err)
+	}
+	if finishedAt.Valid {
+		op.FinishedAt = &finishedAt.Time
+	}
+	return &op, nil
+}
+
+// drainablePods splits the pods on a node into those to evict and the names
+// of those that block the drain. DaemonSet pods, static mirror pods and
+// finished pods are left alone, as kubectl drain does.
+func drainablePods(pods []corev1.Pod, force bool) (evictable []corev1.Pod, blocked []string) {
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if _, mirror := pod.Annotations[corev1.MirrorPodAnnotationKey]; mirror {
+			continue
+		}
+
+		controller := metav1.GetControllerOf(&pod)
+		if controller != nil && controller.Kind == "DaemonSet" {
+			continue
+		}
+		if controller == nil && !force {
+			blocked = append(blocked, pod.Namespace+"/"+pod.Name)
+			continue
+		}
+
+		evictable = append(evictable, pod)
+	}
+	return evictable, blocked
+}
+
+func drainFailureMessage(remaining map[string]*corev1.Pod, lastErrors map[string]string) string {
+	var parts []string
+	for key := range remaining {
+		if msg, ok := lastErrors[key]; ok {
+			parts = append(parts, fmt.Sprintf("%s (%s)", key, msg))
+		} else {
+			parts = append(parts, key)
+		}
+	}
+	sort.Strings(parts)
+	return fmt.Sprintf("timed out with %d pods remaining: %s", len(remaining), strings.Join(parts, ", "))
+}
+
+func nodeError(err error) error {
+	if apierrors.IsNotFound(err) {
+		return ErrNodeNotFound
+	}
+	return fmt.Errorf("node operation failed: %v", err)
+}
@@ -0,0 +1,522 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"devplatform/platform-api/internal/events"
+	"devplatform/platform-api/internal/models"
+	"devplatform/platform-api/pkg/k8s"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// sleepSchedulerLockID is the Postgres advisory lock that keeps more than
+	// one replica from acting on the same schedule tick.
+	sleepSchedulerLockID = 0x736c656570
+
+	// sleepSavingsWindow is the trailing cost window used to estimate what a
+	// tenant costs per hour while awake.
+	sleepSavingsWindow = 7
+
+	weeksPerMonth = 52.0 / 12
+)
+
+var (
+	ErrSleepScheduleNotFound = errors.New("sleep schedule not found")
+	ErrInvalidSleepSchedule  = errors.New("invalid sleep schedule")
+	ErrTenantNotActive       = errors.New("tenant is not active")
+)
+
+// SleepService scales tenant workloads to zero and back, either on request or
+// on a schedule. Original replica counts live in workload annotations, so a
+// tenant can be woken by any replica of the API, or by hand with kubectl.
+type SleepService struct {
+	db      *sql.DB
+	tenants *TenantService
+	costs   *CostService
+	events  *events.Bus
+}
+
+func NewSleepService(db *sql.DB, tenants *TenantService, costs *CostService, bus *events.Bus) *SleepService {
+	return &SleepService{
+		db:      db,
+		tenants: tenants,
+		costs:   costs,
+		events:  bus,
+	}
+}
+
+func (s *SleepService) ListSchedules() ([]models.SleepSchedule, error) {
+	query := `
+		SELECT id, tenant_id, environment, sleep_cron, wake_cron, timezone, enabled, created_at, updated_at
+		FROM sleep_schedules ORDER BY environment NULLS LAST, created_at
+	`
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sleep schedules: %v", err)
+	}
+	defer rows.Close()
+
+	schedules := []models.SleepSchedule{}
+	for rows.Next() {
+		schedule, err := scanSleepSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+// SaveSchedule creates the schedule for a tenant or environment, replacing
+// any schedule that scope already has.
+func (s *SleepService) SaveSchedule(ctx context.Context, req *models.SleepScheduleRequest) (*models.SleepSchedule, error) {
+	if (req.TenantID == nil) == (req.Environment == "") {
+		return nil, fmt.Errorf("%w: set exactly one of tenant_id and environment", ErrInvalidSleepSchedule)
+	}
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	if _, _, err := parseSleepSchedule(req.SleepCron, req.WakeCron, req.Timezone); err != nil {
+		return nil, err
+	}
+	if req.TenantID != nil {
+		if _, err := s.tenants.lookupTenant(ctx, *req.TenantID); err != nil {
+			return nil, err
+		}
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	var environment sql.NullString
+	conflict := "(tenant_id) WHERE tenant_id IS NOT NULL"
+	if req.Environment != "" {
+		environment = sql.NullString{String: req.Environment, Valid: true}
+		conflict = "(environment) WHERE environment IS NOT NULL"
+	}
+
+	query := `
+		INSERT INTO sleep_schedules (id, tenant_id, environment, sleep_cron, wake_cron, timezone, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		ON CONFLICT ` + conflict + ` DO UPDATE SET
+			sleep_cron = EXCLUDED.sleep_cron, wake_cron = EXCLUDED.wake_cron,
+			timezone = EXCLUDED.timezone, enabled = EXCLUDED.enabled, updated_at = NOW()
+		RETURNING id, tenant_id, environment, sleep_cron, wake_cron, timezone, enabled, created_at, updated_at
+	`
+	schedule, err := scanSleepSchedule(s.db.QueryRowContext(ctx, query, uuid.New(), req.TenantID, environment,
+		req.SleepCron, req.WakeCron, req.Timezone, enabled))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save sleep schedule: %v", err)
+	}
+
+	return schedule, nil
+}
+
+func (s *SleepService) DeleteSchedule(id uuid.UUID) error {
+	result, err := s.db.Exec(`DELETE FROM sleep_schedules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete sleep schedule: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrSleepScheduleNotFound
+	}
+	return nil
+}
+
+// Sleep scales the tenant's Deployments and StatefulSets to zero.
+func (s *SleepService) Sleep(ctx context.Context, id uuid.UUID) (*models.SleepResult, error) {
+	tenant, err := s.tenants.lookupTenant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.sleep(ctx, tenant)
+}
+
+// Wake restores the replica counts recorded when the tenant went to sleep.
+func (s *SleepService) Wake(ctx context.Context, id uuid.UUID) (*models.SleepResult, error) {
+	tenant, err := s.tenants.lookupTenant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.wake(ctx, tenant)
+}
+
+func (s *SleepService) sleep(ctx context.Context, tenant *models.Tenant) (*models.SleepResult, error) {
+	if tenant.Status != "active" {
+		return nil, ErrTenantNotActive
+	}
+
+	client, err := s.tenants.clusters.Client(tenant.ClusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Anything scaled down counts as asleep so that waking, by hand or on
+	// schedule, restores it even if other workloads failed to scale.
+	scaled, scaleErr := client.SleepNamespace(ctx, tenant.Namespace)
+	if tenant.SleepingSince == nil && (scaleErr == nil || len(scaled) > 0) {
+		now := time.Now()
+		if _, err := s.db.ExecContext(ctx, `UPDATE tenants SET sleeping_since = $1, updated_at = $1 WHERE id = $2`, now, tenant.ID); err != nil {
+			return nil, fmt.Errorf("failed to update tenant: %v", err)
+		}
+		tenant.SleepingSince = &now
+		s.publish(tenant, "sleep")
+	}
+	if scaleErr != nil {
+		return nil, fmt.Errorf("failed to sleep tenant: %v", scaleErr)
+	}
+
+	return sleepResult(tenant.ID, "sleep", scaled), nil
+}
+
+func (s *SleepService) wake(ctx context.Context, tenant *models.Tenant) (*models.SleepResult, error) {
+	if tenant.Status != "active" {
+		return nil, ErrTenantNotActive
+	}
+
+	client, err := s.tenants.clusters.Client(tenant.ClusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	// On failure sleeping_since stays set, so the next wake retries the
+	// workloads that still carry the annotation.
+	scaled, err := client.WakeNamespace(ctx, tenant.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wake tenant: %v", err)
+	}
+
+	if tenant.SleepingSince != nil {
+		if _, err := s.db.ExecContext(ctx, `UPDATE tenants SET sleeping_since = NULL, updated_at = NOW() WHERE id = $1`, tenant.ID); err != nil {
+			return nil, fmt.Errorf("failed to update tenant: %v", err)
+		}
+		tenant.SleepingSince = nil
+		s.publish(tenant, "wake")
+	}
+
+	return sleepResult(tenant.ID, "wake", scaled), nil
+}
+
+func (s *SleepService) publish(tenant *models.Tenant, action string) {
+	s.events.Publish(events.Event{
+		Topic:    events.TopicTenantLifecycle,
+		Type:     action,
+		TenantID: tenant.ID.String(),
+		Cluster:  tenant.ClusterName,
+		Data: models.TenantLifecycleEvent{
+			TenantID:  tenant.ID,
+			Name:      tenant.Name,
+			Namespace: tenant.Namespace,
+			Cluster:   tenant.ClusterName,
+			Status:    tenant.Status,
+		},
+	})
+}
+
+// Status reports whether the tenant is asleep, the schedule that applies to
+// it and, when it has one, what sleeping is estimated to save per month.
+func (s *SleepService) Status(ctx context.Context, id uuid.UUID) (*models.SleepStatus, error) {
+	tenant, err := s.tenants.lookupTenant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.SleepStatus{
+		TenantID:      tenant.ID,
+		Environment:   tenant.Environment,
+		Sleeping:      tenant.SleepingSince != nil,
+		SleepingSince: tenant.SleepingSince,
+	}
+
+	schedule, err := s.scheduleFor(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return status, nil
+	}
+	status.Schedule = schedule
+
+	sleepAt, wakeAt, err := parseSleepSchedule(schedule.SleepCron, schedule.WakeCron, schedule.Timezone)
+	if err != nil {
+		return status, nil
+	}
+	loc, _ := time.LoadLocation(schedule.Timezone)
+	now := time.Now().In(loc)
+	if schedule.Enabled {
+		nextSleep, nextWake := sleepAt.Next(now), wakeAt.Next(now)
+		status.NextSleep, status.NextWake = &nextSleep, &nextWake
+	}
+
+	hours := sleepHoursPerWeek(sleepAt, wakeAt, now)
+	end := time.Now().UTC().Truncate(24 * time.Hour)
+	summary, err := s.costs.GetTenantCosts(tenant.ID, &models.CostRequest{
+		StartDate:   end.AddDate(0, 0, -sleepSavingsWindow).Format("2006-01-02"),
+		EndDate:     end.Format("2006-01-02"),
+		Granularity: "DAILY",
+	})
+	if err != nil {
+		status.SavingsError = err.Error()
+		return status, nil
+	}
+
+	hourly := summary.TotalCost / float64(sleepSavingsWindow*24)
+	status.Savings = &models.SleepSavings{
+		HourlyCost:        hourly,
+		MonthlySavings:    hourly * hours * weeksPerMonth,
+		SleepHoursPerWeek: hours,
+		Currency:          summary.Currency,
+		Basis:             fmt.Sprintf("average hourly cost over the last %d days", sleepSavingsWindow),
+	}
+
+	return status, nil
+}
+
+func (s *SleepService) scheduleFor(ctx context.Context, tenant *models.Tenant) (*models.SleepSchedule, error) {
+	query := `
+		SELECT id, tenant_id, environment, sleep_cron, wake_cron, timezone, enabled, created_at, updated_at
+		FROM sleep_schedules WHERE tenant_id = $1 OR environment = $2
+		ORDER BY tenant_id NULLS LAST LIMIT 1
+	`
+	schedule, err := scanSleepSchedule(s.db.QueryRowContext(ctx, query, tenant.ID, tenant.Environment))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return schedule, err
+}
+
+// Run applies schedules every interval until stop is closed. Only times after
+// the scheduler started are acted on, so a restart does not replay a missed
+// sleep in the middle of the working day.
+func (s *SleepService) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case now := <-ticker.C:
+			s.tick(last, now)
+			last = now
+		case <-stop:
+			return
+		}
+	}
+}
+
+// tick sleeps or wakes every tenant whose schedule fired in (from, to]. When
+// both fired, the later of the two wins.
+func (s *SleepService) tick(from, to time.Time) {
+	ctx := context.Background()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Warning: sleep scheduler failed to start: %v", err)
+		return
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, sleepSchedulerLockID).Scan(&locked); err != nil || !locked {
+		return
+	}
+
+	schedules, err := s.ListSchedules()
+	if err != nil {
+		log.Printf("Warning: sleep scheduler failed to load schedules: %v", err)
+		return
+	}
+	byTenant := make(map[uuid.UUID]models.SleepSchedule)
+	byEnvironment := make(map[string]models.SleepSchedule)
+	for _, schedule := range schedules {
+		if schedule.TenantID != nil {
+			byTenant[*schedule.TenantID] = schedule
+		} else {
+			byEnvironment[schedule.Environment] = schedule
+		}
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, namespace, cluster_name, environment, status, sleeping_since
+		FROM tenants WHERE status = 'active'
+	`)
+	if err != nil {
+		log.Printf("Warning: sleep scheduler failed to list tenants: %v", err)
+		return
+	}
+	var tenants []models.Tenant
+	for rows.Next() {
+		var t models.Tenant
+		if err := rows.Scan(&t.ID, &t.Name, &t.Namespace, &t.ClusterName, &t.Environment, &t.Status, &t.SleepingSince); err == nil {
+			tenants = append(tenants, t)
+		}
+	}
+	rows.Close()
+
+	for i := range tenants {
+		tenant := &tenants[i]
+		schedule, ok := byTenant[tenant.ID]
+		if !ok {
+			schedule, ok = byEnvironment[tenant.Environment]
+		}
+		if !ok || !schedule.Enabled {
+			continue
+		}
+
+		action := dueSleepAction(&schedule, from, to)
+		switch {
+		case action == "sleep" && tenant.SleepingSince == nil:
+			_, err = s.sleep(ctx, tenant)
+		case action == "wake" && tenant.SleepingSince != nil:
+			_, err = s.wake(ctx, tenant)
+		default:
+			continue
+		}
+		if err != nil {
+			log.Printf("Warning: scheduled %s failed for tenant %s: %v", action, tenant.Name, err)
+		}
+	}
+}
+
+// dueSleepAction returns "sleep" or "wake" if that side of the schedule fired
+// in (from, to], or "" if neither did.
+func dueSleepAction(schedule *models.SleepSchedule, from, to time.Time) string {
+	sleepAt, wakeAt, err := parseSleepSchedule(schedule.SleepCron, schedule.WakeCron, schedule.Timezone)
+	if err != nil {
+		return ""
+	}
+	loc, _ := time.LoadLocation(schedule.Timezone)
+	from = from.In(loc)
+
+	lastFire := func(s cron.Schedule) time.Time {
+		var fired time.Time
+		for next := s.Next(from); !next.IsZero() && !next.After(to); next = s.Next(next) {
+			fired = next
+		}
+		return fired
+	}
+
+	sleptAt, wokeAt := lastFire(sleepAt), lastFire(wakeAt)
+	switch {
+	case sleptAt.IsZero() && wokeAt.IsZero():
+		return ""
+	case sleptAt.After(wokeAt):
+		return "sleep"
+	default:
+		return "wake"
+	}
+}
+
+// sleepHoursPerWeek simulates two weeks of the schedule from now and measures
+// the second, by which point the state no longer depends on where it started.
+func sleepHoursPerWeek(sleepAt, wakeAt cron.Schedule, now time.Time) float64 {
+	type transition struct {
+		at    time.Time
+		sleep bool
+	}
+
+	end := now.AddDate(0, 0, 14)
+	var transitions []transition
+	for next := sleepAt.Next(now); !next.IsZero() && next.Before(end); next = sleepAt.Next(next) {
+		transitions = append(transitions, transition{next, true})
+	}
+	for next := wakeAt.Next(now); !next.IsZero() && next.Before(end); next = wakeAt.Next(next) {
+		transitions = append(transitions, transition{next, false})
+	}
+	sort.Slice(transitions, func(i, j int) bool { return transitions[i].at.Before(transitions[j].at) })
+
+	weekStart := now.AddDate(0, 0, 7)
+	asleep := false
+	var since time.Time
+	var total time.Duration
+	for _, t := range transitions {
+		if t.at.After(weekStart) && asleep {
+			total += t.at.Sub(maxTime(since, weekStart))
+		}
+		if t.sleep != asleep {
+			asleep = t.sleep
+			since = t.at
+		}
+	}
+	if asleep {
+		total += end.Sub(maxTime(since, weekStart))
+	}
+
+	return total.Hours()
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// parseSleepSchedule parses both sides of a schedule. A spec that never
+// fires, such as the 30th of February, is rejected: cron.Schedule.Next
+// returns the zero time for it.
+func parseSleepSchedule(sleepCron, wakeCron, timezone string) (cron.Schedule, cron.Schedule, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidSleepSchedule, timezone)
+	}
+	now := time.Now().In(loc)
+	sleepAt, err := cron.ParseStandard(sleepCron)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: sleep_cron: %v", ErrInvalidSleepSchedule, err)
+	}
+	if sleepAt.Next(now).IsZero() {
+		return nil, nil, fmt.Errorf("%w: sleep_cron never fires", ErrInvalidSleepSchedule)
+	}
+	wakeAt, err := cron.ParseStandard(wakeCron)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: wake_cron: %v", ErrInvalidSleepSchedule, err)
+	}
+	if wakeAt.Next(now).IsZero() {
+		return nil, nil, fmt.Errorf("%w: wake_cron never fires", ErrInvalidSleepSchedule)
+	}
+	return sleepAt, wakeAt, nil
+}
+
+func scanSleepSchedule(row rowScanner) (*models.SleepSchedule, error) {
+	var schedule models.SleepSchedule
+	var tenantID uuid.NullUUID
+	var environment sql.NullString
+	err := row.Scan(&schedule.ID, &tenantID, &environment, &schedule.SleepCron, &schedule.WakeCron,
+		&schedule.Timezone, &schedule.Enabled, &schedule.CreatedAt, &schedule.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan sleep schedule: %v", err)
+	}
+	if tenantID.Valid {
+		schedule.TenantID = &tenantID.UUID
+	}
+	schedule.Environment = environment.String
+	return &schedule, nil
+}
+
+func sleepResult(tenantID uuid.UUID, action string, scaled []k8s.ScaledWorkload) *models.SleepResult {
+	result := &models.SleepResult{
+		TenantID:  tenantID,
+		Action:    action,
+		Workloads: []models.SleepWorkload{},
+		At:        time.Now(),
+	}
+	for _, w := range scaled {
+		result.Workloads = append(result.Workloads, models.SleepWorkload{Kind: w.Kind, Name: w.Name, Replicas: w.Replicas})
+	}
+	return result
+}
@@ -0,0 +1,610 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"devplatform/platform-api/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// resourceConfigFile is the configuration generated from a tenant's
+// resources. Runs carrying it are the tenant's only configuration: a tenant
+// with resources cannot upload any, and one whose workspace runs uploaded
+// configuration cannot add resources.
+const resourceConfigFile = "main.tf.json"
+
+// maxResourceRuns bounds how often a run is queued again while other
+// requests keep changing the tenant's resources.
+const maxResourceRuns = 5
+
+var (
+	ErrTemplateNotFound   = errors.New("template not found")
+	ErrTemplateExists     = errors.New("template version already exists")
+	ErrInvalidTemplate    = errors.New("invalid template")
+	ErrTemplateNotAllowed = errors.New("template is not available for the tenant's environment")
+	ErrInvalidInputs      = errors.New("invalid template inputs")
+	ErrResourceExists     = errors.New("tenant resource already exists")
+	ErrResourceNotFound   = errors.New("tenant resource not found")
+)
+
+// identifierPattern matches names usable as Terraform module names, inputs
+// and outputs.
+var identifierPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+// reservedInputs are module block arguments inputs cannot override. tags is
+// set from the template and tenant default tags.
+var reservedInputs = map[string]bool{
+	"source": true, "version": true, "count": true, "for_each": true,
+	"providers": true, "depends_on": true, "lifecycle": true, "tags": true,
+}
+
+// TemplateService is the service catalog: versioned Terraform modules with a
+// JSON Schema for their inputs, and the resources tenants provision from
+// them. A tenant's resources are rendered into one configuration and run on
+// its workspace through InfrastructureService. Template modules take a tags
+// input, which receives the tenant's cost allocation tags merged with the
+// template's default tags.
+type TemplateService struct {
+	db    *sql.DB
+	infra *InfrastructureService
+}
+
+func NewTemplateService(db *sql.DB, infra *InfrastructureService) *TemplateService {
+	return &TemplateService{db: db, infra: infra}
+}
+
+// ListTemplates returns every template version, newest first within each
+// name, optionally only those named name.
+func (s *TemplateService) ListTemplates(ctx context.Context, name string) ([]models.Template, error) {
+	query := `SELECT ` + templateColumns + ` FROM templates WHERE ($1 = '' OR name = $1) ORDER BY name, created_at DESC`
+	rows, err := s.db.QueryContext(ctx, query, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %v", err)
+	}
+	defer rows.Close()
+
+	templates := []models.Template{}
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+
+	return templates, rows.Err()
+}
+
+// GetTemplate returns a template version, or its most recently registered
+// version when version is empty.
+func (s *TemplateService) GetTemplate(ctx context.Context, name, version string) (*models.Template, error) {
+	return getTemplate(ctx, s.db, name, version)
+}
+
+func (s *TemplateService) CreateTemplate(ctx context.Context, req *models.CreateTemplateRequest, createdBy string) (*models.Template, error) {
+	if _, err := compileSchema(req.InputSchema); err != nil {
+		return nil, fmt.Errorf("%w: input_schema: %v", ErrInvalidTemplate, err)
+	}
+	for _, output := range req.Outputs {
+		if !identifierPattern.MatchString(output) {
+			return nil, fmt.Errorf("%w: output %q is not a valid name", ErrInvalidTemplate, output)
+		}
+	}
+
+	template := &models.Template{
+		ID:            uuid.New(),
+		Name:          req.Name,
+		Version:       req.Version,
+		Description:   req.Description,
+		ModuleSource:  req.ModuleSource,
+		ModuleVersion: req.ModuleVersion,
+		InputSchema:   req.InputSchema,
+		DefaultTags:   req.DefaultTags,
+		AllowedTiers:  req.AllowedTiers,
+		Outputs:       req.Outputs,
+		CreatedBy:     createdBy,
+		CreatedAt:     time.Now(),
+	}
+	if template.DefaultTags == nil {
+		template.DefaultTags = map[string]string{}
+	}
+	if template.AllowedTiers == nil {
+		template.AllowedTiers = []string{}
+	}
+	if template.Outputs == nil {
+		template.Outputs = []string{}
+	}
+
+	defaultTags, err := json.Marshal(template.DefaultTags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode default tags: %v", err)
+	}
+
+	query := `
+		INSERT INTO templates (id, name, version, description, module_source, module_version, input_schema, default_tags, allowed_tiers, outputs, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err = s.db.ExecContext(ctx, query, template.ID, template.Name, template.Version, template.Description,
+		template.ModuleSource, template.ModuleVersion, []byte(template.InputSchema), defaultTags,
+		pq.Array(template.AllowedTiers), pq.Array(template.Outputs), template.CreatedBy, template.CreatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, ErrTemplateExists
+		}
+		return nil, fmt.Errorf("failed to create template: %v", err)
+	}
+
+	return template, nil
+}
+
+func (s *TemplateService) ListResources(ctx context.Context, tenantID uuid.UUID) ([]models.TenantResource, error) {
+	if _, err := findTenant(ctx, s.db, tenantID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + tenantResourceColumns + ` FROM tenant_resources WHERE tenant_id = $1 ORDER BY name`
+	rows, err := s.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources: %v", err)
+	}
+	defer rows.Close()
+
+	resources := []models.TenantResource{}
+	for rows.Next() {
+		resource, err := scanTenantResource(rows)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, *resource)
+	}
+
+	return resources, rows.Err()
+}
+
+// CreateResource validates the inputs against the template's schema, records
+// the resource and queues a run of the tenant's regenerated configuration.
+func (s *TemplateService) CreateResource(ctx context.Context, tenantID uuid.UUID, req *models.CreateTenantResourceRequest, requestedBy string) (*models.TenantResource, *models.InfrastructureRun, error) {
+	if !s.infra.Enabled() {
+		return nil, nil, ErrInfrastructureDisabled
+	}
+	if !identifierPattern.MatchString(req.Name) {
+		return nil, nil, fmt.Errorf("%w: name must be lowercase letters, digits and underscores, starting with a letter", ErrInvalidInputs)
+	}
+
+	tenant, err := findTenant(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkNotDeleting(tenant); err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkConfigSource(ctx, tenantID); err != nil {
+		return nil, nil, err
+	}
+	template, err := getTemplate(ctx, s.db, req.Template, req.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(template.AllowedTiers) > 0 && !contains(template.AllowedTiers, tenant.Environment) {
+		return nil, nil, ErrTemplateNotAllowed
+	}
+
+	inputs := req.Inputs
+	if len(inputs) == 0 {
+		inputs = json.RawMessage(`{}`)
+	}
+	if err := validateInputs(template.InputSchema, inputs); err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	resource := &models.TenantResource{
+		ID:              uuid.New(),
+		TenantID:        tenantID,
+		Name:            req.Name,
+		TemplateID:      template.ID,
+		TemplateName:    template.Name,
+		TemplateVersion: template.Version,
+		Inputs:          inputs,
+		CreatedBy:       requestedBy,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := s.insertResource(ctx, resource); err != nil {
+		return nil, nil, err
+	}
+
+	message := fmt.Sprintf("Provision %s from %s@%s", resource.Name, template.Name, template.Version)
+	run, err := s.queueResourceRun(ctx, tenant, message, req.AutoApply, requestedBy)
+	if err != nil {
+		if _, deleteErr := s.db.ExecContext(ctx, `DELETE FROM tenant_resources WHERE id = $1`, resource.ID); deleteErr != nil {
+			log.Printf("Warning: failed to remove resource %s of tenant %s after its run failed: %v", resource.Name, tenantID, deleteErr)
+		}
+		return nil, nil, err
+	}
+
+	resource.RunID = run.RunID
+	if _, err := s.db.ExecContext(ctx, `UPDATE tenant_resources SET run_id = $1 WHERE id = $2`, run.RunID, resource.ID); err != nil {
+		return nil, nil, fmt.Errorf("failed to record resource run: %v", err)
+	}
+
+	return resource, run, nil
+}
+
+// DeleteResource removes a resource and queues a run of the tenant's
+// regenerated configuration, which destroys the resource's module.
+func (s *TemplateService) DeleteResource(ctx context.Context, tenantID uuid.UUID, name string, autoApply bool, requestedBy string) (*models.TenantResource, *models.InfrastructureRun, error) {
+	if !s.infra.Enabled() {
+		return nil, nil, ErrInfrastructureDisabled
+	}
+
+	tenant, err := findTenant(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkNotDeleting(tenant); err != nil {
+		return nil, nil, err
+	}
+
+	query := `DELETE FROM tenant_resources WHERE tenant_id = $1 AND name = $2 RETURNING ` + tenantResourceColumns
+	resource, err := scanTenantResource(s.db.QueryRowContext(ctx, query, tenantID, name))
+	if err == sql.ErrNoRows {
+		return nil, nil, ErrResourceNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	message := fmt.Sprintf("Remove %s (%s@%s)", resource.Name, resource.TemplateName, resource.TemplateVersion)
+	run, err := s.queueResourceRun(ctx, tenant, message, autoApply, requestedBy)
+	if err != nil {
+		if restoreErr := s.insertResource(ctx, resource); restoreErr != nil {
+			log.Printf("Warning: failed to restore resource %s of tenant %s after its run failed: %v", resource.Name, tenantID, restoreErr)
+		}
+		return nil, nil, err
+	}
+
+	return resource, run, nil
+}
+
+func (s *TemplateService) insertResource(ctx context.Context, resource *models.TenantResource) error {
+	query := `
+		INSERT INTO tenant_resources (id, tenant_id, name, template_id, template_name, template_version, inputs, run_id, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := s.db.ExecContext(ctx, query, resource.ID, resource.TenantID, resource.Name, resource.TemplateID, resource.TemplateName,
+		resource.TemplateVersion, []byte(resource.Inputs), resource.RunID, resource.CreatedBy, resource.CreatedAt, resource.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return ErrResourceExists
+		}
+		return fmt.Errorf("failed to record resource: %v", err)
+	}
+	return nil
+}
+
+// checkConfigSource refuses resources for a tenant whose workspace runs
+// uploaded configuration, which the generated configuration would replace.
+func (s *TemplateService) checkConfigSource(ctx context.Context, tenantID uuid.UUID) error {
+	var source string
+	query := `
+		SELECT config_source FROM infrastructure_runs
+		WHERE tenant_id = $1 AND config_source <> ''
+		ORDER BY created_at DESC LIMIT 1
+	`
+	err := s.db.QueryRowContext(ctx, query, tenantID).Scan(&source)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check configuration source: %v", err)
+	}
+	if source == configSourceUpload {
+		return fmt.Errorf("%w: the workspace runs uploaded configuration", ErrConfigurationConflict)
+	}
+	return nil
+}
+
+// queueResourceRun queues a run of the configuration rendered from the
+// tenant's recorded resources. Requests for a tenant are not serialised, so
+// when its resources change while the run is being queued the new
+// configuration is queued again; the last run queued on the workspace then
+// includes every resource recorded before it.
+func (s *TemplateService) queueResourceRun(ctx context.Context, tenant *models.Tenant, message string, autoApply bool, requestedBy string) (*models.InfrastructureRun, error) {
+	config, err := renderResources(ctx, s.db, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		run, err := s.infra.startRun(ctx, tenant, &models.CreateInfrastructureRunRequest{
+			Message:   message,
+			Files:     map[string]string{resourceConfigFile: string(config)},
+			AutoApply: autoApply,
+		}, requestedBy, configSourceTemplates)
+		if err != nil {
+			return nil, err
+		}
+
+		latest, err := renderResources(ctx, s.db, tenant.ID)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(latest, config) {
+			return run, nil
+		}
+		if attempt == maxResourceRuns {
+			log.Printf("Warning: resources of tenant %s kept changing; run %s may not include the latest", tenant.ID, run.RunID)
+			return run, nil
+		}
+		config = latest
+	}
+}
+
+// renderResources generates the JSON Terraform configuration of every
+// resource of the tenant.
+func renderResources(ctx context.Context, q querier, tenantID uuid.UUID) ([]byte, error) {
+	query := `
+		SELECT r.name, r.inputs, t.module_source, t.module_version, t.default_tags, t.outputs
+		FROM tenant_resources r JOIN templates t ON t.id = r.template_id
+		WHERE r.tenant_id = $1 ORDER BY r.name
+	`
+	rows, err := q.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load resources: %v", err)
+	}
+	defer rows.Close()
+
+	var modules []resourceModule
+	for rows.Next() {
+		var m resourceModule
+		if err := rows.Scan(&m.Name, &m.Inputs, &m.Source, &m.Version, &m.DefaultTags, pq.Array(&m.Outputs)); err != nil {
+			return nil, fmt.Errorf("failed to scan resource: %v", err)
+		}
+		modules = append(modules, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load resources: %v", err)
+	}
+	return renderModules(modules)
+}
+
+// resourceModule is a tenant resource joined with its template.
+type resourceModule struct {
+	Name        string
+	Inputs      []byte
+	Source      string
+	Version     string
+	DefaultTags []byte
+	Outputs     []string
+}
+
+// renderModules generates the configuration of a tenant's resources: the
+// seeded workspace variables, an AWS provider applying the cost allocation
+// tags, and a module and its exposed outputs per resource. An exposed output
+// is named <resource>_<output>, so names such as db with main_url and
+// db_main with url would collide; that is refused rather than letting one
+// replace the other.
+func renderModules(resources []resourceModule) ([]byte, error) {
+	modules := map[string]interface{}{}
+	locals := map[string]interface{}{}
+	outputs := map[string]interface{}{}
+	outputOwners := map[string]string{}
+	for _, r := range resources {
+		decoder := json.NewDecoder(bytes.NewReader(r.Inputs))
+		decoder.UseNumber()
+		var values map[string]interface{}
+		if err := decoder.Decode(&values); err != nil {
+			return nil, fmt.Errorf("failed to decode inputs of %s: %v", r.Name, err)
+		}
+		var tags map[string]string
+		if err := json.Unmarshal(r.DefaultTags, &tags); err != nil {
+			return nil, fmt.Errorf("failed to decode default tags of %s: %v", r.Name, err)
+		}
+
+		block := map[string]interface{}{"source": r.Source}
+		if r.Version != "" {
+			block["version"] = r.Version
+		}
+		for key, value := range values {
+			block[key] = literal(value)
+		}
+		tagsLocal := r.Name + "_template_tags"
+		locals[tagsLocal] = literal(tags)
+		block["tags"] = fmt.Sprintf("${merge(var.default_tags, local.%s)}", tagsLocal)
+		modules[r.Name] = block
+
+		for _, output := range r.Outputs {
+			key := r.Name + "_" + output
+			if owner, ok := outputOwners[key]; ok {
+				return nil, fmt.Errorf("%w: output %s of %s would have the same name as one of %s", ErrResourceExists, output, r.Name, owner)
+			}
+			outputOwners[key] = r.Name
+			outputs[key] = map[string]interface{}{
+				"value": fmt.Sprintf("${module.%s.%s}", r.Name, output),
+			}
+		}
+	}
+
+	config := map[string]interface{}{
+		"variable": map[string]interface{}{
+			"tenant_id":    map[string]interface{}{"type": "string"},
+			"namespace":    map[string]interface{}{"type": "string"},
+			"cluster_name": map[string]interface{}{"type": "string"},
+			"default_tags": map[string]interface{}{"type": "map(string)", "default": map[string]string{}},
+		},
+		"provider": map[string]interface{}{
+			"aws": map[string]interface{}{
+				"default_tags": map[string]interface{}{"tags": "${var.default_tags}"},
+			},
+		},
+		"module": modules,
+	}
+	if len(locals) > 0 {
+		config["locals"] = locals
+	}
+	if len(outputs) > 0 {
+		config["output"] = outputs
+	}
+
+	return json.MarshalIndent(config, "", "  ")
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// literal escapes template sequences in every string of a value. Strings in
+// JSON configuration are templates, so an input such as "${file(...)}"
+// would otherwise be evaluated.
+func literal(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return strings.NewReplacer("${", "$${", "%{", "%%{").Replace(v)
+	case map[string]string:
+		escaped := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			escaped[key] = literal(item)
+		}
+		return escaped
+	case map[string]interface{}:
+		escaped := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			escaped[key] = literal(item)
+		}
+		return escaped
+	case []interface{}:
+		escaped := make([]interface{}, len(v))
+		for i, item := range v {
+			escaped[i] = literal(item)
+		}
+		return escaped
+	}
+	return value
+}
+
+// inputSchemaURL identifies a template schema while it is compiled. It is
+// absolute so references are not resolved against the working directory.
+const inputSchemaURL = "mem:///input_schema.json"
+
+// compileSchema compiles a JSON Schema. References are resolved only within
+// the schema itself.
+func compileSchema(schema json.RawMessage) (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.LoadURL = func(url string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("external reference %s is not allowed", url)
+	}
+	if err := compiler.AddResource(inputSchemaURL, bytes.NewReader(schema)); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(inputSchemaURL)
+}
+
+// validateInputs checks inputs against the template schema and that they
+// can be passed as module arguments.
+func validateInputs(schema, inputs json.RawMessage) error {
+	compiled, err := compileSchema(schema)
+	if err != nil {
+		return fmt.Errorf("failed to compile template schema: %v", err)
+	}
+
+	// The validator expects numbers decoded as json.Number.
+	decoder := json.NewDecoder(bytes.NewReader(inputs))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidInputs, err)
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: inputs must be an object", ErrInvalidInputs)
+	}
+	for key := range object {
+		if !identifierPattern.MatchString(key) || reservedInputs[key] {
+			return fmt.Errorf("%w: %q cannot be used as an input name", ErrInvalidInputs, key)
+		}
+	}
+
+	if err := compiled.Validate(value); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidInputs, err)
+	}
+	return nil
+}
+
+func getTemplate(ctx context.Context, db *sql.DB, name, version string) (*models.Template, error) {
+	query := `
+		SELECT ` + templateColumns + ` FROM templates
+		WHERE name = $1 AND ($2 = '' OR version = $2)
+		ORDER BY created_at DESC LIMIT 1
+	`
+	template, err := scanTemplate(db.QueryRowContext(ctx, query, name, version))
+	if err == sql.ErrNoRows {
+		return nil, ErrTemplateNotFound
+	}
+	return template, err
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+const templateColumns = `id, name, version, description, module_source, module_version, input_schema, default_tags,
+		allowed_tiers, outputs, created_by, created_at`
+
+func scanTemplate(row rowScanner) (*models.Template, error) {
+	var template models.Template
+	var schema, defaultTags []byte
+	err := row.Scan(&template.ID, &template.Name, &template.Version, &template.Description, &template.ModuleSource,
+		&template.ModuleVersion, &schema, &defaultTags, pq.Array(&template.AllowedTiers), pq.Array(&template.Outputs),
+		&template.CreatedBy, &template.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan template: %v", err)
+	}
+	template.InputSchema = schema
+	if err := json.Unmarshal(defaultTags, &template.DefaultTags); err != nil {
+		return nil, fmt.Errorf("failed to decode default tags: %v", err)
+	}
+	if template.AllowedTiers == nil {
+		template.AllowedTiers = []string{}
+	}
+	if template.Outputs == nil {
+		template.Outputs = []string{}
+	}
+	return &template, nil
+}
+
+const tenantResourceColumns = `id, tenant_id, name, template_id, template_name, template_version, inputs, run_id,
+		created_by, created_at, updated_at`
+
+func scanTenantResource(row rowScanner) (*models.TenantResource, error) {
+	var resource models.TenantResource
+	var inputs []byte
+	err := row.Scan(&resource.ID, &resource.TenantID, &resource.Name, &resource.TemplateID, &resource.TemplateName,
+		&resource.TemplateVersion, &inputs, &resource.RunID, &resource.CreatedBy, &resource.CreatedAt, &resource.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan resource: %v", err)
+	}
+	resource.Inputs = inputs
+	return &resource, nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateInputs(t *testing.T) {
+	schema := json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"size": {"type": "string", "enum": ["small", "large"]},
+			"replicas": {"type": "integer", "minimum": 1},
+			"tags": {"type": "object"},
+			"nested": {"$ref": "#/$defs/nested"}
+		},
+		"$defs": {"nested": {"type": "object", "required": ["name"]}},
+		"required": ["size"]
+	}`)
+
+	tests := []struct {
+		name    string
+		inputs  string
+		wantErr error
+	}{
+		{"valid", `{"size": "small", "replicas": 3}`, nil},
+		{"large integer", `{"size": "small", "replicas": 12345678901234567890}`, nil},
+		{"local reference", `{"size": "small", "nested": {"name": "a"}}`, nil},
+		{"local reference fails", `{"size": "small", "nested": {}}`, ErrInvalidInputs},
+		{"missing required", `{"replicas": 3}`, ErrInvalidInputs},
+		{"wrong enum", `{"size": "huge"}`, ErrInvalidInputs},
+		{"not integer", `{"size": "small", "replicas": 1.5}`, ErrInvalidInputs},
+		{"not an object", `["small"]`, ErrInvalidInputs},
+		{"malformed", `{"size":`, ErrInvalidInputs},
+		{"reserved name", `{"size": "small", "source": "evil"}`, ErrInvalidInputs},
+		{"reserved tags", `{"size": "small", "tags": {}}`, ErrInvalidInputs},
+		{"bad identifier", `{"size": "small", "Bad-Name": 1}`, ErrInvalidInputs},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateInputs(schema, json.RawMessage(tt.inputs))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateInputs = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateInputsRejectsExternalReferences(t *testing.T) {
+	err := validateInputs(json.RawMessage(`{"$ref": "https://example.com/schema.json"}`), json.RawMessage(`{}`))
+	if err == nil || !strings.Contains(err.Error(), "failed to compile template schema") {
+		t.Errorf("validateInputs = %v, want a compile error", err)
+	}
+}
+
+func TestLiteral(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{"plain", "bucket", "bucket"},
+		{"interpolation", "${file(\"/etc/passwd\")}", "$${file(\"/etc/passwd\")}"},
+		{"directive", "%{ if true }x%{ endif }", "%%{ if true }x%%{ endif }"},
+		{"lone markers", "$5 and 50% {x}", "$5 and 50% {x}"},
+		{"number", json.Number("3"), json.Number("3")},
+		{"bool", true, true},
+		{"nil", nil, nil},
+		{
+			"nested",
+			map[string]interface{}{"a": []interface{}{"${x}", map[string]interface{}{"b": "%{y}"}, json.Number("1")}},
+			map[string]interface{}{"a": []interface{}{"$${x}", map[string]interface{}{"b": "%%{y}"}, json.Number("1")}},
+		},
+		{"string map", map[string]string{"Team": "${var.team}"}, map[string]interface{}{"Team": "$${var.team}"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := literal(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("literal(%#v) = %#v, want %#v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderModules(t *testing.T) {
+	config, err := renderModules([]resourceModule{
+		{
+			Name:        "cache",
+			Inputs:      []byte(`{"node_type": "cache.t3.micro", "nodes": 2, "note": "${timestamp()}"}`),
+			Source:      "app.terraform.io/acme/redis/aws",
+			Version:     "1.2.0",
+			DefaultTags: []byte(`{"Service": "redis-${env}"}`),
+			Outputs:     []string{"endpoint"},
+		},
+		{
+			Name:        "db",
+			Inputs:      []byte(`{}`),
+			Source:      "./modules/db",
+			DefaultTags: []byte(`{}`),
+		},
+	})
+	if err != nil {
+		t.Fatalf("renderModules: %v", err)
+	}
+
+	var got map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(string(config)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&got); err != nil {
+		t.Fatalf("rendered configuration is not JSON: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"cache": map[string]interface{}{
+			"source":    "app.terraform.io/acme/redis/aws",
+			"version":   "1.2.0",
+			"node_type": "cache.t3.micro",
+			"nodes":     json.Number("2"),
+			"note":      "$${timestamp()}",
+			"tags":      "${merge(var.default_tags, local.cache_template_tags)}",
+		},
+		"db": map[string]interface{}{
+			"source": "./modules/db",
+			"tags":   "${merge(var.default_tags, local.db_template_tags)}",
+		},
+	}
+	if !reflect.DeepEqual(got["module"], want) {
+		t.Errorf("module = %#v\nwant %#v", got["module"], want)
+	}
+	wantLocals := map[string]interface{}{
+		"cache_template_tags": map[string]interface{}{"Service": "redis-$${env}"},
+		"db_template_tags":    map[string]interface{}{},
+	}
+	if !reflect.DeepEqual(got["locals"], wantLocals) {
+		t.Errorf("locals = %#v", got["locals"])
+	}
+	wantOutputs := map[string]interface{}{
+		"cache_endpoint": map[string]interface{}{"value": "${module.cache.endpoint}"},
+	}
+	if !reflect.DeepEqual(got["output"], wantOutputs) {
+		t.Errorf("output = %#v", got["output"])
+	}
+	if _, ok := got["variable"].(map[string]interface{})["default_tags"]; !ok {
+		t.Errorf("variable = %#v, want default_tags declared", got["variable"])
+	}
+}
+
+func TestRenderModulesRejectsOutputCollisions(t *testing.T) {
+	_, err := renderModules([]resourceModule{
+		{Name: "db", Inputs: []byte(`{}`), DefaultTags: []byte(`{}`), Outputs: []string{"main_url"}},
+		{Name: "db_main", Inputs: []byte(`{}`), DefaultTags: []byte(`{}`), Outputs: []string{"url"}},
+	})
+	if !errors.Is(err, ErrResourceExists) || !strings.Contains(err.Error(), "output url of db_main") {
+		t.Errorf("renderModules = %v, want an output collision", err)
+	}
+}
+
+func TestRenderModulesEmpty(t *testing.T) {
+	config, err := renderModules(nil)
+	if err != nil {
+		t.Fatalf("renderModules: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(config, &got); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["output"]; ok {
+		t.Errorf("empty configuration has outputs: %s", config)
+	}
+	if _, ok := got["locals"]; ok {
+		t.Errorf("empty configuration has locals: %s", config)
+	}
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"devplatform/platform-api/internal/models"
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	ErrPodNotFound  = errors.New("pod not found")
+	ErrInvalidQuery = errors.New("invalid query")
+)
+
+const (
+	defaultEventLimit = 100
+	maxEventLimit     = 1000
+
+	// defaultLogTailLines bounds non-follow log requests so a chatty pod
+	// cannot return its whole log buffer by accident.
+	defaultLogTailLines int64 = 500
+)
+
+// GetTenantEvents returns the events in a tenant's namespace, newest first,
+// along with the number of events matching the query before pagination.
+func (s *TenantService) GetTenantEvents(ctx context.Context, id uuid.UUID, q *models.EventQuery) ([]models.NamespaceEvent, int, error) {
+	tenant, err := s.lookupTenant(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	since, err := parseSince(q.Since)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	client, err := s.clusters.Client(tenant.ClusterName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	list, err := client.Clientset.CoreV1().Events(tenant.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list events: %v", err)
+	}
+
+	events := []models.NamespaceEvent{}
+	for _, e := range list.Items {
+		event := namespaceEvent(e)
+		if !eventMatches(event, q, since) {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].LastSeen.After(events[j].LastSeen)
+	})
+
+	total := len(events)
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultEventLimit
+	}
+	if limit > maxEventLimit {
+		limit = maxEventLimit
+	}
+	if q.Offset >= total {
+		return []models.NamespaceEvent{}, total, nil
+	}
+	end := q.Offset + limit
+	if end > total {
+		end = total
+	}
+
+	return events[q.Offset:end], total, nil
+}
+
+// StreamPodLogs opens a log stream for a pod in the tenant's namespace. The
+// caller must close the returned reader; cancelling ctx ends a follow stream.
+func (s *TenantService) StreamPodLogs(ctx context.Context, id uuid.UUID, pod string, q *models.PodLogQuery) (io.ReadCloser, error) {
+	tenant, err := s.lookupTenant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &corev1.PodLogOptions{
+		Container:  q.Container,
+		Follow:     q.Follow,
+		Previous:   q.Previous,
+		Timestamps: q.Timestamps,
+		TailLines:  q.Tail,
+	}
+	if opts.TailLines == nil && !q.Follow {
+		tail := defaultLogTailLines
+		opts.TailLines = &tail
+	}
+	if q.Since != "" {
+		d, err := time.ParseDuration(q.Since)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%w: since must be a positive duration", ErrInvalidQuery)
+		}
+		seconds := int64(d.Seconds())
+		opts.SinceSeconds = &seconds
+	}
+
+	client, err := s.clusters.Client(tenant.ClusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := client.Clientset.CoreV1().Pods(tenant.Namespace).Get(ctx, pod, metav1.GetOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, ErrPodNotFound
+		}
+		return nil, fmt.Errorf("failed to get pod: %v", err)
+	}
+
+	stream, err := client.Clientset.CoreV1().Pods(tenant.Namespace).GetLogs(pod, opts).Stream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream logs: %v", err)
+	}
+
+	return stream, nil
+}
+
+func namespaceEvent(e corev1.Event) models.NamespaceEvent {
+	first := e.FirstTimestamp.Time
+	if first.IsZero() {
+		first = e.EventTime.Time
+	}
+	last := e.LastTimestamp.Time
+	if last.IsZero() && e.Series != nil {
+		last = e.Series.LastObservedTime.Time
+	}
+	if last.IsZero() {
+		last = first
+	}
+
+	count := e.Count
+	if count == 0 {
+		count = 1
+	}
+
+	return models.NamespaceEvent{
+		Type:    e.Type,
+		Reason:  e.Reason,
+		Message: e.Message,
+		InvolvedObject: models.ObjectReference{
+			Kind: e.InvolvedObject.Kind,
+			Name: e.InvolvedObject.Name,
+		},
+		Count:     count,
+		Source:    firstNonEmpty(e.Source.Component, e.ReportingController),
+		FirstSeen: first,
+		LastSeen:  last,
+	}
+}
+
+func eventMatches(e models.NamespaceEvent, q *models.EventQuery, since time.Time) bool {
+	if q.Type != "" && !strings.EqualFold(e.Type, q.Type) {
+		return false
+	}
+	if q.Reason != "" && e.Reason != q.Reason {
+		return false
+	}
+	if q.Kind != "" && !strings.EqualFold(e.InvolvedObject.Kind, q.Kind) {
+		return false
+	}
+	if q.Name != "" && e.InvolvedObject.Name != q.Name {
+		return false
+	}
+	if !since.IsZero() && e.LastSeen.Before(since) {
+		return false
+	}
+	return true
+}
+
+// parseSince accepts either a duration relative to now ("30m") or an RFC 3339
+// timestamp.
+func parseSince(since string) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(since); err == nil && d > 0 {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%w: since must be a duration or RFC 3339 timestamp", ErrInvalidQuery)
+}
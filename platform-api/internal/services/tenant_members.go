@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"devplatform/platform-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// IsTenantMember reports whether the caller owns the tenant or has been
+// added as a member. Either the user ID or the username may match, since
+// tenant owners are recorded by name.
+func (s *TenantService) IsTenantMember(ctx context.Context, tenantID uuid.UUID, userID, username string) (bool, error) {
+	tenant, err := s.lookupTenant(ctx, tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	if tenant.Owner != "" && (tenant.Owner == userID || tenant.Owner == username) {
+		return true, nil
+	}
+
+	var member bool
+	query := `SELECT EXISTS (SELECT 1 FROM tenant_members WHERE tenant_id = $1 AND user_id IN ($2, $3))`
+	if err := s.db.QueryRowContext(ctx, query, tenantID, userID, username).Scan(&member); err != nil {
+		return false, fmt.Errorf("failed to check tenant membership: %v", err)
+	}
+
+	return member, nil
+}
+
+// IsTenantOwner reports whether the caller owns the tenant, either as its
+// recorded owner or as a member with the owner role.
+func (s *TenantService) IsTenantOwner(ctx context.Context, tenantID uuid.UUID, userID, username string) (bool, error) {
+	tenant, err := s.lookupTenant(ctx, tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	if tenant.Owner != "" && (tenant.Owner == userID || tenant.Owner == username) {
+		return true, nil
+	}
+
+	var owner bool
+	query := `SELECT EXISTS (SELECT 1 FROM tenant_members WHERE tenant_id = $1 AND user_id IN ($2, $3) AND role = 'owner')`
+	if err := s.db.QueryRowContext(ctx, query, tenantID, userID, username).Scan(&owner); err != nil {
+		return false, fmt.Errorf("failed to check tenant ownership: %v", err)
+	}
+
+	return owner, nil
+}
+
+func (s *TenantService) ListMembers(tenantID uuid.UUID) ([]models.TenantMember, error) {
+	if _, err := s.lookupTenant(context.TODO(), tenantID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT tenant_id, user_id, role, created_at
+		FROM tenant_members WHERE tenant_id = $1 ORDER BY created_at
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant members: %v", err)
+	}
+	defer rows.Close()
+
+	members := []models.TenantMember{}
+	for rows.Next() {
+		var m models.TenantMember
+		if err := rows.Scan(&m.TenantID, &m.UserID, &m.Role, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant member: %v", err)
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+func (s *TenantService) AddMember(member *models.TenantMember) error {
+	if _, err := s.lookupTenant(context.TODO(), member.TenantID); err != nil {
+		return err
+	}
+	if member.Role == "" {
+		member.Role = "member"
+	}
+	member.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO tenant_members (tenant_id, user_id, role, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, user_id) DO UPDATE SET role = EXCLUDED.role
+	`
+	if _, err := s.db.Exec(query, member.TenantID, member.UserID, member.Role, member.CreatedAt); err != nil {
+		return fmt.Errorf("failed to add tenant member: %v", err)
+	}
+
+	return nil
+}
+
+func (s *TenantService) RemoveMember(tenantID uuid.UUID, userID string) error {
+	result, err := s.db.Exec(`DELETE FROM tenant_members WHERE tenant_id = $1 AND user_id = $2`, tenantID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove tenant member: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// MemberTenantIDs returns the IDs of every tenant the caller owns or belongs
+// to, keyed by the string form used on event bus events.
+func (s *TenantService) MemberTenantIDs(ctx context.Context, userID, username string) (map[string]bool, error) {
+	query := `
+		SELECT id FROM tenants WHERE owner IN ($1, $2)
+		UNION
+		SELECT tenant_id FROM tenant_members WHERE user_id IN ($1, $2)
+	`
+	rows, err := s.db.QueryContext(ctx, query, userID, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant memberships: %v", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant membership: %v", err)
+		}
+		ids[id.String()] = true
+	}
+
+	return ids, rows.Err()
+}
@@ -1,33 +1,68 @@
 package services
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"log"
 	"strings"
+	"sync"
 	"time"
 
+	"devplatform/platform-api/internal/events"
 	"devplatform/platform-api/internal/models"
-	"devplatform/platform-api/pkg/k8s"
 	"github.com/google/uuid"
 )
 
+// tenantHealthConcurrency bounds the parallel cluster lookups made when
+// listing tenants.
+const tenantHealthConcurrency = 8
+
+// tenantTeardownTimeout bounds a tenant deletion, including waiting for its
+// destroy run.
+const tenantTeardownTimeout = runTrackTimeout + 10*time.Minute
+
+// tenantDeletionLockClass is the first key of the Postgres advisory locks
+// that claim tenant deletions; the second is a hash of the tenant ID.
+const tenantDeletionLockClass = 0x746e74
+
+var ErrTenantDeleting = errors.New("tenant is already being deleted")
+
 type TenantService struct {
-	db        *sql.DB
-	k8sClient *k8s.Client
+	db       *sql.DB
+	clusters *ClusterRegistry
+	events   *events.Bus
+	infra    *InfrastructureService
 }
 
-func NewTenantService(db *sql.DB, k8sClient *k8s.Client) *TenantService {
+func NewTenantService(db *sql.DB, clusters *ClusterRegistry, bus *events.Bus, infra *InfrastructureService) *TenantService {
 	return &TenantService{
-		db:        db,
-		k8sClient: k8sClient,
+		db:       db,
+		clusters: clusters,
+		events:   bus,
+		infra:    infra,
 	}
 }
 
-func (s *TenantService) CreateTenant(req *models.CreateTenantRequest) (*models.TenantResponse, error) {
+func (s *TenantService) CreateTenant(ctx context.Context, req *models.CreateTenantRequest) (*models.TenantResponse, error) {
+	cluster, err := s.clusters.Get(req.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	k8sClient, err := s.clusters.Client(cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+
 	tenant := &models.Tenant{
 		ID:          uuid.New(),
 		Name:        req.Name,
 		Namespace:   generateNamespace(req.Name),
+		ClusterName: cluster.Name,
+		Environment: req.Environment,
 		Description: req.Description,
 		Owner:       req.Owner,
 		Email:       req.Email,
@@ -36,29 +71,41 @@ func (s *TenantService) CreateTenant(req *models.CreateTenantRequest) (*models.T
 		UpdatedAt:   time.Now(),
 	}
 
+	if tenant.Environment == "" {
+		tenant.Environment = "dev"
+	}
+
 	query := `
-		INSERT INTO tenants (id, name, namespace, description, owner, email, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO tenants (id, name, namespace, cluster_name, environment, description, owner, email, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 
-	_, err := s.db.Exec(query, tenant.ID, tenant.Name, tenant.Namespace,
+	_, err = s.db.Exec(query, tenant.ID, tenant.Name, tenant.Namespace, tenant.ClusterName, tenant.Environment,
 		tenant.Description, tenant.Owner, tenant.Email, tenant.Status,
 		tenant.CreatedAt, tenant.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create tenant: %v", err)
 	}
+	s.publishLifecycle(tenant, "")
 
-	if err := s.k8sClient.CreateNamespace(tenant.Namespace); err != nil {
-		s.updateTenantStatus(tenant.ID, "failed")
+	if err := k8sClient.CreateNamespace(tenant.Namespace); err != nil {
+		s.transitionTenant(tenant, "failed")
 		return nil, fmt.Errorf("failed to create namespace: %v", err)
 	}
 
-	s.updateTenantStatus(tenant.ID, "active")
+	if _, err := s.infra.EnsureWorkspace(ctx, tenant); err != nil {
+		s.transitionTenant(tenant, "failed")
+		return nil, err
+	}
+
+	s.transitionTenant(tenant, "active")
 
 	return &models.TenantResponse{
 		ID:          tenant.ID,
 		Name:        tenant.Name,
 		Namespace:   tenant.Namespace,
+		Cluster:     tenant.ClusterName,
+		Environment: tenant.Environment,
 		Description: tenant.Description,
 		Owner:       tenant.Owner,
 		Email:       tenant.Email,
@@ -68,16 +115,51 @@ func (s *TenantService) CreateTenant(req *models.CreateTenantRequest) (*models.T
 	}, nil
 }
 
-func (s *TenantService) GetTenant(id uuid.UUID) (*models.TenantResponse, error) {
+func (s *TenantService) GetTenant(ctx context.Context, id uuid.UUID) (*models.TenantResponse, error) {
+	tenant, err := s.lookupTenant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := s.getTenantResources(ctx, tenant.ClusterName, tenant.Namespace)
+	if err != nil {
+		resources = nil
+	}
+
+	return &models.TenantResponse{
+		ID:            tenant.ID,
+		Name:          tenant.Name,
+		Namespace:     tenant.Namespace,
+		Cluster:       tenant.ClusterName,
+		Environment:   tenant.Environment,
+		Description:   tenant.Description,
+		Owner:         tenant.Owner,
+		Email:         tenant.Email,
+		Status:        tenant.Status,
+		Resources:     resources,
+		SleepingSince: tenant.SleepingSince,
+		DeleteError:   tenant.DeleteError,
+		DeleteRunURL:  tenant.DeleteRunURL,
+		CreatedAt:     tenant.CreatedAt,
+		UpdatedAt:     tenant.UpdatedAt,
+	}, nil
+}
+
+func (s *TenantService) lookupTenant(ctx context.Context, id uuid.UUID) (*models.Tenant, error) {
+	return findTenant(ctx, s.db, id)
+}
+
+func findTenant(ctx context.Context, db *sql.DB, id uuid.UUID) (*models.Tenant, error) {
 	var tenant models.Tenant
 	query := `
-		SELECT id, name, namespace, description, owner, email, status, created_at, updated_at
+		SELECT id, name, namespace, cluster_name, environment, description, owner, email, status, sleeping_since, delete_error, delete_run_url,
+			created_at, updated_at
 		FROM tenants WHERE id = $1
 	`
 
-	err := s.db.QueryRow(query, id).Scan(&tenant.ID, &tenant.Name, &tenant.Namespace,
-		&tenant.Description, &tenant.Owner, &tenant.Email, &tenant.Status,
-		&tenant.CreatedAt, &tenant.UpdatedAt)
+	err := db.QueryRowContext(ctx, query, id).Scan(&tenant.ID, &tenant.Name, &tenant.Namespace, &tenant.ClusterName, &tenant.Environment,
+		&tenant.Description, &tenant.Owner, &tenant.Email, &tenant.Status, &tenant.SleepingSince,
+		&tenant.DeleteError, &tenant.DeleteRunURL, &tenant.CreatedAt, &tenant.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("tenant not found")
@@ -85,79 +167,248 @@ func (s *TenantService) GetTenant(id uuid.UUID) (*models.TenantResponse, error)
 		return nil, fmt.Errorf("failed to get tenant: %v", err)
 	}
 
-	resources, err := s.getTenantResources(tenant.Namespace)
-	if err != nil {
-		resources = nil
-	}
-
-	return &models.TenantResponse{
-		ID:          tenant.ID,
-		Name:        tenant.Name,
-		Namespace:   tenant.Namespace,
-		Description: tenant.Description,
-		Owner:       tenant.Owner,
-		Email:       tenant.Email,
-		Status:      tenant.Status,
-		Resources:   resources,
-		CreatedAt:   tenant.CreatedAt,
-		UpdatedAt:   tenant.UpdatedAt,
-	}, nil
+	return &tenant, nil
 }
 
-func (s *TenantService) ListTenants() ([]models.TenantResponse, error) {
+func (s *TenantService) ListTenants(ctx context.Context) ([]models.TenantResponse, error) {
 	query := `
-		SELECT id, name, namespace, description, owner, email, status, created_at, updated_at
+		SELECT id, name, namespace, cluster_name, environment, description, owner, email, status, sleeping_since, delete_error, delete_run_url,
+			created_at, updated_at
 		FROM tenants ORDER BY created_at DESC
 	`
 
-	rows, err := s.db.Query(query)
+	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tenants: %v", err)
 	}
 	defer rows.Close()
 
-	var tenants []models.TenantResponse
+	var records []models.Tenant
 	for rows.Next() {
 		var tenant models.Tenant
-		err := rows.Scan(&tenant.ID, &tenant.Name, &tenant.Namespace,
-			&tenant.Description, &tenant.Owner, &tenant.Email, &tenant.Status,
-			&tenant.CreatedAt, &tenant.UpdatedAt)
+		err := rows.Scan(&tenant.ID, &tenant.Name, &tenant.Namespace, &tenant.ClusterName, &tenant.Environment,
+			&tenant.Description, &tenant.Owner, &tenant.Email, &tenant.Status, &tenant.SleepingSince,
+			&tenant.DeleteError, &tenant.DeleteRunURL, &tenant.CreatedAt, &tenant.UpdatedAt)
 		if err != nil {
 			continue
 		}
+		records = append(records, tenant)
+	}
+
+	health := make([]string, len(records))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, tenantHealthConcurrency)
+	for i := range records {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			health[i] = s.tenantHealth(ctx, &records[i])
+		}(i)
+	}
+	wg.Wait()
 
+	var tenants []models.TenantResponse
+	for i, tenant := range records {
 		tenants = append(tenants, models.TenantResponse{
-			ID:          tenant.ID,
-			Name:        tenant.Name,
-			Namespace:   tenant.Namespace,
-			Description: tenant.Description,
-			Owner:       tenant.Owner,
-			Email:       tenant.Email,
-			Status:      tenant.Status,
-			CreatedAt:   tenant.CreatedAt,
-			UpdatedAt:   tenant.UpdatedAt,
+			ID:            tenant.ID,
+			Name:          tenant.Name,
+			Namespace:     tenant.Namespace,
+			Cluster:       tenant.ClusterName,
+			Environment:   tenant.Environment,
+			Description:   tenant.Description,
+			Owner:         tenant.Owner,
+			Email:         tenant.Email,
+			Status:        tenant.Status,
+			Health:        health[i],
+			SleepingSince: tenant.SleepingSince,
+			DeleteError:   tenant.DeleteError,
+			DeleteRunURL:  tenant.DeleteRunURL,
+			CreatedAt:     tenant.CreatedAt,
+			UpdatedAt:     tenant.UpdatedAt,
 		})
 	}
 
 	return tenants, nil
 }
 
-func (s *TenantService) DeleteTenant(id uuid.UUID) error {
-	tenant, err := s.GetTenant(id)
+// DeleteTenant removes a tenant. With Terraform configured, its
+// infrastructure is torn down first: the tenant moves to deleting and the
+// rest happens in the background, so DeleteTenant returns false. If the
+// teardown fails the tenant is left in delete_failed, with the error and the
+// destroy run's link, rather than orphaning its resources; deleting it again
+// retries. Without Terraform the tenant is removed before DeleteTenant
+// returns true.
+func (s *TenantService) DeleteTenant(ctx context.Context, id uuid.UUID, requestedBy string) (bool, error) {
+	tenant, err := s.lookupTenant(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	if !s.infra.Enabled() {
+		return true, s.removeTenant(ctx, tenant)
+	}
+
+	query := `
+		UPDATE tenants SET status = 'deleting', delete_error = '', delete_run_url = '', updated_at = $1
+		WHERE id = $2 AND status <> 'deleting'
+	`
+	result, err := s.db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return false, fmt.Errorf("failed to update tenant: %v", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return false, ErrTenantDeleting
+	}
+	previous := tenant.Status
+	tenant.Status = "deleting"
+	tenant.DeleteError, tenant.DeleteRunURL = "", ""
+	s.publishLifecycle(tenant, previous)
+
+	go s.teardownTenant(id, requestedBy)
+
+	return false, nil
+}
+
+// ResumeDeletions restarts the teardown of tenants left in deleting, at once
+// and then every interval until stop is closed. Each teardown is claimed, so
+// one process carries it on however many run this, and another picks it up
+// when that process exits.
+func (s *TenantService) ResumeDeletions(interval time.Duration, stop <-chan struct{}) {
+	if !s.infra.Enabled() {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.resumeDeletions()
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (s *TenantService) resumeDeletions() {
+	rows, err := s.db.Query(`SELECT id FROM tenants WHERE status = 'deleting'`)
+	if err != nil {
+		log.Printf("Warning: failed to resume tenant deletions: %v", err)
+		return
+	}
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		go s.teardownTenant(id, "platform-api")
+	}
+}
+
+// claimDeletion takes the tenant's deletion lock on a connection of its own,
+// which holds it until release is called or, if the process dies, the
+// connection closes. release is nil when another process holds the lock.
+func (s *TenantService) claimDeletion(ctx context.Context, id uuid.UUID) (release func(), err error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %v", err)
+	}
+	var locked bool
+	err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1, hashtext($2))`, tenantDeletionLockClass, id.String()).Scan(&locked)
+	if err != nil || !locked {
+		conn.Close()
+		return nil, err
+	}
+
+	return func() {
+		_, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1, hashtext($2))`, tenantDeletionLockClass, id.String())
+		if err != nil {
+			// Drop the connection rather than return it to the pool still
+			// holding the lock.
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}, nil
+}
+
+// teardownTenant claims the tenant's deletion, destroys its infrastructure
+// and then removes the tenant, or marks it delete_failed.
+func (s *TenantService) teardownTenant(id uuid.UUID, requestedBy string) {
+	ctx, cancel := context.WithTimeout(context.Background(), tenantTeardownTimeout)
+	defer cancel()
+
+	release, err := s.claimDeletion(ctx, id)
+	if err != nil {
+		log.Printf("Warning: failed to claim deletion of tenant %s: %v", id, err)
+		return
+	}
+	if release == nil {
+		return
+	}
+	defer release()
+
+	// The process that held the claim before may have finished.
+	tenant, err := s.lookupTenant(ctx, id)
+	if err != nil {
+		log.Printf("Warning: failed to resume deletion of tenant %s: %v", id, err)
+		return
+	}
+	if tenant.Status != "deleting" {
+		return
+	}
+
+	run, err := s.infra.Teardown(ctx, tenant, requestedBy)
+	if err == nil {
+		err = s.removeTenant(ctx, tenant)
+	}
+	if err == nil {
+		return
+	}
+
+	log.Printf("Warning: failed to delete tenant %s: %v", tenant.Name, err)
+	tenant.DeleteError = err.Error()
+	if run != nil {
+		tenant.DeleteRunURL = run.URL
+	}
+	query := `UPDATE tenants SET status = 'delete_failed', delete_error = $1, delete_run_url = $2, updated_at = $3 WHERE id = $4`
+	if _, err := s.db.Exec(query, tenant.DeleteError, tenant.DeleteRunURL, time.Now(), tenant.ID); err != nil {
+		log.Printf("Warning: failed to mark tenant %s delete_failed: %v", tenant.Name, err)
+		return
+	}
+	previous := tenant.Status
+	tenant.Status = "delete_failed"
+	s.publishLifecycle(tenant, previous)
+}
+
+// removeTenant deletes the tenant's namespace and record.
+func (s *TenantService) removeTenant(ctx context.Context, tenant *models.Tenant) error {
+	k8sClient, err := s.clusters.Client(tenant.ClusterName)
 	if err != nil {
 		return err
 	}
 
-	if err := s.k8sClient.DeleteNamespace(tenant.Namespace); err != nil {
+	if err := k8sClient.DeleteNamespace(tenant.Namespace); err != nil {
 		return fmt.Errorf("failed to delete namespace: %v", err)
 	}
 
 	query := `DELETE FROM tenants WHERE id = $1`
-	_, err = s.db.Exec(query, id)
+	_, err = s.db.ExecContext(ctx, query, tenant.ID)
 	if err != nil {
 		return fmt.Errorf("failed to delete tenant: %v", err)
 	}
 
+	previous := tenant.Status
+	tenant.Status = "deleted"
+	s.publishLifecycle(tenant, previous)
+
 	return nil
 }
 
@@ -167,19 +418,79 @@ func (s *TenantService) updateTenantStatus(id uuid.UUID, status string) error {
 	return err
 }
 
-func (s *TenantService) getTenantResources(namespace string) (*models.TenantResources, error) {
-	pods, err := s.k8sClient.GetPodCount(namespace)
+// transitionTenant records a status change and publishes it on the event bus.
+func (s *TenantService) transitionTenant(tenant *models.Tenant, status string) {
+	if err := s.updateTenantStatus(tenant.ID, status); err != nil {
+		return
+	}
+	previous := tenant.Status
+	tenant.Status = status
+	s.publishLifecycle(tenant, previous)
+}
+
+func (s *TenantService) publishLifecycle(tenant *models.Tenant, previous string) {
+	s.events.Publish(events.Event{
+		Topic:    events.TopicTenantLifecycle,
+		Type:     tenant.Status,
+		TenantID: tenant.ID.String(),
+		Cluster:  tenant.ClusterName,
+		Data: models.TenantLifecycleEvent{
+			TenantID:       tenant.ID,
+			Name:           tenant.Name,
+			Namespace:      tenant.Namespace,
+			Cluster:        tenant.ClusterName,
+			Status:         tenant.Status,
+			PreviousStatus: previous,
+			DeleteError:    tenant.DeleteError,
+			DeleteRunURL:   tenant.DeleteRunURL,
+		},
+	})
+}
+
+// getTenantResources reads usage from the tenant's cluster. Tenants created
+// before clusters were registered have no cluster name and resolve to the
+// default cluster.
+func (s *TenantService) getTenantResources(ctx context.Context, clusterName, namespace string) (*models.TenantResources, error) {
+	k8sClient, err := s.clusters.Client(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := k8sClient.ListPods(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := k8sClient.ListServices(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	deployments, err := k8sClient.ListDeployments(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, source, err := k8sClient.NamespaceUsage(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	allocatable, err := k8sClient.Allocatable(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	return &models.TenantResources{
-		Namespace:   namespace,
-		Pods:        pods,
-		Services:    0,
-		Deployments: 0,
-		CPUUsage:    "0m",
-		MemoryUsage: "0Mi",
+		Namespace:        namespace,
+		Pods:             len(pods),
+		Services:         len(services),
+		Deployments:      len(deployments),
+		CPUUsage:         formatCPU(usage.Cpu()),
+		MemoryUsage:      formatMemory(usage.Memory()),
+		CPUPercentage:    usagePercentage(usage.Cpu(), allocatable.Cpu()),
+		MemoryPercentage: usagePercentage(usage.Memory(), allocatable.Memory()),
+		UsageSource:      source,
 	}, nil
 }
 
@@ -0,0 +1,342 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"devplatform/platform-api/internal/models"
+	"devplatform/platform-api/pkg/k8s"
+	"github.com/google/uuid"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// pendingGracePeriod is how long a pod may sit in Pending before it counts
+// against tenant health.
+const pendingGracePeriod = 5 * time.Minute
+
+var unhealthyWaitingReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+func (s *TenantService) GetTenantWorkloads(ctx context.Context, id uuid.UUID) (*models.TenantWorkloads, error) {
+	tenant, err := s.lookupTenant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.clusters.Client(tenant.ClusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	workloads, err := collectWorkloads(ctx, client, tenant.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	workloads.TenantID = tenant.ID
+	workloads.Cluster = tenant.ClusterName
+
+	return workloads, nil
+}
+
+// tenantHealth rolls up the health of a tenant's namespace, returning
+// HealthUnknown when the cluster cannot be reached.
+func (s *TenantService) tenantHealth(ctx context.Context, tenant *models.Tenant) string {
+	client, err := s.clusters.Client(tenant.ClusterName)
+	if err != nil {
+		return models.HealthUnknown
+	}
+
+	workloads, err := collectWorkloads(ctx, client, tenant.Namespace)
+	if err != nil {
+		return models.HealthUnknown
+	}
+
+	return workloads.Health
+}
+
+func collectWorkloads(ctx context.Context, client *k8s.Client, namespace string) (*models.TenantWorkloads, error) {
+	deployments, err := client.ListDeployments(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	statefulSets, err := client.ListStatefulSets(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	services, err := client.ListServices(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	ingresses, err := client.ListIngresses(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := client.ListPods(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	workloads := &models.TenantWorkloads{
+		Namespace:    namespace,
+		Deployments:  []models.DeploymentInfo{},
+		StatefulSets: []models.StatefulSetInfo{},
+		Services:     []models.ServiceInfo{},
+		Ingresses:    []models.IngressInfo{},
+		Pods:         []models.PodInfo{},
+	}
+
+	for _, d := range deployments {
+		workloads.Deployments = append(workloads.Deployments, deploymentInfo(d))
+	}
+	for _, ss := range statefulSets {
+		workloads.StatefulSets = append(workloads.StatefulSets, statefulSetInfo(ss))
+	}
+	for _, svc := range services {
+		workloads.Services = append(workloads.Services, serviceInfo(svc))
+	}
+	for _, ing := range ingresses {
+		info := models.IngressInfo{
+			Name:      ing.Name,
+			Hosts:     []string{},
+			Addresses: []string{},
+			TLS:       len(ing.Spec.TLS) > 0,
+			CreatedAt: ing.CreationTimestamp.Time,
+		}
+		if ing.Spec.IngressClassName != nil {
+			info.ClassName = *ing.Spec.IngressClassName
+		}
+		for _, rule := range ing.Spec.Rules {
+			if rule.Host != "" {
+				info.Hosts = append(info.Hosts, rule.Host)
+			}
+		}
+		for _, lb := range ing.Status.LoadBalancer.Ingress {
+			info.Addresses = append(info.Addresses, firstNonEmpty(lb.Hostname, lb.IP))
+		}
+		workloads.Ingresses = append(workloads.Ingresses, info)
+	}
+	for _, pod := range pods {
+		workloads.Pods = append(workloads.Pods, podInfo(pod))
+	}
+
+	workloads.Health = rollUpHealth(workloads)
+
+	return workloads, nil
+}
+
+// rollUpHealth reports the worst health among controllers and pods. Pods
+// owned by a Deployment or StatefulSet are already reflected in the
+// controller's replica counts, but a crash-looping pod is still reported.
+func rollUpHealth(w *models.TenantWorkloads) string {
+	if len(w.Deployments) == 0 && len(w.StatefulSets) == 0 && len(w.Pods) == 0 {
+		return models.HealthEmpty
+	}
+
+	health := models.HealthHealthy
+	for _, d := range w.Deployments {
+		health = worseHealth(health, d.Health)
+	}
+	for _, ss := range w.StatefulSets {
+		health = worseHealth(health, ss.Health)
+	}
+	for _, p := range w.Pods {
+		if p.Health == models.HealthUnhealthy {
+			// A single bad pod degrades the tenant; controllers decide
+			// whether it is fully unhealthy.
+			health = worseHealth(health, models.HealthDegraded)
+		}
+	}
+
+	return health
+}
+
+func worseHealth(a, b string) string {
+	rank := map[string]int{
+		models.HealthHealthy:   0,
+		models.HealthEmpty:     0,
+		models.HealthUnknown:   1,
+		models.HealthDegraded:  2,
+		models.HealthUnhealthy: 3,
+	}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+func replicaHealth(desired, ready int32) string {
+	switch {
+	case ready >= desired:
+		return models.HealthHealthy
+	case ready == 0:
+		return models.HealthUnhealthy
+	}
+	return models.HealthDegraded
+}
+
+func deploymentInfo(d *appsv1.Deployment) models.DeploymentInfo {
+	desired := int32(1)
+	if d.Spec.Replicas != nil {
+		desired = *d.Spec.Replicas
+	}
+
+	return models.DeploymentInfo{
+		Name:              d.Name,
+		Replicas:          desired,
+		ReadyReplicas:     d.Status.ReadyReplicas,
+		UpdatedReplicas:   d.Status.UpdatedReplicas,
+		AvailableReplicas: d.Status.AvailableReplicas,
+		Images:            containerImages(d.Spec.Template.Spec.Containers),
+		Health:            replicaHealth(desired, d.Status.ReadyReplicas),
+		CreatedAt:         d.CreationTimestamp.Time,
+	}
+}
+
+func statefulSetInfo(ss *appsv1.StatefulSet) models.StatefulSetInfo {
+	desired := int32(1)
+	if ss.Spec.Replicas != nil {
+		desired = *ss.Spec.Replicas
+	}
+
+	return models.StatefulSetInfo{
+		Name:            ss.Name,
+		Replicas:        desired,
+		ReadyReplicas:   ss.Status.ReadyReplicas,
+		UpdatedReplicas: ss.Status.UpdatedReplicas,
+		Images:          containerImages(ss.Spec.Template.Spec.Containers),
+		Health:          replicaHealth(desired, ss.Status.ReadyReplicas),
+		CreatedAt:       ss.CreationTimestamp.Time,
+	}
+}
+
+func serviceInfo(svc *corev1.Service) models.ServiceInfo {
+	info := models.ServiceInfo{
+		Name:      svc.Name,
+		Type:      string(svc.Spec.Type),
+		ClusterIP: svc.Spec.ClusterIP,
+		Ports:     []models.ServicePortInfo{},
+		CreatedAt: svc.CreationTimestamp.Time,
+	}
+
+	for _, port := range svc.Spec.Ports {
+		info.Ports = append(info.Ports, models.ServicePortInfo{
+			Name:       port.Name,
+			Protocol:   string(port.Protocol),
+			Port:       port.Port,
+			TargetPort: port.TargetPort.String(),
+			NodePort:   port.NodePort,
+		})
+	}
+	for _, lb := range svc.Status.LoadBalancer.Ingress {
+		info.ExternalHost = append(info.ExternalHost, firstNonEmpty(lb.Hostname, lb.IP))
+	}
+
+	return info
+}
+
+func podInfo(pod *corev1.Pod) models.PodInfo {
+	info := models.PodInfo{
+		Name:       pod.Name,
+		Phase:      string(pod.Status.Phase),
+		Node:       pod.Spec.NodeName,
+		Ready:      true,
+		Containers: []models.ContainerStatusInfo{},
+		CreatedAt:  pod.CreationTimestamp.Time,
+	}
+
+	images := make(map[string]string)
+	for _, c := range pod.Spec.Containers {
+		images[c.Name] = c.Image
+	}
+
+	crashing := false
+	for _, cs := range pod.Status.ContainerStatuses {
+		status := models.ContainerStatusInfo{
+			Name:         cs.Name,
+			Image:        images[cs.Name],
+			ImageTag:     imageTag(images[cs.Name]),
+			Ready:        cs.Ready,
+			RestartCount: cs.RestartCount,
+		}
+
+		switch {
+		case cs.State.Running != nil:
+			status.State = "running"
+		case cs.State.Waiting != nil:
+			status.State = "waiting"
+			status.Reason = cs.State.Waiting.Reason
+			crashing = crashing || unhealthyWaitingReasons[cs.State.Waiting.Reason]
+		case cs.State.Terminated != nil:
+			status.State = "terminated"
+			status.Reason = cs.State.Terminated.Reason
+		}
+
+		info.Restarts += cs.RestartCount
+		info.Ready = info.Ready && cs.Ready
+		info.Containers = append(info.Containers, status)
+	}
+	if len(pod.Status.ContainerStatuses) == 0 {
+		info.Ready = false
+	}
+
+	switch {
+	case pod.Status.Phase == corev1.PodSucceeded:
+		info.Health = models.HealthHealthy
+	case pod.Status.Phase == corev1.PodFailed || crashing:
+		info.Health = models.HealthUnhealthy
+	case pod.Status.Phase == corev1.PodPending && time.Since(pod.CreationTimestamp.Time) > pendingGracePeriod:
+		info.Health = models.HealthUnhealthy
+	case pod.Status.Phase == corev1.PodRunning && info.Ready:
+		info.Health = models.HealthHealthy
+	default:
+		info.Health = models.HealthDegraded
+	}
+
+	return info
+}
+
+func containerImages(containers []corev1.Container) []string {
+	images := make([]string, 0, len(containers))
+	for _, c := range containers {
+		images = append(images, c.Image)
+	}
+	return images
+}
+
+// imageTag extracts the tag from an image reference, ignoring any registry
+// port. Images pinned only by digest report the digest, and untagged images
+// default to "latest".
+func imageTag(image string) string {
+	digest := ""
+	if i := strings.Index(image, "@"); i >= 0 {
+		image, digest = image[:i], image[i+1:]
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	if digest != "" {
+		return digest
+	}
+	if image == "" {
+		return ""
+	}
+	return "latest"
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
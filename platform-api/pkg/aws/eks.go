@@ -6,8 +6,17 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
 )
 
+// EKSAPI is the subset of EKS operations the platform uses. EKSClient
+// implements it against AWS; tests can substitute a stub.
+type EKSAPI interface {
+	DescribeCluster(ctx context.Context, clusterName string) (*eks.DescribeClusterOutput, error)
+	DescribeAddons(ctx context.Context, clusterName string) ([]types.Addon, error)
+	DescribeNodegroups(ctx context.Context, clusterName string) ([]types.Nodegroup, error)
+}
+
 type EKSClient struct {
 	client *eks.Client
 }
@@ -18,15 +27,73 @@ func NewEKSClient(cfg aws.Config) *EKSClient {
 	}
 }
 
-func (e *EKSClient) DescribeCluster(clusterName string) (*eks.DescribeClusterOutput, error) {
+func (e *EKSClient) DescribeCluster(ctx context.Context, clusterName string) (*eks.DescribeClusterOutput, error) {
 	input := &eks.DescribeClusterInput{
 		Name: aws.String(clusterName),
 	}
 
-	result, err := e.client.DescribeCluster(context.TODO(), input)
+	result, err := e.client.DescribeCluster(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to describe cluster %s: %v", clusterName, err)
 	}
 
 	return result, nil
 }
+
+func (e *EKSClient) DescribeAddons(ctx context.Context, clusterName string) ([]types.Addon, error) {
+	var addons []types.Addon
+
+	paginator := eks.NewListAddonsPaginator(e.client, &eks.ListAddonsInput{
+		ClusterName: aws.String(clusterName),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list add-ons for cluster %s: %v", clusterName, err)
+		}
+
+		for _, name := range page.Addons {
+			result, err := e.client.DescribeAddon(ctx, &eks.DescribeAddonInput{
+				ClusterName: aws.String(clusterName),
+				AddonName:   aws.String(name),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to describe add-on %s: %v", name, err)
+			}
+			if result.Addon != nil {
+				addons = append(addons, *result.Addon)
+			}
+		}
+	}
+
+	return addons, nil
+}
+
+func (e *EKSClient) DescribeNodegroups(ctx context.Context, clusterName string) ([]types.Nodegroup, error) {
+	var nodegroups []types.Nodegroup
+
+	paginator := eks.NewListNodegroupsPaginator(e.client, &eks.ListNodegroupsInput{
+		ClusterName: aws.String(clusterName),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list node groups for cluster %s: %v", clusterName, err)
+		}
+
+		for _, name := range page.Nodegroups {
+			result, err := e.client.DescribeNodegroup(ctx, &eks.DescribeNodegroupInput{
+				ClusterName:   aws.String(clusterName),
+				NodegroupName: aws.String(name),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to describe node group %s: %v", name, err)
+			}
+			if result.Nodegroup != nil {
+				nodegroups = append(nodegroups, *result.Nodegroup)
+			}
+		}
+	}
+
+	return nodegroups, nil
+}
@@ -0,0 +1,42 @@
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+const (
+	eksTokenPrefix   = "k8s-aws-v1."
+	eksClusterHeader = "x-k8s-aws-id"
+
+	// EKS accepts a presigned request for 15 minutes after signing.
+	eksTokenLifetime = 14 * time.Minute
+)
+
+// EKSToken returns a bearer token for the EKS cluster, built the same way as
+// `aws eks get-token`: a presigned STS GetCallerIdentity URL bound to the
+// cluster name.
+func EKSToken(cfg aws.Config, clusterName string) (string, time.Time, error) {
+	presigner := sts.NewPresignClient(sts.NewFromConfig(cfg))
+
+	req, err := presigner.PresignGetCallerIdentity(context.TODO(), &sts.GetCallerIdentityInput{},
+		func(o *sts.PresignOptions) {
+			o.ClientOptions = append(o.ClientOptions, func(so *sts.Options) {
+				so.APIOptions = append(so.APIOptions,
+					smithyhttp.AddHeaderValue(eksClusterHeader, clusterName),
+				)
+			})
+		})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to presign EKS token for %s: %v", clusterName, err)
+	}
+
+	token := eksTokenPrefix + base64.RawURLEncoding.EncodeToString([]byte(req.URL))
+	return token, time.Now().Add(eksTokenLifetime), nil
+}
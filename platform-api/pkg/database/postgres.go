@@ -64,6 +64,218 @@ func createTables(db *sql.DB) error {
 	);
 	`
 
+	auditLogTable := `
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id BIGSERIAL PRIMARY KEY,
+		timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+		actor VARCHAR(255) NOT NULL,
+		actor_id VARCHAR(255) NOT NULL,
+		action VARCHAR(255) NOT NULL,
+		resource_type VARCHAR(100) NOT NULL,
+		resource_id VARCHAR(255) NOT NULL,
+		request_id VARCHAR(64) NOT NULL,
+		source_ip VARCHAR(64) NOT NULL,
+		before_state JSON,
+		after_state JSON,
+		outcome VARCHAR(20) NOT NULL,
+		status_code INTEGER NOT NULL,
+		prev_hash CHAR(64) NOT NULL,
+		hash CHAR(64) NOT NULL UNIQUE
+	);
+	`
+
+	// Rows in audit_log may only ever be inserted; the hash chain detects
+	// tampering by anyone who bypasses this with superuser access.
+	auditLogImmutable := `
+	CREATE OR REPLACE FUNCTION audit_log_immutable() RETURNS trigger AS $$
+	BEGIN
+		RAISE EXCEPTION 'audit_log is append-only';
+	END;
+	$$ LANGUAGE plpgsql;
+
+	DROP TRIGGER IF EXISTS audit_log_no_modify ON audit_log;
+	CREATE TRIGGER audit_log_no_modify
+		BEFORE UPDATE OR DELETE ON audit_log
+		FOR EACH ROW EXECUTE FUNCTION audit_log_immutable();
+	`
+
+	rateLimitBucketsTable := `
+	CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+		key VARCHAR(255) PRIMARY KEY,
+		tokens DOUBLE PRECISION NOT NULL,
+		updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+	);
+	`
+
+	clustersTable := `
+	CREATE TABLE IF NOT EXISTS clusters (
+		name VARCHAR(255) PRIMARY KEY,
+		source VARCHAR(20) NOT NULL,
+		kubeconfig TEXT NOT NULL DEFAULT '',
+		context VARCHAR(255) NOT NULL DEFAULT '',
+		eks_cluster_name VARCHAR(255) NOT NULL DEFAULT '',
+		region VARCHAR(50) NOT NULL DEFAULT '',
+		is_default BOOLEAN NOT NULL DEFAULT FALSE,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+	`
+
+	tenantsClusterColumn := `ALTER TABLE tenants ADD COLUMN IF NOT EXISTS cluster_name VARCHAR(255) NOT NULL DEFAULT '';`
+
+	tenantMembersTable := `
+	CREATE TABLE IF NOT EXISTS tenant_members (
+		tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+		user_id VARCHAR(255) NOT NULL,
+		role VARCHAR(50) NOT NULL DEFAULT 'member',
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		PRIMARY KEY (tenant_id, user_id)
+	);
+	`
+
+	tenantsEnvironmentColumn := `ALTER TABLE tenants ADD COLUMN IF NOT EXISTS environment VARCHAR(50) NOT NULL DEFAULT 'dev';`
+
+	tenantsSleepingColumn := `ALTER TABLE tenants ADD COLUMN IF NOT EXISTS sleeping_since TIMESTAMP WITH TIME ZONE;`
+
+	tenantsDeletionColumns := `
+	ALTER TABLE tenants
+		ADD COLUMN IF NOT EXISTS delete_error TEXT NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS delete_run_url TEXT NOT NULL DEFAULT '';
+	`
+
+	sleepSchedulesTable := `
+	CREATE TABLE IF NOT EXISTS sleep_schedules (
+		id UUID PRIMARY KEY,
+		tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE,
+		environment VARCHAR(50),
+		sleep_cron VARCHAR(100) NOT NULL,
+		wake_cron VARCHAR(100) NOT NULL,
+		timezone VARCHAR(100) NOT NULL DEFAULT 'UTC',
+		enabled BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		CHECK ((tenant_id IS NULL) <> (environment IS NULL))
+	);
+	`
+
+	infrastructureRunsTable := `
+	CREATE TABLE IF NOT EXISTS infrastructure_runs (
+		id UUID PRIMARY KEY,
+		tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+		run_id VARCHAR(100) NOT NULL UNIQUE,
+		workspace_id VARCHAR(100) NOT NULL,
+		configuration_version_id VARCHAR(100) NOT NULL DEFAULT '',
+		message TEXT NOT NULL DEFAULT '',
+		is_destroy BOOLEAN NOT NULL DEFAULT FALSE,
+		status VARCHAR(50) NOT NULL,
+		has_changes BOOLEAN NOT NULL DEFAULT FALSE,
+		resource_additions INTEGER NOT NULL DEFAULT 0,
+		resource_changes INTEGER NOT NULL DEFAULT 0,
+		resource_destructions INTEGER NOT NULL DEFAULT 0,
+		confirmable BOOLEAN NOT NULL DEFAULT FALSE,
+		url TEXT NOT NULL DEFAULT '',
+		requested_by VARCHAR(255) NOT NULL DEFAULT '',
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		finished_at TIMESTAMP WITH TIME ZONE
+	);
+	`
+
+	infrastructureRunsPolicyColumns := `
+	ALTER TABLE infrastructure_runs
+		ADD COLUMN IF NOT EXISTS auto_apply BOOLEAN NOT NULL DEFAULT FALSE,
+		ADD COLUMN IF NOT EXISTS policy_status VARCHAR(20) NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS policy_violations JSONB;
+	`
+
+	// platformEvents carries events between replicas; its ID sequence
+	// numbers them for all of them. dedup_key is set for changes every
+	// replica observes.
+	platformEventsTable := `
+	CREATE TABLE IF NOT EXISTS platform_events (
+		id BIGSERIAL PRIMARY KEY,
+		dedup_key VARCHAR(512) UNIQUE,
+		topic VARCHAR(50) NOT NULL,
+		type VARCHAR(50) NOT NULL,
+		tenant_id VARCHAR(36) NOT NULL DEFAULT '',
+		cluster VARCHAR(255) NOT NULL DEFAULT '',
+		admin_only BOOLEAN NOT NULL DEFAULT FALSE,
+		data JSONB,
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+	);
+	`
+
+	infrastructureRunsSourceColumn := `
+	ALTER TABLE infrastructure_runs ADD COLUMN IF NOT EXISTS config_source VARCHAR(20) NOT NULL DEFAULT '';
+	`
+
+	driftChecksTable := `
+	CREATE TABLE IF NOT EXISTS drift_checks (
+		id UUID PRIMARY KEY,
+		tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+		run_id VARCHAR(100) NOT NULL DEFAULT '',
+		url TEXT NOT NULL DEFAULT '',
+		status VARCHAR(20) NOT NULL,
+		resources JSONB NOT NULL DEFAULT '[]',
+		error TEXT NOT NULL DEFAULT '',
+		drifted_since TIMESTAMP WITH TIME ZONE,
+		started_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		finished_at TIMESTAMP WITH TIME ZONE
+	);
+	`
+
+	templatesTable := `
+	CREATE TABLE IF NOT EXISTS templates (
+		id UUID PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		version VARCHAR(100) NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		module_source TEXT NOT NULL,
+		module_version VARCHAR(100) NOT NULL DEFAULT '',
+		input_schema JSONB NOT NULL,
+		default_tags JSONB NOT NULL DEFAULT '{}',
+		allowed_tiers TEXT[] NOT NULL DEFAULT '{}',
+		outputs TEXT[] NOT NULL DEFAULT '{}',
+		created_by VARCHAR(255) NOT NULL DEFAULT '',
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		UNIQUE(name, version)
+	);
+	`
+
+	tenantResourcesTable := `
+	CREATE TABLE IF NOT EXISTS tenant_resources (
+		id UUID PRIMARY KEY,
+		tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+		name VARCHAR(63) NOT NULL,
+		template_id UUID NOT NULL REFERENCES templates(id),
+		template_name VARCHAR(255) NOT NULL,
+		template_version VARCHAR(100) NOT NULL,
+		inputs JSONB NOT NULL DEFAULT '{}',
+		run_id VARCHAR(100) NOT NULL DEFAULT '',
+		created_by VARCHAR(255) NOT NULL DEFAULT '',
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		UNIQUE(tenant_id, name)
+	);
+	`
+
+	nodeOperationsTable := `
+	CREATE TABLE IF NOT EXISTS node_operations (
+		id UUID PRIMARY KEY,
+		cluster_name VARCHAR(255) NOT NULL,
+		node_name VARCHAR(255) NOT NULL,
+		operation VARCHAR(50) NOT NULL,
+		status VARCHAR(50) NOT NULL,
+		total_pods INTEGER NOT NULL DEFAULT 0,
+		evicted_pods INTEGER NOT NULL DEFAULT 0,
+		message TEXT NOT NULL DEFAULT '',
+		requested_by VARCHAR(255) NOT NULL DEFAULT '',
+		started_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		finished_at TIMESTAMP WITH TIME ZONE
+	);
+	`
+
 	indexQueries := []string{
 		"CREATE INDEX IF NOT EXISTS idx_tenants_status ON tenants(status);",
 		"CREATE INDEX IF NOT EXISTS idx_tenants_created_at ON tenants(created_at);",
@@ -71,9 +283,24 @@ func createTables(db *sql.DB) error {
 		"CREATE INDEX IF NOT EXISTS idx_cost_data_dates ON cost_data(start_date, end_date);",
 		"CREATE INDEX IF NOT EXISTS idx_platform_metrics_name ON platform_metrics(metric_name);",
 		"CREATE INDEX IF NOT EXISTS idx_platform_metrics_timestamp ON platform_metrics(timestamp);",
+		"CREATE INDEX IF NOT EXISTS idx_audit_log_timestamp ON audit_log(timestamp);",
+		"CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor);",
+		"CREATE INDEX IF NOT EXISTS idx_audit_log_resource ON audit_log(resource_type, resource_id);",
+		"CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated_at ON rate_limit_buckets(updated_at);",
+		"CREATE INDEX IF NOT EXISTS idx_tenants_cluster_name ON tenants(cluster_name);",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_clusters_default ON clusters(is_default) WHERE is_default;",
+		"CREATE INDEX IF NOT EXISTS idx_tenant_members_user_id ON tenant_members(user_id);",
+		"CREATE INDEX IF NOT EXISTS idx_node_operations_node ON node_operations(cluster_name, node_name, started_at);",
+		"CREATE INDEX IF NOT EXISTS idx_tenants_environment ON tenants(environment);",
+		"CREATE INDEX IF NOT EXISTS idx_infrastructure_runs_tenant ON infrastructure_runs(tenant_id, created_at);",
+		"CREATE INDEX IF NOT EXISTS idx_drift_checks_tenant ON drift_checks(tenant_id, started_at);",
+		"CREATE INDEX IF NOT EXISTS idx_platform_events_created_at ON platform_events(created_at);",
+		"CREATE INDEX IF NOT EXISTS idx_templates_name ON templates(name, created_at);",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_sleep_schedules_tenant ON sleep_schedules(tenant_id) WHERE tenant_id IS NOT NULL;",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_sleep_schedules_environment ON sleep_schedules(environment) WHERE environment IS NOT NULL;",
 	}
 
-	tables := []string{tenantsTable, costDataTable, platformMetricsTable}
+	tables := []string{tenantsTable, costDataTable, platformMetricsTable, auditLogTable, auditLogImmutable, rateLimitBucketsTable, clustersTable, tenantsClusterColumn, tenantMembersTable, nodeOperationsTable, tenantsEnvironmentColumn, tenantsSleepingColumn, sleepSchedulesTable, infrastructureRunsTable, infrastructureRunsPolicyColumns, templatesTable, tenantResourcesTable, driftChecksTable, tenantsDeletionColumns, infrastructureRunsSourceColumn, platformEventsTable}
 
 	for _, table := range tables {
 		if _, err := db.Exec(table); err != nil {
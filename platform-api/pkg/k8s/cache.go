@@ -0,0 +1,114 @@
+package k8s
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	networkinglisters "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Cache holds shared informers for the objects the API reads on every
+// request. Listers serve from memory once the initial list has synced and
+// are kept current by a watch.
+type Cache struct {
+	factory informers.SharedInformerFactory
+	synced  []cache.InformerSynced
+
+	nodeInformer  cache.SharedIndexInformer
+	quotaInformer cache.SharedIndexInformer
+
+	Namespaces   corelisters.NamespaceLister
+	Nodes        corelisters.NodeLister
+	Pods         corelisters.PodLister
+	Services     corelisters.ServiceLister
+	Deployments  appslisters.DeploymentLister
+	StatefulSets appslisters.StatefulSetLister
+	Ingresses    networkinglisters.IngressLister
+	Quotas       corelisters.ResourceQuotaLister
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewCache(clientset kubernetes.Interface, resync time.Duration) *Cache {
+	factory := informers.NewSharedInformerFactory(clientset, resync)
+
+	namespaces := factory.Core().V1().Namespaces()
+	nodes := factory.Core().V1().Nodes()
+	pods := factory.Core().V1().Pods()
+	services := factory.Core().V1().Services()
+	deployments := factory.Apps().V1().Deployments()
+	statefulSets := factory.Apps().V1().StatefulSets()
+	ingresses := factory.Networking().V1().Ingresses()
+	quotas := factory.Core().V1().ResourceQuotas()
+
+	return &Cache{
+		factory: factory,
+		synced: []cache.InformerSynced{
+			namespaces.Informer().HasSynced,
+			nodes.Informer().HasSynced,
+			pods.Informer().HasSynced,
+			services.Informer().HasSynced,
+			deployments.Informer().HasSynced,
+			statefulSets.Informer().HasSynced,
+			ingresses.Informer().HasSynced,
+			quotas.Informer().HasSynced,
+		},
+		nodeInformer:  nodes.Informer(),
+		quotaInformer: quotas.Informer(),
+		Namespaces:    namespaces.Lister(),
+		Nodes:         nodes.Lister(),
+		Pods:          pods.Lister(),
+		Services:      services.Lister(),
+		Deployments:   deployments.Lister(),
+		StatefulSets:  statefulSets.Lister(),
+		Ingresses:     ingresses.Lister(),
+		Quotas:        quotas.Lister(),
+		stop:          make(chan struct{}),
+	}
+}
+
+// Start runs the informers in the background until Stop is called.
+func (c *Cache) Start() {
+	c.factory.Start(c.stop)
+}
+
+func (c *Cache) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+		c.factory.Shutdown()
+	})
+}
+
+// OnNodeChange registers a handler for node add, update and delete
+// notifications. Handlers added after Start still see the existing nodes.
+func (c *Cache) OnNodeChange(handler cache.ResourceEventHandler) error {
+	_, err := c.nodeInformer.AddEventHandler(handler)
+	return err
+}
+
+func (c *Cache) OnResourceQuotaChange(handler cache.ResourceEventHandler) error {
+	_, err := c.quotaInformer.AddEventHandler(handler)
+	return err
+}
+
+// HasSynced reports whether every informer has completed its initial list.
+func (c *Cache) HasSynced() bool {
+	for _, synced := range c.synced {
+		if !synced() {
+			return false
+		}
+	}
+	return true
+}
+
+// WaitForSync blocks until the cache has synced or ctx is done.
+func (c *Cache) WaitForSync(ctx context.Context) bool {
+	return cache.WaitForCacheSync(ctx.Done(), c.synced...)
+}
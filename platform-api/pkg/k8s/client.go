@@ -6,16 +6,29 @@ import (
 	"path/filepath"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 type Client struct {
-	Clientset *kubernetes.Clientset
+	Clientset kubernetes.Interface
+	Metrics   metricsclientset.Interface
 	Config    *rest.Config
+	Cache     *Cache
+}
+
+// NewClientFromClientsets wraps existing clientsets, such as the fake ones
+// from client-go and k8s.io/metrics in tests.
+func NewClientFromClientsets(clientset kubernetes.Interface, metrics metricsclientset.Interface) *Client {
+	return &Client{
+		Clientset: clientset,
+		Metrics:   metrics,
+	}
 }
 
 func NewClient(kubeconfig string) (*Client, error) {
@@ -40,13 +53,42 @@ func NewClient(kubeconfig string) (*Client, error) {
 		}
 	}
 
+	return NewClientFromRESTConfig(config)
+}
+
+// NewClientForContext loads a kubeconfig file and selects the named context.
+// An empty path uses the standard loading rules ($KUBECONFIG, ~/.kube/config).
+func NewClientForContext(kubeconfig, context string) (*Client, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		rules.ExplicitPath = kubeconfig
+	}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		rules,
+		&clientcmd.ConfigOverrides{CurrentContext: context},
+	).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig context %q: %v", context, err)
+	}
+
+	return NewClientFromRESTConfig(config)
+}
+
+func NewClientFromRESTConfig(config *rest.Config) (*Client, error) {
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create kubernetes client: %v", err)
 	}
 
+	metrics, err := metricsclientset.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics client: %v", err)
+	}
+
 	return &Client{
 		Clientset: clientset,
+		Metrics:   metrics,
 		Config:    config,
 	}, nil
 }
@@ -70,9 +112,11 @@ func (c *Client) CreateNamespace(name string) error {
 	return nil
 }
 
+// DeleteNamespace deletes a namespace. One that is already gone counts as
+// deleted, so a retried tenant deletion can finish.
 func (c *Client) DeleteNamespace(name string) error {
 	err := c.Clientset.CoreV1().Namespaces().Delete(context.TODO(), name, metav1.DeleteOptions{})
-	if err != nil {
+	if err != nil && !apierrors.IsNotFound(err) {
 		return fmt.Errorf("failed to delete namespace %s: %v", name, err)
 	}
 
@@ -80,12 +124,12 @@ func (c *Client) DeleteNamespace(name string) error {
 }
 
 func (c *Client) GetPodCount(namespace string) (int, error) {
-	pods, err := c.Clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	pods, err := c.ListPods(context.TODO(), namespace)
 	if err != nil {
 		return 0, fmt.Errorf("failed to list pods in namespace %s: %v", namespace, err)
 	}
 
-	return len(pods.Items), nil
+	return len(pods), nil
 }
 
 func (c *Client) NamespaceExists(name string) (bool, error) {
@@ -0,0 +1,25 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDeleteNamespace(t *testing.T) {
+	clientset := k8sfake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"}})
+	c := &Client{Clientset: clientset}
+
+	if err := c.DeleteNamespace("tenant-a"); err != nil {
+		t.Fatalf("DeleteNamespace: %v", err)
+	}
+	if _, err := clientset.CoreV1().Namespaces().Get(context.Background(), "tenant-a", metav1.GetOptions{}); err == nil {
+		t.Error("namespace still exists")
+	}
+	if err := c.DeleteNamespace("tenant-a"); err != nil {
+		t.Errorf("DeleteNamespace of a missing namespace = %v, want nil", err)
+	}
+}
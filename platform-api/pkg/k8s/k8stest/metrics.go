@@ -0,0 +1,34 @@
+// Package k8stest provides fakes for testing code built on pkg/k8s.
+package k8stest
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+// FakeMetrics returns a fake metrics clientset serving the given node and
+// pod metrics. They are registered under the resources the fake lists
+// ("nodes" and "pods"); its object tracker would otherwise file them under
+// the kind's plural and List would return nothing.
+func FakeMetrics(t *testing.T, objects ...runtime.Object) *metricsfake.Clientset {
+	t.Helper()
+	metrics := metricsfake.NewSimpleClientset()
+	for _, obj := range objects {
+		var err error
+		switch m := obj.(type) {
+		case *metricsv1beta1.NodeMetrics:
+			err = metrics.Tracker().Create(metricsv1beta1.SchemeGroupVersion.WithResource("nodes"), m, "")
+		case *metricsv1beta1.PodMetrics:
+			err = metrics.Tracker().Create(metricsv1beta1.SchemeGroupVersion.WithResource("pods"), m, m.Namespace)
+		default:
+			t.Fatalf("unsupported metrics object %T", obj)
+		}
+		if err != nil {
+			t.Fatalf("failed to add metrics: %v", err)
+		}
+	}
+	return metrics
+}
@@ -0,0 +1,156 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// The List helpers read from the informer cache once it has synced and fall
+// back to the API server before that, or when the client has no cache. An
+// empty namespace lists across all namespaces. Objects returned from the
+// cache are shared and must not be modified.
+
+// CacheSynced reports whether reads are being served from the cache.
+func (c *Client) CacheSynced() bool {
+	return c.Cache != nil && c.Cache.HasSynced()
+}
+
+// StartCache creates and starts the informer cache for this client.
+func (c *Client) StartCache(resync time.Duration) {
+	c.Cache = NewCache(c.Clientset, resync)
+	c.Cache.Start()
+}
+
+func (c *Client) ListNamespaces(ctx context.Context) ([]*corev1.Namespace, error) {
+	if c.CacheSynced() {
+		return c.Cache.Namespaces.List(labels.Everything())
+	}
+
+	list, err := c.Clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %v", err)
+	}
+	items := make([]*corev1.Namespace, len(list.Items))
+	for i := range list.Items {
+		items[i] = &list.Items[i]
+	}
+	return items, nil
+}
+
+func (c *Client) ListNodes(ctx context.Context) ([]*corev1.Node, error) {
+	if c.CacheSynced() {
+		return c.Cache.Nodes.List(labels.Everything())
+	}
+
+	list, err := c.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %v", err)
+	}
+	items := make([]*corev1.Node, len(list.Items))
+	for i := range list.Items {
+		items[i] = &list.Items[i]
+	}
+	return items, nil
+}
+
+func (c *Client) ListPods(ctx context.Context, namespace string) ([]*corev1.Pod, error) {
+	if c.CacheSynced() {
+		if namespace == "" {
+			return c.Cache.Pods.List(labels.Everything())
+		}
+		return c.Cache.Pods.Pods(namespace).List(labels.Everything())
+	}
+
+	list, err := c.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %v", err)
+	}
+	items := make([]*corev1.Pod, len(list.Items))
+	for i := range list.Items {
+		items[i] = &list.Items[i]
+	}
+	return items, nil
+}
+
+func (c *Client) ListServices(ctx context.Context, namespace string) ([]*corev1.Service, error) {
+	if c.CacheSynced() {
+		if namespace == "" {
+			return c.Cache.Services.List(labels.Everything())
+		}
+		return c.Cache.Services.Services(namespace).List(labels.Everything())
+	}
+
+	list, err := c.Clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %v", err)
+	}
+	items := make([]*corev1.Service, len(list.Items))
+	for i := range list.Items {
+		items[i] = &list.Items[i]
+	}
+	return items, nil
+}
+
+func (c *Client) ListDeployments(ctx context.Context, namespace string) ([]*appsv1.Deployment, error) {
+	if c.CacheSynced() {
+		if namespace == "" {
+			return c.Cache.Deployments.List(labels.Everything())
+		}
+		return c.Cache.Deployments.Deployments(namespace).List(labels.Everything())
+	}
+
+	list, err := c.Clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %v", err)
+	}
+	items := make([]*appsv1.Deployment, len(list.Items))
+	for i := range list.Items {
+		items[i] = &list.Items[i]
+	}
+	return items, nil
+}
+
+func (c *Client) ListStatefulSets(ctx context.Context, namespace string) ([]*appsv1.StatefulSet, error) {
+	if c.CacheSynced() {
+		if namespace == "" {
+			return c.Cache.StatefulSets.List(labels.Everything())
+		}
+		return c.Cache.StatefulSets.StatefulSets(namespace).List(labels.Everything())
+	}
+
+	list, err := c.Clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %v", err)
+	}
+	items := make([]*appsv1.StatefulSet, len(list.Items))
+	for i := range list.Items {
+		items[i] = &list.Items[i]
+	}
+	return items, nil
+}
+
+func (c *Client) ListIngresses(ctx context.Context, namespace string) ([]*networkingv1.Ingress, error) {
+	if c.CacheSynced() {
+		if namespace == "" {
+			return c.Cache.Ingresses.List(labels.Everything())
+		}
+		return c.Cache.Ingresses.Ingresses(namespace).List(labels.Everything())
+	}
+
+	list, err := c.Clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %v", err)
+	}
+	items := make([]*networkingv1.Ingress, len(list.Items))
+	for i := range list.Items {
+		items[i] = &list.Items[i]
+	}
+	return items, nil
+}
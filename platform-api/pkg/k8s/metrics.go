@@ -0,0 +1,127 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	UsageSourceMetricsServer = "metrics-server"
+	UsageSourceRequests      = "requests"
+)
+
+// NodeUsage returns CPU and memory usage per node name. It reads
+// metrics.k8s.io and falls back to summed pod requests when metrics-server
+// is not installed or not answering; the second return value names the
+// source that was used.
+func (c *Client) NodeUsage(ctx context.Context) (map[string]corev1.ResourceList, string, error) {
+	if c.Metrics != nil {
+		nodeMetrics, err := c.Metrics.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+		if err == nil {
+			usage := make(map[string]corev1.ResourceList, len(nodeMetrics.Items))
+			for _, m := range nodeMetrics.Items {
+				usage[m.Name] = m.Usage
+			}
+			return usage, UsageSourceMetricsServer, nil
+		}
+	}
+
+	pods, err := c.ListPods(ctx, "")
+	if err != nil {
+		return nil, "", err
+	}
+
+	usage := make(map[string]corev1.ResourceList)
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || !podActive(pod) {
+			continue
+		}
+		if _, ok := usage[pod.Spec.NodeName]; !ok {
+			usage[pod.Spec.NodeName] = corev1.ResourceList{}
+		}
+		addResources(usage[pod.Spec.NodeName], podRequests(pod))
+	}
+
+	return usage, UsageSourceRequests, nil
+}
+
+// NamespaceUsage returns the summed CPU and memory usage of all pods in a
+// namespace, with the same metrics-server fallback as NodeUsage.
+func (c *Client) NamespaceUsage(ctx context.Context, namespace string) (corev1.ResourceList, string, error) {
+	usage := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.Quantity{},
+		corev1.ResourceMemory: resource.Quantity{},
+	}
+
+	if c.Metrics != nil {
+		podMetrics, err := c.Metrics.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{})
+		if err == nil {
+			for _, m := range podMetrics.Items {
+				for _, container := range m.Containers {
+					addResources(usage, container.Usage)
+				}
+			}
+			return usage, UsageSourceMetricsServer, nil
+		}
+	}
+
+	pods, err := c.ListPods(ctx, namespace)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list pods in namespace %s: %v", namespace, err)
+	}
+
+	for _, pod := range pods {
+		if podActive(pod) {
+			addResources(usage, podRequests(pod))
+		}
+	}
+
+	return usage, UsageSourceRequests, nil
+}
+
+// Allocatable returns the CPU and memory allocatable across all nodes of the
+// cluster, which namespace usage is measured against.
+func (c *Client) Allocatable(ctx context.Context) (corev1.ResourceList, error) {
+	nodes, err := c.ListNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	total := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.Quantity{},
+		corev1.ResourceMemory: resource.Quantity{},
+	}
+	for _, node := range nodes {
+		addResources(total, node.Status.Allocatable)
+	}
+	return total, nil
+}
+
+// podActive skips pods that no longer hold resources on a node.
+func podActive(pod *corev1.Pod) bool {
+	return pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed
+}
+
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		addResources(requests, container.Resources.Requests)
+	}
+	return requests
+}
+
+func addResources(total, add corev1.ResourceList) {
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		q, ok := add[name]
+		if !ok {
+			continue
+		}
+		sum := total[name]
+		sum.Add(q)
+		total[name] = sum
+	}
+}
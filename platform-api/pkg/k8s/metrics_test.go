@@ -0,0 +1,186 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"devplatform/platform-api/pkg/k8s/k8stest"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+func testPod(namespace, name, node string, phase corev1.PodPhase, cpu, memory string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: corev1.PodSpec{
+			NodeName: node,
+			Containers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse(cpu),
+						corev1.ResourceMemory: resource.MustParse(memory),
+					},
+				},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func testPods() []runtime.Object {
+	return []runtime.Object{
+		testPod("team-a", "web-1", "node-1", corev1.PodRunning, "250m", "256Mi"),
+		testPod("team-a", "web-2", "node-1", corev1.PodRunning, "250m", "256Mi"),
+		testPod("team-a", "job-1", "node-1", corev1.PodSucceeded, "1", "1Gi"),
+		testPod("team-b", "api-1", "node-2", corev1.PodRunning, "500m", "1Gi"),
+		testPod("team-b", "api-2", "node-2", corev1.PodFailed, "2", "2Gi"),
+		testPod("team-b", "pending", "", corev1.PodPending, "100m", "64Mi"),
+	}
+}
+
+func unavailableMetrics() *metricsfake.Clientset {
+	metrics := metricsfake.NewSimpleClientset()
+	metrics.PrependReactor("list", "*", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("the server could not find the requested resource")
+	})
+	return metrics
+}
+
+func assertQuantity(t *testing.T, what string, got resource.Quantity, want string) {
+	t.Helper()
+	if got.Cmp(resource.MustParse(want)) != 0 {
+		t.Errorf("%s = %s, want %s", what, got.String(), want)
+	}
+}
+
+func TestNodeUsageFromMetricsServer(t *testing.T) {
+	metrics := k8stest.FakeMetrics(t, &metricsv1beta1.NodeMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Usage: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1200m"),
+			corev1.ResourceMemory: resource.MustParse("3Gi"),
+		},
+	})
+	client := NewClientFromClientsets(k8sfake.NewSimpleClientset(testPods()...), metrics)
+
+	usage, source, err := client.NodeUsage(context.Background())
+	if err != nil {
+		t.Fatalf("NodeUsage: %v", err)
+	}
+	if source != UsageSourceMetricsServer {
+		t.Errorf("source = %q, want %q", source, UsageSourceMetricsServer)
+	}
+	if len(usage) != 1 {
+		t.Fatalf("got usage for %d nodes, want 1", len(usage))
+	}
+	node := usage["node-1"]
+	assertQuantity(t, "node-1 cpu", *node.Cpu(), "1200m")
+	assertQuantity(t, "node-1 memory", *node.Memory(), "3Gi")
+}
+
+func TestNodeUsageFallsBackToRequests(t *testing.T) {
+	tests := []struct {
+		name    string
+		metrics *metricsfake.Clientset
+	}{
+		{name: "no metrics client"},
+		{name: "metrics API unavailable", metrics: unavailableMetrics()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClientFromClientsets(k8sfake.NewSimpleClientset(testPods()...), nil)
+			if tt.metrics != nil {
+				client.Metrics = tt.metrics
+			}
+
+			usage, source, err := client.NodeUsage(context.Background())
+			if err != nil {
+				t.Fatalf("NodeUsage: %v", err)
+			}
+			if source != UsageSourceRequests {
+				t.Errorf("source = %q, want %q", source, UsageSourceRequests)
+			}
+			if len(usage) != 2 {
+				t.Fatalf("got usage for %d nodes, want 2", len(usage))
+			}
+			node1, node2 := usage["node-1"], usage["node-2"]
+			assertQuantity(t, "node-1 cpu", *node1.Cpu(), "500m")
+			assertQuantity(t, "node-1 memory", *node1.Memory(), "512Mi")
+			assertQuantity(t, "node-2 cpu", *node2.Cpu(), "500m")
+			assertQuantity(t, "node-2 memory", *node2.Memory(), "1Gi")
+		})
+	}
+}
+
+func TestNamespaceUsage(t *testing.T) {
+	podMetrics := &metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "web-1"},
+		Containers: []metricsv1beta1.ContainerMetrics{
+			{Name: "app", Usage: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("100Mi"),
+			}},
+			{Name: "sidecar", Usage: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("20m"),
+				corev1.ResourceMemory: resource.MustParse("28Mi"),
+			}},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		metrics    *metricsfake.Clientset
+		wantSource string
+		wantCPU    string
+		wantMemory string
+	}{
+		{
+			name:       "metrics server",
+			metrics:    k8stest.FakeMetrics(t, podMetrics),
+			wantSource: UsageSourceMetricsServer,
+			wantCPU:    "120m",
+			wantMemory: "128Mi",
+		},
+		{
+			name:       "no metrics client",
+			wantSource: UsageSourceRequests,
+			wantCPU:    "500m",
+			wantMemory: "512Mi",
+		},
+		{
+			name:       "metrics API unavailable",
+			metrics:    unavailableMetrics(),
+			wantSource: UsageSourceRequests,
+			wantCPU:    "500m",
+			wantMemory: "512Mi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClientFromClientsets(k8sfake.NewSimpleClientset(testPods()...), nil)
+			if tt.metrics != nil {
+				client.Metrics = tt.metrics
+			}
+
+			usage, source, err := client.NamespaceUsage(context.Background(), "team-a")
+			if err != nil {
+				t.Fatalf("NamespaceUsage: %v", err)
+			}
+			if source != tt.wantSource {
+				t.Errorf("source = %q, want %q", source, tt.wantSource)
+			}
+			assertQuantity(t, "cpu", *usage.Cpu(), tt.wantCPU)
+			assertQuantity(t, "memory", *usage.Memory(), tt.wantMemory)
+		})
+	}
+}
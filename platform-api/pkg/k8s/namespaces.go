@@ -5,19 +5,19 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func (c *Client) ListNamespaces() (*corev1.NamespaceList, error) {
-	namespaces, err := c.Clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list namespaces: %v", err)
+func (c *Client) GetNamespace(name string) (*corev1.Namespace, error) {
+	if c.CacheSynced() {
+		namespace, err := c.Cache.Namespaces.Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get namespace %s: %v", name, err)
+		}
+		return namespace, nil
 	}
 
-	return namespaces, nil
-}
-
-func (c *Client) GetNamespace(name string) (*corev1.Namespace, error) {
 	namespace, err := c.Clientset.CoreV1().Namespaces().Get(context.TODO(), name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get namespace %s: %v", name, err)
@@ -25,3 +25,36 @@ func (c *Client) GetNamespace(name string) (*corev1.Namespace, error) {
 
 	return namespace, nil
 }
+
+// ApplyConfigMap creates the ConfigMap or replaces the data and labels of an
+// existing one.
+func (c *Client) ApplyConfigMap(ctx context.Context, namespace, name string, labels, data map[string]string) error {
+	configMaps := c.Clientset.CoreV1().ConfigMaps(namespace)
+
+	existing, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = configMaps.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+			Data:       data,
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to create configmap %s: %v", name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get configmap %s: %v", name, err)
+	}
+
+	if existing.Labels == nil {
+		existing.Labels = map[string]string{}
+	}
+	for k, v := range labels {
+		existing.Labels[k] = v
+	}
+	existing.Data = data
+	if _, err := configMaps.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update configmap %s: %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+// SetUnschedulable cordons or uncordons a node.
+func (c *Client) SetUnschedulable(ctx context.Context, name string, unschedulable bool) (*corev1.Node, error) {
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"unschedulable": unschedulable},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Clientset.CoreV1().Nodes().Patch(ctx, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+}
+
+// PatchNodeLabels sets node labels; a nil value removes the label.
+func (c *Client) PatchNodeLabels(ctx context.Context, name string, labels map[string]*string) (*corev1.Node, error) {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": labels},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Clientset.CoreV1().Nodes().Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+}
+
+// UpdateNodeTaints applies remove and then add to the node's taints. The
+// taint list has no merge key, so this is a read-modify-write that retries
+// on conflict rather than a patch.
+func (c *Client) UpdateNodeTaints(ctx context.Context, name string, add []corev1.Taint, remove func(corev1.Taint) bool) (*corev1.Node, error) {
+	var updated *corev1.Node
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := c.Clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		var taints []corev1.Taint
+		for _, taint := range node.Spec.Taints {
+			if remove != nil && remove(taint) {
+				continue
+			}
+			replaced := false
+			for _, t := range add {
+				if t.Key == taint.Key && t.Effect == taint.Effect {
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				taints = append(taints, taint)
+			}
+		}
+		node.Spec.Taints = append(taints, add...)
+
+		updated, err = c.Clientset.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+		return err
+	})
+	return updated, err
+}
+
+// PodsOnNode lists the pods scheduled to a node straight from the API server,
+// since a drain must not act on a stale cache.
+func (c *Client) PodsOnNode(ctx context.Context, name string) ([]corev1.Pod, error) {
+	pods, err := c.Clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", name).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods on node %s: %v", name, err)
+	}
+	return pods.Items, nil
+}
+
+// EvictPod asks the API server to evict a pod. The eviction is refused with
+// 429 Too Many Requests while it would violate a PodDisruptionBudget.
+func (c *Client) EvictPod(ctx context.Context, pod *corev1.Pod, gracePeriodSeconds *int64) error {
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+		DeleteOptions: &metav1.DeleteOptions{
+			GracePeriodSeconds: gracePeriodSeconds,
+			Preconditions:      &metav1.Preconditions{UID: &pod.UID},
+		},
+	}
+	return c.Clientset.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+}
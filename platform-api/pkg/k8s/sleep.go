@@ -0,0 +1,148 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// SleepReplicasAnnotation records the replica count a workload had before it
+// was scaled to zero, so waking restores it exactly.
+const SleepReplicasAnnotation = "platform-api/sleep-original-replicas"
+
+// ScaledWorkload is a Deployment or StatefulSet changed by SleepNamespace or
+// WakeNamespace, with the replica count it was scaled to.
+type ScaledWorkload struct {
+	Kind     string
+	Name     string
+	Replicas int32
+}
+
+type scalable struct {
+	kind        string
+	name        string
+	replicas    int32
+	annotations map[string]string
+}
+
+// SleepNamespace scales every Deployment and StatefulSet in the namespace to
+// zero. Workloads that already carry the annotation are skipped, so sleeping
+// twice never overwrites the original counts with zero.
+func (c *Client) SleepNamespace(ctx context.Context, namespace string) ([]ScaledWorkload, error) {
+	workloads, err := c.scalableWorkloads(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var scaled []ScaledWorkload
+	var errs []error
+	for _, w := range workloads {
+		if _, asleep := w.annotations[SleepReplicasAnnotation]; asleep || w.replicas == 0 {
+			continue
+		}
+		patch := map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{SleepReplicasAnnotation: strconv.Itoa(int(w.replicas))},
+			},
+			"spec": map[string]interface{}{"replicas": 0},
+		}
+		if err := c.patchWorkload(ctx, namespace, w.kind, w.name, patch); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		scaled = append(scaled, ScaledWorkload{Kind: w.kind, Name: w.name, Replicas: 0})
+	}
+
+	return scaled, errors.Join(errs...)
+}
+
+// WakeNamespace restores the replica counts recorded by SleepNamespace and
+// removes the annotation. Workloads without it are left alone.
+func (c *Client) WakeNamespace(ctx context.Context, namespace string) ([]ScaledWorkload, error) {
+	workloads, err := c.scalableWorkloads(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var scaled []ScaledWorkload
+	var errs []error
+	for _, w := range workloads {
+		value, asleep := w.annotations[SleepReplicasAnnotation]
+		if !asleep {
+			continue
+		}
+		replicas, err := strconv.Atoi(value)
+		if err != nil || replicas < 0 {
+			errs = append(errs, fmt.Errorf("%s %s has an invalid %s annotation %q", w.kind, w.name, SleepReplicasAnnotation, value))
+			continue
+		}
+		patch := map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{SleepReplicasAnnotation: nil},
+			},
+			"spec": map[string]interface{}{"replicas": replicas},
+		}
+		if err := c.patchWorkload(ctx, namespace, w.kind, w.name, patch); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		scaled = append(scaled, ScaledWorkload{Kind: w.kind, Name: w.name, Replicas: int32(replicas)})
+	}
+
+	return scaled, errors.Join(errs...)
+}
+
+// scalableWorkloads reads straight from the API server: acting on a stale
+// cache could record a replica count that has already changed.
+func (c *Client) scalableWorkloads(ctx context.Context, namespace string) ([]scalable, error) {
+	deployments, err := c.Clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %v", err)
+	}
+	statefulSets, err := c.Clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %v", err)
+	}
+
+	var workloads []scalable
+	for _, d := range deployments.Items {
+		workloads = append(workloads, scalable{"Deployment", d.Name, replicaCount(d.Spec.Replicas), d.Annotations})
+	}
+	for _, s := range statefulSets.Items {
+		workloads = append(workloads, scalable{"StatefulSet", s.Name, replicaCount(s.Spec.Replicas), s.Annotations})
+	}
+	return workloads, nil
+}
+
+func (c *Client) patchWorkload(ctx context.Context, namespace, kind, name string, patch map[string]interface{}) error {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case "Deployment":
+		_, err = c.Clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{})
+	case "StatefulSet":
+		_, err = c.Clientset.AppsV1().StatefulSets(namespace).Patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{})
+	default:
+		return fmt.Errorf("cannot scale %s", kind)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to scale %s %s: %v", kind, name, err)
+	}
+	return nil
+}
+
+// replicaCount applies the API default of one replica when unset.
+func replicaCount(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
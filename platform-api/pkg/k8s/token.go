@@ -0,0 +1,71 @@
+package k8s
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// TokenSource returns a bearer token and the time it stops being valid.
+type TokenSource func() (string, time.Time, error)
+
+// RESTConfigWithToken builds a rest.Config for a cluster that authenticates
+// with short-lived bearer tokens, such as EKS IAM tokens. Tokens are cached
+// and refreshed a minute before they expire.
+func RESTConfigWithToken(host string, caData []byte, source TokenSource) *rest.Config {
+	cached := &cachedToken{source: source}
+
+	return &rest.Config{
+		Host: host,
+		TLSClientConfig: rest.TLSClientConfig{
+			CAData: caData,
+		},
+		WrapTransport: func(rt http.RoundTripper) http.RoundTripper {
+			return &tokenRoundTripper{base: rt, token: cached}
+		},
+	}
+}
+
+type cachedToken struct {
+	source TokenSource
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func (t *cachedToken) get() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && time.Until(t.expiry) > time.Minute {
+		return t.token, nil
+	}
+
+	token, expiry, err := t.source()
+	if err != nil {
+		return "", err
+	}
+	t.token = token
+	t.expiry = expiry
+
+	return token, nil
+}
+
+type tokenRoundTripper struct {
+	base  http.RoundTripper
+	token *cachedToken
+}
+
+func (rt *tokenRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := rt.token.get()
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return rt.base.RoundTrip(req)
+}
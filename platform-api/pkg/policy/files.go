@@ -0,0 +1,314 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// dataBlock is a data source declared in a configuration file.
+type dataBlock struct {
+	Type string
+	Name string
+}
+
+// CheckFiles returns the disallowed data sources declared in configuration
+// files, so they can be refused before being planned; each message names the
+// file. Files other than .tf and .tf.json are ignored. Only the files
+// themselves are read: data sources in modules fetched from a registry or
+// repository are still only seen by Evaluate.
+func (p *Policy) CheckFiles(files map[string][]byte) ([]Violation, error) {
+	if len(p.AllowedDataSources) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var violations []Violation
+	for _, name := range names {
+		var blocks []dataBlock
+		var err error
+		switch {
+		case strings.HasSuffix(name, ".tf.json"):
+			blocks, err = jsonDataBlocks(files[name])
+		case strings.HasSuffix(name, ".tf"):
+			blocks, err = hclDataBlocks(files[name])
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", name, err)
+		}
+
+		for _, block := range blocks {
+			if p.allowsDataSource(block.Type) {
+				continue
+			}
+			violations = append(violations, Violation{
+				Rule:     RuleAllowedDataSources,
+				Resource: "data." + block.Type + "." + block.Name,
+				Message:  fmt.Sprintf("data source %s is not allowed (%s)", block.Type, name),
+			})
+		}
+	}
+	return violations, nil
+}
+
+// jsonDataBlocks reads the data blocks of a .tf.json file. Terraform's JSON
+// syntax allows a block type to hold an object or an array of objects.
+func jsonDataBlocks(src []byte) ([]dataBlock, error) {
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(src, &root); err != nil {
+		return nil, err
+	}
+	data, ok := root["data"]
+	if !ok {
+		return nil, nil
+	}
+
+	byType, err := jsonObjects(data)
+	if err != nil {
+		return nil, fmt.Errorf("data: %v", err)
+	}
+	var blocks []dataBlock
+	for _, types := range byType {
+		for dataType, raw := range types {
+			byName, err := jsonObjects(raw)
+			if err != nil {
+				return nil, fmt.Errorf("data.%s: %v", dataType, err)
+			}
+			for _, names := range byName {
+				for name := range names {
+					blocks = append(blocks, dataBlock{Type: dataType, Name: name})
+				}
+			}
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].Type != blocks[j].Type {
+			return blocks[i].Type < blocks[j].Type
+		}
+		return blocks[i].Name < blocks[j].Name
+	})
+	return blocks, nil
+}
+
+func jsonObjects(raw json.RawMessage) ([]map[string]json.RawMessage, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &object); err == nil {
+		return []map[string]json.RawMessage{object}, nil
+	}
+	var objects []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &objects); err != nil {
+		return nil, fmt.Errorf("expected an object or an array of objects")
+	}
+	return objects, nil
+}
+
+// hclDataBlocks finds the data blocks of a .tf file. It does not parse HCL
+// fully: it skips comments, strings, template interpolations and heredocs
+// to track brace depth, and takes a data identifier outside any braces,
+// where Terraform only allows block headers, as the start of a data block.
+func hclDataBlocks(src []byte) ([]dataBlock, error) {
+	s := &hclScanner{src: src}
+	var blocks []dataBlock
+	depth := 0
+	for s.pos < len(src) {
+		c := src[s.pos]
+		switch {
+		case c == '#' || s.has("//"):
+			s.skipLine()
+		case s.has("/*"):
+			if err := s.skipPast("*/"); err != nil {
+				return nil, err
+			}
+		case c == '"':
+			if _, err := s.quoted(); err != nil {
+				return nil, err
+			}
+		case s.has("<<"):
+			if err := s.heredoc(); err != nil {
+				return nil, err
+			}
+		case c == '{':
+			depth++
+			s.pos++
+		case c == '}':
+			if depth == 0 {
+				return nil, fmt.Errorf("line %d: unexpected }", s.line(s.pos))
+			}
+			depth--
+			s.pos++
+		case isIdentStart(c):
+			word := s.ident()
+			if depth == 0 && word == "data" {
+				block, err := s.dataHeader()
+				if err != nil {
+					return nil, err
+				}
+				blocks = append(blocks, block)
+			}
+		default:
+			s.pos++
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unclosed block")
+	}
+	return blocks, nil
+}
+
+type hclScanner struct {
+	src []byte
+	pos int
+}
+
+func (s *hclScanner) has(prefix string) bool {
+	return bytes.HasPrefix(s.src[s.pos:], []byte(prefix))
+}
+
+// line is the line number of offset pos, for errors.
+func (s *hclScanner) line(pos int) int {
+	return 1 + bytes.Count(s.src[:pos], []byte("\n"))
+}
+
+func (s *hclScanner) skipLine() {
+	for s.pos < len(s.src) && s.src[s.pos] != '\n' {
+		s.pos++
+	}
+}
+
+func (s *hclScanner) skipPast(end string) error {
+	start := s.pos
+	i := bytes.Index(s.src[s.pos:], []byte(end))
+	if i < 0 {
+		return fmt.Errorf("line %d: unterminated comment", s.line(start))
+	}
+	s.pos += i + len(end)
+	return nil
+}
+
+func (s *hclScanner) ident() string {
+	start := s.pos
+	for s.pos < len(s.src) && isIdentPart(s.src[s.pos]) {
+		s.pos++
+	}
+	return string(s.src[start:s.pos])
+}
+
+// quoted skips a quoted string, including any interpolations in it, and
+// returns its raw content.
+func (s *hclScanner) quoted() (string, error) {
+	start := s.pos
+	s.pos++
+	from := s.pos
+	for s.pos < len(s.src) {
+		switch {
+		case s.src[s.pos] == '\\':
+			s.pos += 2
+		case s.has("$${") || s.has("%%{"):
+			s.pos += 3
+		case s.has("${") || s.has("%{"):
+			s.pos += 2
+			if err := s.interpolation(); err != nil {
+				return "", err
+			}
+		case s.src[s.pos] == '"':
+			s.pos++
+			return string(s.src[from : s.pos-1]), nil
+		default:
+			s.pos++
+		}
+	}
+	return "", fmt.Errorf("line %d: unterminated string", s.line(start))
+}
+
+// interpolation skips to the brace closing a template interpolation or
+// directive, whose expression may hold strings and braces of its own.
+func (s *hclScanner) interpolation() error {
+	start := s.pos
+	depth := 0
+	for s.pos < len(s.src) {
+		switch s.src[s.pos] {
+		case '"':
+			if _, err := s.quoted(); err != nil {
+				return err
+			}
+			continue
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				s.pos++
+				return nil
+			}
+			depth--
+		}
+		s.pos++
+	}
+	return fmt.Errorf("line %d: unterminated interpolation", s.line(start))
+}
+
+// heredoc skips a <<MARKER or <<-MARKER heredoc through its closing marker.
+func (s *hclScanner) heredoc() error {
+	start := s.pos
+	s.pos += 2
+	if s.pos < len(s.src) && s.src[s.pos] == '-' {
+		s.pos++
+	}
+	marker := s.ident()
+	if marker == "" {
+		// Not a heredoc, such as a << in an expression.
+		return nil
+	}
+	s.skipLine()
+	for s.pos < len(s.src) {
+		s.pos++
+		lineStart := s.pos
+		s.skipLine()
+		if strings.TrimSpace(string(s.src[lineStart:s.pos])) == marker {
+			return nil
+		}
+	}
+	return fmt.Errorf("line %d: unterminated heredoc %s", s.line(start), marker)
+}
+
+// dataHeader reads the type and name labels after a data keyword.
+func (s *hclScanner) dataHeader() (dataBlock, error) {
+	start := s.pos
+	var labels []string
+	for len(labels) < 2 {
+		for s.pos < len(s.src) && (s.src[s.pos] == ' ' || s.src[s.pos] == '\t') {
+			s.pos++
+		}
+		switch {
+		case s.pos >= len(s.src):
+			return dataBlock{}, fmt.Errorf("line %d: incomplete data block", s.line(start))
+		case s.src[s.pos] == '"':
+			label, err := s.quoted()
+			if err != nil {
+				return dataBlock{}, err
+			}
+			labels = append(labels, label)
+		case isIdentStart(s.src[s.pos]):
+			labels = append(labels, s.ident())
+		default:
+			return dataBlock{}, fmt.Errorf("line %d: data block needs a type and a name", s.line(start))
+		}
+	}
+	return dataBlock{Type: labels[0], Name: labels[1]}, nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || c == '-' || c >= '0' && c <= '9'
+}
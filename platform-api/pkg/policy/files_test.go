@@ -0,0 +1,112 @@
+package policy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckFiles(t *testing.T) {
+	policy := &Policy{AllowedDataSources: []string{"aws_caller_identity", "aws_iam_*"}}
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []Violation
+	}{
+		{
+			name: "allowed",
+			files: map[string]string{"main.tf": `
+data "aws_caller_identity" "current" {}
+data "aws_iam_policy_document" "read" {
+  statement {
+    actions = ["s3:GetObject"]
+  }
+}`},
+		},
+		{
+			name: "disallowed",
+			files: map[string]string{
+				"main.tf":             `data "external" "cmd" { program = ["sh", "-c", "id"] }`,
+				"modules/net/main.tf": "data http ip {\n  url = \"https://example.com\"\n}\n",
+			},
+			want: []Violation{
+				{Rule: RuleAllowedDataSources, Resource: "data.external.cmd", Message: "data source external is not allowed (main.tf)"},
+				{Rule: RuleAllowedDataSources, Resource: "data.http.ip", Message: "data source http is not allowed (modules/net/main.tf)"},
+			},
+		},
+		{
+			name: "not blocks",
+			files: map[string]string{"main.tf": `
+# data "external" "comment" {}
+// data "external" "comment" {}
+/* data "external" "comment" {} */
+resource "aws_s3_bucket" "data" {
+  bucket = "data ${jsonencode({ data = "}" })} data \"external\" \"x\" {}"
+  policy = <<-EOT
+    }
+    data "external" "heredoc" {}
+  EOT
+  tags = { data = "x" }
+}
+locals {
+  data = "external"
+  escaped = "$${data}"
+}`},
+		},
+		{
+			name: "json",
+			files: map[string]string{
+				"main.tf.json":  `{"data":{"external":{"cmd":{"program":["id"]}},"aws_caller_identity":{"current":{}}}}`,
+				"extra.tf.json": `{"data":[{"http":[{"ip":{"url":"https://example.com"}}]}]}`,
+			},
+			want: []Violation{
+				{Rule: RuleAllowedDataSources, Resource: "data.http.ip", Message: "data source http is not allowed (extra.tf.json)"},
+				{Rule: RuleAllowedDataSources, Resource: "data.external.cmd", Message: "data source external is not allowed (main.tf.json)"},
+			},
+		},
+		{
+			name:  "other files",
+			files: map[string]string{"README.md": `data "external" "cmd" {}`, "terraform.tfvars": `data = "external"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := make(map[string][]byte, len(tt.files))
+			for name, content := range tt.files {
+				files[name] = []byte(content)
+			}
+			got, err := policy.CheckFiles(files)
+			if err != nil {
+				t.Fatalf("CheckFiles: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations:\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckFilesInvalid(t *testing.T) {
+	policy := &Policy{AllowedDataSources: []string{"aws_caller_identity"}}
+	for _, content := range []string{
+		`data "external" {}`,
+		`resource "a" "b" {`,
+		`resource "a" "b" {}}`,
+		`locals { a = "unterminated }`,
+		"locals {\n  a = <<EOT\n  text\n}\n",
+		`/* unterminated`,
+	} {
+		if _, err := policy.CheckFiles(map[string][]byte{"main.tf": []byte(content)}); err == nil {
+			t.Errorf("CheckFiles accepted %q", content)
+		}
+	}
+	if _, err := policy.CheckFiles(map[string][]byte{"main.tf.json": []byte(`{"data":"external"}`)}); err == nil {
+		t.Error("CheckFiles accepted a malformed data block in JSON")
+	}
+
+	got, err := (&Policy{}).CheckFiles(map[string][]byte{"main.tf": []byte(`data "external" {`)})
+	if err != nil || got != nil {
+		t.Errorf("without allowed_data_sources got %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,424 @@
+// Package policy evaluates Terraform JSON plans (`terraform show -json`)
+// against a declarative rule set before they are applied.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// hoursPerMonth converts hourly prices to the monthly estimate.
+const hoursPerMonth = 730
+
+// Rule names reported in violations.
+const (
+	RuleAllowedResourceTypes = "allowed_resource_types"
+	RuleSizeCaps             = "size_caps"
+	RuleRequiredTags         = "required_tags"
+	RulePublicAccess         = "deny_public_access"
+	RuleMaxMonthlyCost       = "max_monthly_cost"
+	RuleAllowedDataSources   = "allowed_data_sources"
+	RuleDenyProvisioners     = "deny_provisioners"
+)
+
+// Policy is the rule set. Zero values disable a rule.
+//
+//	allowed_resource_types: ["aws_s3_*", "aws_db_instance"]
+//	allowed_data_sources: ["aws_caller_identity", "aws_iam_policy_document"]
+//	deny_provisioners: true
+//	size_caps:
+//	  aws_db_instance.instance_class: 2xlarge
+//	required_tags: [TenantID, Project]
+//	deny_public_access: true
+//	max_monthly_cost: 500
+//	hourly_prices:
+//	  db.t3.medium: 0.068
+//
+// Allowed types are path.Match patterns. Size caps are keyed by resource
+// type and attribute and compare the size suffix of values such as
+// db.r6g.16xlarge. The monthly cost covers every sized resource in the
+// resulting state and needs a price for each size in use.
+//
+// Data sources and provisioners are read from the plan's configuration,
+// including that of nested modules. A policy only sees a plan once it has
+// been made, and data sources are read while planning: a disallowed one,
+// such as the external data source that runs a program, stops the plan from
+// being applied but has already run. CheckFiles finds them in uploaded
+// configuration before it is planned. Provisioners only run on apply, so
+// denying them stops them in time.
+type Policy struct {
+	AllowedResourceTypes []string           `yaml:"allowed_resource_types"`
+	AllowedDataSources   []string           `yaml:"allowed_data_sources"`
+	DenyProvisioners     bool               `yaml:"deny_provisioners"`
+	SizeCaps             map[string]string  `yaml:"size_caps"`
+	RequiredTags         []string           `yaml:"required_tags"`
+	DenyPublicAccess     bool               `yaml:"deny_public_access"`
+	MaxMonthlyCost       float64            `yaml:"max_monthly_cost"`
+	HourlyPrices         map[string]float64 `yaml:"hourly_prices"`
+}
+
+// Violation is a rule a planned resource breaks. Resource is the resource
+// address, empty for rules about the plan as a whole.
+type Violation struct {
+	Rule     string `json:"rule"`
+	Resource string `json:"resource,omitempty"`
+	Message  string `json:"message"`
+}
+
+// sizedAttributes hold an instance size on the resources that have one.
+var sizedAttributes = []string{"instance_type", "instance_class", "node_type"}
+
+var publicACLs = map[string]bool{
+	"public-read":        true,
+	"public-read-write":  true,
+	"authenticated-read": true,
+}
+
+var publicAccessBlockFlags = []string{"block_public_acls", "block_public_policy", "ignore_public_acls", "restrict_public_buckets"}
+
+var xlargePattern = regexp.MustCompile(`^(\d+)xlarge$`)
+
+// Load reads a policy from a YAML file. Unknown keys and unparseable size
+// caps are errors.
+func Load(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %v", err)
+	}
+
+	var p Policy
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %v", err)
+	}
+
+	for key, limit := range p.SizeCaps {
+		if !strings.Contains(key, ".") {
+			return nil, fmt.Errorf("size_caps: %q must be resource_type.attribute", key)
+		}
+		if _, ok := sizeRank(limit); !ok {
+			return nil, fmt.Errorf("size_caps: unknown size %q for %s", limit, key)
+		}
+	}
+	for _, pattern := range p.AllowedResourceTypes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("allowed_resource_types: invalid pattern %q", pattern)
+		}
+	}
+	for _, pattern := range p.AllowedDataSources {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("allowed_data_sources: invalid pattern %q", pattern)
+		}
+	}
+	return &p, nil
+}
+
+type plan struct {
+	ResourceChanges []resourceChange `json:"resource_changes"`
+	Configuration   struct {
+		RootModule configModule `json:"root_module"`
+	} `json:"configuration"`
+}
+
+// configModule is a module of the plan's configuration. Addresses of its
+// resources are relative to the module.
+type configModule struct {
+	Resources []struct {
+		Address      string `json:"address"`
+		Mode         string `json:"mode"`
+		Type         string `json:"type"`
+		Provisioners []struct {
+			Type string `json:"type"`
+		} `json:"provisioners"`
+	} `json:"resources"`
+	ModuleCalls map[string]struct {
+		Module configModule `json:"module"`
+	} `json:"module_calls"`
+}
+
+type resourceChange struct {
+	Address string `json:"address"`
+	Mode    string `json:"mode"`
+	Type    string `json:"type"`
+	Change  struct {
+		Actions []string               `json:"actions"`
+		After   map[string]interface{} `json:"after"`
+	} `json:"change"`
+}
+
+// Evaluate returns the violations of a plan, ordered by resource address.
+// Only resources the plan creates or updates are checked against the
+// per-resource rules; resources it leaves alone count towards the cost.
+// Data sources are not checked in a destroy plan, so a configuration the
+// policy has since disallowed can still be torn down.
+func (p *Policy) Evaluate(planJSON []byte, destroy bool) ([]Violation, error) {
+	var pl plan
+	if err := json.Unmarshal(planJSON, &pl); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %v", err)
+	}
+
+	violations := []Violation{}
+	var monthly float64
+	for _, rc := range pl.ResourceChanges {
+		if rc.Mode != "managed" || rc.Change.After == nil {
+			continue
+		}
+
+		cost, costViolations := p.cost(rc)
+		monthly += cost
+		violations = append(violations, costViolations...)
+
+		if !changes(rc.Change.Actions) {
+			continue
+		}
+		violations = append(violations, p.checkType(rc)...)
+		violations = append(violations, p.checkSize(rc)...)
+		violations = append(violations, p.checkTags(rc)...)
+		violations = append(violations, p.checkPublicAccess(rc)...)
+	}
+	violations = append(violations, p.checkConfiguration(pl.Configuration.RootModule, "", destroy)...)
+
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Resource < violations[j].Resource })
+
+	if p.MaxMonthlyCost > 0 && monthly > p.MaxMonthlyCost {
+		violations = append(violations, Violation{
+			Rule:    RuleMaxMonthlyCost,
+			Message: fmt.Sprintf("estimated monthly cost $%.2f exceeds the $%.2f limit", monthly, p.MaxMonthlyCost),
+		})
+	}
+	return violations, nil
+}
+
+func (p *Policy) checkType(rc resourceChange) []Violation {
+	if len(p.AllowedResourceTypes) == 0 || matchAny(p.AllowedResourceTypes, rc.Type) {
+		return nil
+	}
+	return []Violation{{
+		Rule:     RuleAllowedResourceTypes,
+		Resource: rc.Address,
+		Message:  fmt.Sprintf("resource type %s is not allowed", rc.Type),
+	}}
+}
+
+// checkConfiguration checks the data sources and provisioners of a module
+// and the modules it calls. prefix is the module's address.
+func (p *Policy) checkConfiguration(module configModule, prefix string, destroy bool) []Violation {
+	var violations []Violation
+	for _, r := range module.Resources {
+		address := prefix + r.Address
+		if r.Mode == "data" && !destroy && !p.allowsDataSource(r.Type) {
+			violations = append(violations, Violation{
+				Rule:     RuleAllowedDataSources,
+				Resource: address,
+				Message:  fmt.Sprintf("data source %s is not allowed", r.Type),
+			})
+		}
+		if p.DenyProvisioners {
+			for _, provisioner := range r.Provisioners {
+				violations = append(violations, Violation{
+					Rule:     RuleDenyProvisioners,
+					Resource: address,
+					Message:  fmt.Sprintf("%s provisioner is not allowed", provisioner.Type),
+				})
+			}
+		}
+	}
+
+	names := make([]string, 0, len(module.ModuleCalls))
+	for name := range module.ModuleCalls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		violations = append(violations, p.checkConfiguration(module.ModuleCalls[name].Module, prefix+"module."+name+".", destroy)...)
+	}
+	return violations
+}
+
+func (p *Policy) allowsDataSource(dataType string) bool {
+	return len(p.AllowedDataSources) == 0 || matchAny(p.AllowedDataSources, dataType)
+}
+
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) checkSize(rc resourceChange) []Violation {
+	var violations []Violation
+	for key, limit := range p.SizeCaps {
+		resourceType, attribute, _ := strings.Cut(key, ".")
+		if resourceType != rc.Type {
+			continue
+		}
+		value, ok := rc.Change.After[attribute].(string)
+		if !ok {
+			continue
+		}
+
+		rank, known := sizeRank(value)
+		maxRank, _ := sizeRank(limit)
+		switch {
+		case !known:
+			violations = append(violations, Violation{
+				Rule:     RuleSizeCaps,
+				Resource: rc.Address,
+				Message:  fmt.Sprintf("%s %s has a size the policy cannot compare with its %s cap", attribute, value, limit),
+			})
+		case rank > maxRank:
+			violations = append(violations, Violation{
+				Rule:     RuleSizeCaps,
+				Resource: rc.Address,
+				Message:  fmt.Sprintf("%s %s exceeds the %s cap", attribute, value, limit),
+			})
+		}
+	}
+	return violations
+}
+
+// checkTags applies to resources that support tags. tags_all adds the
+// provider's default_tags to the resource's own tags.
+func (p *Policy) checkTags(rc resourceChange) []Violation {
+	if len(p.RequiredTags) == 0 {
+		return nil
+	}
+	after := rc.Change.After
+	_, hasTags := after["tags"]
+	_, hasTagsAll := after["tags_all"]
+	if !hasTags && !hasTagsAll {
+		return nil
+	}
+	tags := map[string]interface{}{}
+	for _, attribute := range []string{"tags", "tags_all"} {
+		if values, ok := after[attribute].(map[string]interface{}); ok {
+			for k, v := range values {
+				tags[k] = v
+			}
+		}
+	}
+
+	var missing []string
+	for _, key := range p.RequiredTags {
+		if value, ok := tags[key].(string); !ok || value == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return []Violation{{
+		Rule:     RuleRequiredTags,
+		Resource: rc.Address,
+		Message:  fmt.Sprintf("missing required tags: %s", strings.Join(missing, ", ")),
+	}}
+}
+
+func (p *Policy) checkPublicAccess(rc resourceChange) []Violation {
+	if !p.DenyPublicAccess {
+		return nil
+	}
+
+	after := rc.Change.After
+	var reasons []string
+	if acl, ok := after["acl"].(string); ok && publicACLs[acl] {
+		reasons = append(reasons, fmt.Sprintf("acl %s grants public access", acl))
+	}
+	if public, ok := after["publicly_accessible"].(bool); ok && public {
+		reasons = append(reasons, "publicly_accessible is enabled")
+	}
+	if rc.Type == "aws_s3_bucket_public_access_block" {
+		for _, flag := range publicAccessBlockFlags {
+			if enabled, ok := after[flag].(bool); !ok || !enabled {
+				reasons = append(reasons, fmt.Sprintf("%s must be true", flag))
+			}
+		}
+	}
+
+	violations := make([]Violation, 0, len(reasons))
+	for _, reason := range reasons {
+		violations = append(violations, Violation{Rule: RulePublicAccess, Resource: rc.Address, Message: reason})
+	}
+	return violations
+}
+
+// cost estimates the monthly cost of a sized resource. A size without a
+// price is a violation, since the limit could not be enforced otherwise.
+func (p *Policy) cost(rc resourceChange) (float64, []Violation) {
+	if p.MaxMonthlyCost <= 0 {
+		return 0, nil
+	}
+	for _, attribute := range sizedAttributes {
+		size, ok := rc.Change.After[attribute].(string)
+		if !ok || size == "" {
+			continue
+		}
+		price, ok := p.HourlyPrices[size]
+		if !ok {
+			return 0, []Violation{{
+				Rule:     RuleMaxMonthlyCost,
+				Resource: rc.Address,
+				Message:  fmt.Sprintf("no price is configured for %s, so its cost cannot be estimated", size),
+			}}
+		}
+		return price * hoursPerMonth, nil
+	}
+	return 0, nil
+}
+
+// sizeRank orders size suffixes: nano < micro < small < medium < large <
+// xlarge < 2xlarge < ... < metal.
+func sizeRank(value string) (int, bool) {
+	size := value
+	if i := strings.LastIndex(value, "."); i >= 0 {
+		size = value[i+1:]
+	}
+
+	switch size {
+	case "nano":
+		return 0, true
+	case "micro":
+		return 1, true
+	case "small":
+		return 2, true
+	case "medium":
+		return 3, true
+	case "large":
+		return 4, true
+	case "xlarge":
+		return 5, true
+	case "metal":
+		return 1 << 20, true
+	}
+	if m := xlargePattern.FindStringSubmatch(size); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err == nil {
+			return 4 + n, true
+		}
+	}
+	return 0, false
+}
+
+// changes reports whether the actions create or update the resource; a
+// replacement is a delete and a create.
+func changes(actions []string) bool {
+	for _, action := range actions {
+		if action == "create" || action == "update" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,336 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// planWith builds a minimal `terraform show -json` document from resource
+// change entries and an optional configuration root module.
+func planWith(rootModule string, changes ...string) []byte {
+	if rootModule == "" {
+		rootModule = "{}"
+	}
+	list := "["
+	for i, c := range changes {
+		if i > 0 {
+			list += ","
+		}
+		list += c
+	}
+	list += "]"
+	return []byte(fmt.Sprintf(`{"format_version":"1.2","resource_changes":%s,"configuration":{"root_module":%s}}`, list, rootModule))
+}
+
+func change(address, resourceType string, actions, after string) string {
+	return fmt.Sprintf(`{"address":%q,"mode":"managed","type":%q,"change":{"actions":%s,"after":%s}}`,
+		address, resourceType, actions, after)
+}
+
+func TestEvaluate(t *testing.T) {
+	policy := &Policy{
+		AllowedResourceTypes: []string{"aws_s3_bucket*", "aws_db_instance"},
+		AllowedDataSources:   []string{"aws_caller_identity"},
+		DenyProvisioners:     true,
+		SizeCaps:             map[string]string{"aws_db_instance.instance_class": "xlarge"},
+		RequiredTags:         []string{"TenantID"},
+		DenyPublicAccess:     true,
+		MaxMonthlyCost:       100,
+		HourlyPrices:         map[string]float64{"db.t3.micro": 0.02, "db.r6g.large": 0.25},
+	}
+	tagged := `"tags":{"TenantID":"t-1"}`
+
+	tests := []struct {
+		name string
+		plan []byte
+		want []Violation
+	}{
+		{
+			name: "compliant plan",
+			plan: planWith(`{"resources":[{"address":"data.aws_caller_identity.current","mode":"data","type":"aws_caller_identity"}]}`,
+				change("aws_s3_bucket.data", "aws_s3_bucket", `["create"]`, `{"acl":"private",`+tagged+`}`),
+				change("aws_db_instance.main", "aws_db_instance", `["create"]`, `{"instance_class":"db.t3.micro",`+tagged+`}`),
+			),
+			want: []Violation{},
+		},
+		{
+			name: "disallowed resource type",
+			plan: planWith("", change("aws_instance.vm", "aws_instance", `["create"]`, `{}`)),
+			want: []Violation{
+				{Rule: RuleAllowedResourceTypes, Resource: "aws_instance.vm", Message: "resource type aws_instance is not allowed"},
+			},
+		},
+		{
+			name: "unchanged resources only count towards cost",
+			plan: planWith("", change("aws_instance.vm", "aws_instance", `["no-op"]`, `{}`)),
+			want: []Violation{},
+		},
+		{
+			name: "deleted resources are ignored",
+			plan: planWith("", change("aws_instance.vm", "aws_instance", `["delete"]`, `null`)),
+			want: []Violation{},
+		},
+		{
+			name: "size over the cap",
+			plan: planWith("", change("aws_db_instance.main", "aws_db_instance", `["update"]`, `{"instance_class":"db.r6g.2xlarge",`+tagged+`}`)),
+			want: []Violation{
+				{Rule: RuleMaxMonthlyCost, Resource: "aws_db_instance.main", Message: "no price is configured for db.r6g.2xlarge, so its cost cannot be estimated"},
+				{Rule: RuleSizeCaps, Resource: "aws_db_instance.main", Message: "instance_class db.r6g.2xlarge exceeds the xlarge cap"},
+			},
+		},
+		{
+			name: "public bucket",
+			plan: planWith("",
+				change("aws_s3_bucket.site", "aws_s3_bucket", `["create"]`, `{"acl":"public-read",`+tagged+`}`),
+				change("aws_s3_bucket_public_access_block.site", "aws_s3_bucket_public_access_block", `["create"]`,
+					`{"block_public_acls":true,"block_public_policy":false,"ignore_public_acls":true,"restrict_public_buckets":true}`),
+			),
+			want: []Violation{
+				{Rule: RulePublicAccess, Resource: "aws_s3_bucket.site", Message: "acl public-read grants public access"},
+				{Rule: RulePublicAccess, Resource: "aws_s3_bucket_public_access_block.site", Message: "block_public_policy must be true"},
+			},
+		},
+		{
+			name: "cost over the limit",
+			plan: planWith("",
+				change("aws_db_instance.a", "aws_db_instance", `["no-op"]`, `{"instance_class":"db.r6g.large",`+tagged+`}`),
+				change("aws_db_instance.b", "aws_db_instance", `["create"]`, `{"instance_class":"db.r6g.large",`+tagged+`}`),
+			),
+			want: []Violation{
+				{Rule: RuleMaxMonthlyCost, Message: "estimated monthly cost $365.00 exceeds the $100.00 limit"},
+			},
+		},
+		{
+			name: "data sources and provisioners in nested modules",
+			plan: planWith(`{
+				"resources":[
+					{"address":"data.external.cmd","mode":"data","type":"external"},
+					{"address":"aws_s3_bucket.b","mode":"managed","type":"aws_s3_bucket","provisioners":[{"type":"local-exec"}]}
+				],
+				"module_calls":{"net":{"module":{
+					"resources":[{"address":"data.http.ip","mode":"data","type":"http"}],
+					"module_calls":{"inner":{"module":{"resources":[{"address":"terraform_data.run","mode":"managed","type":"terraform_data","provisioners":[{"type":"remote-exec"},{"type":"file"}]}]}}}
+				}}}
+			}`),
+			want: []Violation{
+				{Rule: RuleDenyProvisioners, Resource: "aws_s3_bucket.b", Message: "local-exec provisioner is not allowed"},
+				{Rule: RuleAllowedDataSources, Resource: "data.external.cmd", Message: "data source external is not allowed"},
+				{Rule: RuleAllowedDataSources, Resource: "module.net.data.http.ip", Message: "data source http is not allowed"},
+				{Rule: RuleDenyProvisioners, Resource: "module.net.module.inner.terraform_data.run", Message: "remote-exec provisioner is not allowed"},
+				{Rule: RuleDenyProvisioners, Resource: "module.net.module.inner.terraform_data.run", Message: "file provisioner is not allowed"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := policy.Evaluate(tt.plan, false)
+			if err != nil {
+				t.Fatalf("Evaluate: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations:\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateEmptyPolicy(t *testing.T) {
+	plan := planWith(`{"resources":[{"address":"data.external.cmd","mode":"data","type":"external","provisioners":[{"type":"local-exec"}]}]}`,
+		change("aws_instance.vm", "aws_instance", `["create"]`, `{"instance_type":"m5.metal","acl":"public-read"}`))
+
+	got, err := (&Policy{}).Evaluate(plan, false)
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("violations = %+v, want none", got)
+	}
+}
+
+func TestEvaluateInvalidPlan(t *testing.T) {
+	if _, err := (&Policy{}).Evaluate([]byte("not json"), false); err == nil {
+		t.Error("Evaluate accepted an invalid plan")
+	}
+}
+
+func TestSizeRank(t *testing.T) {
+	tests := []struct {
+		value string
+		rank  int
+		known bool
+	}{
+		{"db.t3.nano", 0, true},
+		{"t3.micro", 1, true},
+		{"small", 2, true},
+		{"cache.t3.medium", 3, true},
+		{"m5.large", 4, true},
+		{"m5.xlarge", 5, true},
+		{"m5.2xlarge", 6, true},
+		{"db.r6g.16xlarge", 20, true},
+		{"m5.metal", 1 << 20, true},
+		{"custom", 0, false},
+		{"m5.xxlarge", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		rank, known := sizeRank(tt.value)
+		if rank != tt.rank || known != tt.known {
+			t.Errorf("sizeRank(%q) = %d, %t, want %d, %t", tt.value, rank, known, tt.rank, tt.known)
+		}
+	}
+
+	metal, _ := sizeRank("m5.metal")
+	largest, _ := sizeRank("m5.96xlarge")
+	if metal <= largest {
+		t.Errorf("metal ranks %d, not above 96xlarge at %d", metal, largest)
+	}
+}
+
+func TestCheckTags(t *testing.T) {
+	policy := &Policy{RequiredTags: []string{"TenantID", "Project"}}
+
+	tests := []struct {
+		name  string
+		after map[string]interface{}
+		want  string
+	}{
+		{
+			name:  "resource without tags",
+			after: map[string]interface{}{"name": "queue"},
+		},
+		{
+			name:  "own tags",
+			after: map[string]interface{}{"tags": map[string]interface{}{"TenantID": "t-1", "Project": "p"}},
+		},
+		{
+			name: "default tags in tags_all",
+			after: map[string]interface{}{
+				"tags":     nil,
+				"tags_all": map[string]interface{}{"TenantID": "t-1", "Project": "p"},
+			},
+		},
+		{
+			name: "tags and tags_all combined",
+			after: map[string]interface{}{
+				"tags":     map[string]interface{}{"Project": "p"},
+				"tags_all": map[string]interface{}{"TenantID": "t-1"},
+			},
+		},
+		{
+			name:  "missing and empty tags",
+			after: map[string]interface{}{"tags": map[string]interface{}{"TenantID": ""}},
+			want:  "missing required tags: TenantID, Project",
+		},
+		{
+			name:  "unknown tags_all",
+			after: map[string]interface{}{"tags_all": nil},
+			want:  "missing required tags: TenantID, Project",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := resourceChange{Address: "aws_sqs_queue.q", Type: "aws_sqs_queue"}
+			rc.Change.After = tt.after
+
+			violations := policy.checkTags(rc)
+			switch {
+			case tt.want == "" && len(violations) > 0:
+				t.Errorf("violations = %+v, want none", violations)
+			case tt.want != "" && (len(violations) != 1 || violations[0].Message != tt.want || violations[0].Rule != RuleRequiredTags):
+				t.Errorf("violations = %+v, want %q", violations, tt.want)
+			}
+		})
+	}
+}
+
+func TestCost(t *testing.T) {
+	policy := &Policy{MaxMonthlyCost: 100, HourlyPrices: map[string]float64{"db.t3.micro": 0.02}}
+
+	tests := []struct {
+		name          string
+		after         map[string]interface{}
+		want          float64
+		wantViolation bool
+	}{
+		{name: "priced size", after: map[string]interface{}{"instance_class": "db.t3.micro"}, want: 0.02 * hoursPerMonth},
+		{name: "missing price", after: map[string]interface{}{"instance_type": "m5.large"}, wantViolation: true},
+		{name: "unsized resource", after: map[string]interface{}{"name": "queue"}},
+		{name: "empty size", after: map[string]interface{}{"node_type": ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := resourceChange{Address: "aws_db_instance.main", Type: "aws_db_instance"}
+			rc.Change.After = tt.after
+
+			cost, violations := policy.cost(rc)
+			if cost != tt.want {
+				t.Errorf("cost = %v, want %v", cost, tt.want)
+			}
+			if got := len(violations) > 0; got != tt.wantViolation {
+				t.Errorf("violations = %+v, want violation %t", violations, tt.wantViolation)
+			}
+			if tt.wantViolation && violations[0].Rule != RuleMaxMonthlyCost {
+				t.Errorf("rule = %s, want %s", violations[0].Rule, RuleMaxMonthlyCost)
+			}
+		})
+	}
+
+	if cost, violations := (&Policy{}).cost(resourceChange{}); cost != 0 || violations != nil {
+		t.Errorf("cost without a limit = %v, %+v", cost, violations)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{name: "example", yaml: "allowed_data_sources: [aws_*]\ndeny_provisioners: true\nsize_caps:\n  aws_db_instance.instance_class: 2xlarge\n"},
+		{name: "unknown key", yaml: "allowed_types: [aws_*]\n", wantErr: true},
+		{name: "unknown size", yaml: "size_caps:\n  aws_db_instance.instance_class: huge\n", wantErr: true},
+		{name: "cap without attribute", yaml: "size_caps:\n  aws_db_instance: large\n", wantErr: true},
+		{name: "invalid pattern", yaml: "allowed_data_sources: ['aws_[']\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "policy.yaml")
+			if err := os.WriteFile(file, []byte(tt.yaml), 0600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Load(file)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load error = %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := Load("../../terraform-policy.example.yaml"); err != nil {
+		t.Errorf("example policy: %v", err)
+	}
+}
+
+func TestEvaluateDestroySkipsDataSources(t *testing.T) {
+	policy := &Policy{AllowedDataSources: []string{"aws_caller_identity"}, DenyProvisioners: true}
+	plan := planWith(`{"resources":[
+		{"address":"data.external.cmd","mode":"data","type":"external"},
+		{"address":"terraform_data.run","mode":"managed","type":"terraform_data","provisioners":[{"type":"local-exec"}]}
+	]}`, change("terraform_data.run", "terraform_data", `["delete"]`, `null`))
+
+	got, err := policy.Evaluate(plan, true)
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	want := []Violation{{Rule: RuleDenyProvisioners, Resource: "terraform_data.run", Message: "local-exec provisioner is not allowed"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violations:\n got %+v\nwant %+v", got, want)
+	}
+}
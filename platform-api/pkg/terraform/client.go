@@ -2,74 +2,243 @@ package terraform
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math/rand"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
+const (
+	DefaultBaseURL    = "https://app.terraform.io"
+	DefaultTimeout    = 30 * time.Second
+	DefaultMaxRetries = 4
+
+	mediaType = "application/vnd.api+json"
+
+	// maxPageSize is the largest page the API serves.
+	maxPageSize = 100
+)
+
+// Client talks to the Terraform Cloud (or Enterprise) v2 API. BaseURL and
+// HTTPClient may be replaced, e.g. to point at an httptest server; the
+// HTTPClient timeout bounds each attempt of a request.
+//
+// Rate limited requests (429) are retried, as are server errors and network
+// failures for every method but POST, which may not be safe to repeat.
+// Attempts are spaced by jittered exponential backoff between RetryWaitMin
+// and RetryWaitMax, or by the API's Retry-After when that is longer. A
+// retry is not attempted when the API asks for a wait longer than
+// RetryWaitMax or when it would outlast the context's deadline.
 type Client struct {
-	BaseURL    string
-	Token      string
-	HTTPClient *http.Client
+	BaseURL      string
+	Token        string
+	HTTPClient   *http.Client
+	MaxRetries   int
+	RetryWaitMin time.Duration
+	RetryWaitMax time.Duration
 }
 
-type WorkspaceRequest struct {
-	Data WorkspaceData `json:"data"`
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		BaseURL:      strings.TrimSuffix(baseURL, "/"),
+		Token:        token,
+		HTTPClient:   &http.Client{Timeout: DefaultTimeout},
+		MaxRetries:   DefaultMaxRetries,
+		RetryWaitMin: time.Second,
+		RetryWaitMax: 30 * time.Second,
+	}
 }
 
-type WorkspaceData struct {
-	Type       string              `json:"type"`
-	Attributes WorkspaceAttributes `json:"attributes"`
+// ResourceRef identifies a related JSON:API resource.
+type ResourceRef struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
 }
 
-type WorkspaceAttributes struct {
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
+type Relationship struct {
+	Data *ResourceRef `json:"data"`
 }
 
-func NewClient(baseURL, token string) *Client {
-	return &Client{
-		BaseURL:    baseURL,
-		Token:      token,
-		HTTPClient: &http.Client{},
-	}
+// Pagination is the meta.pagination block of a list response.
+type Pagination struct {
+	CurrentPage  int  `json:"current-page"`
+	PreviousPage *int `json:"prev-page"`
+	NextPage     *int `json:"next-page"`
+	TotalPages   int  `json:"total-pages"`
+	TotalCount   int  `json:"total-count"`
 }
 
-func (c *Client) CreateWorkspace(orgName, workspaceName, description string) error {
-	url := fmt.Sprintf("%s/api/v2/organizations/%s/workspaces", c.BaseURL, orgName)
+// ListOptions selects a page; zero values use the API defaults (page 1 of 20).
+type ListOptions struct {
+	PageNumber int
+	PageSize   int
+}
 
-	payload := WorkspaceRequest{
-		Data: WorkspaceData{
-			Type: "workspaces",
-			Attributes: WorkspaceAttributes{
-				Name:        workspaceName,
-				Description: description,
-			},
-		},
+func (o ListOptions) values() url.Values {
+	values := url.Values{}
+	if o.PageNumber > 0 {
+		values.Set("page[number]", fmt.Sprint(o.PageNumber))
 	}
-
-	jsonPayload, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal workspace request: %v", err)
+	if o.PageSize > 0 {
+		values.Set("page[size]", fmt.Sprint(o.PageSize))
 	}
+	return values
+}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
+// do sends one API request. A non-nil body is sent as the JSON:API document;
+// a non-nil out receives the decoded response. Error responses are returned
+// as *APIError.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	endpoint := c.BaseURL + "/api/v2" + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
 	}
 
-	req.Header.Set("Content-Type", "application/vnd.api+json")
-	req.Header.Set("Authorization", "Bearer "+c.Token)
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to marshal request: %v", err)
+		}
+	}
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.send(ctx, func() (*http.Request, error) {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", mediaType)
+		if body != nil {
+			req.Header.Set("Content-Type", mediaType)
+		}
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+		return req, nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to make request: %v", err)
+		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("failed to create workspace, status: %d", resp.StatusCode)
+	if resp.StatusCode >= 400 {
+		return decodeError(resp)
 	}
 
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
 	return nil
 }
+
+// send makes a request, retrying as described on Client. newRequest builds
+// each attempt so the body can be replayed. The response of the last
+// attempt is returned whatever its status.
+func (c *Client) send(ctx context.Context, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %v", err)
+		}
+
+		resp, err := c.HTTPClient.Do(req)
+		if ctx.Err() != nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return nil, fmt.Errorf("failed to make request: %w", ctx.Err())
+		}
+
+		if attempt < c.MaxRetries && retryable(req.Method, resp, err) {
+			wait, ok := c.retryWait(attempt, resp)
+			deadline, hasDeadline := ctx.Deadline()
+			if ok && (!hasDeadline || time.Until(deadline) > wait) {
+				if resp != nil {
+					io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+					resp.Body.Close()
+				}
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return nil, fmt.Errorf("failed to make request: %w", ctx.Err())
+				case <-timer.C:
+				}
+				continue
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to make request: %w", err)
+		}
+		return resp, nil
+	}
+}
+
+func retryable(method string, resp *http.Response, err error) bool {
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if method == http.MethodPost {
+		return false
+	}
+	return err != nil || resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented
+}
+
+// retryWait returns the delay before the retry following attempt: half the
+// exponential backoff plus a random share of the other half, unless the
+// response asks for longer. It reports false when the response asks for
+// more than RetryWaitMax.
+func (c *Client) retryWait(attempt int, resp *http.Response) (time.Duration, bool) {
+	backoff := c.RetryWaitMax
+	if attempt < 30 && c.RetryWaitMin<<attempt < c.RetryWaitMax {
+		backoff = c.RetryWaitMin << attempt
+	}
+	wait := backoff / 2
+	if wait > 0 {
+		wait += time.Duration(rand.Int63n(int64(wait)))
+	}
+
+	if resp != nil {
+		if after, ok := retryAfter(resp.Header); ok && after > wait {
+			if after > c.RetryWaitMax {
+				return 0, false
+			}
+			wait = after
+		}
+	}
+	return wait, true
+}
+
+// retryAfter reads the delay a response asks for: Retry-After in seconds or
+// as an HTTP date, or Terraform Cloud's X-RateLimit-Reset in seconds.
+func retryAfter(header http.Header) (time.Duration, bool) {
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return time.Until(at), true
+		}
+	}
+	if value := header.Get("X-RateLimit-Reset"); value != "" {
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+			return time.Duration(seconds * float64(time.Second)), true
+		}
+	}
+	return 0, false
+}
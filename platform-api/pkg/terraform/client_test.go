@@ -0,0 +1,347 @@
+package terraform
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client for an httptest server running handler,
+// with retry waits short enough for tests.
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	c := NewClient(srv.URL, "test-token")
+	c.RetryWaitMin = time.Millisecond
+	c.RetryWaitMax = 10 * time.Millisecond
+	return c
+}
+
+func TestClientSendsJSONAPIHeaders(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.Header.Get("Accept"); got != mediaType {
+			t.Errorf("Accept = %q", got)
+		}
+		if r.Method == http.MethodPatch {
+			if got := r.Header.Get("Content-Type"); got != mediaType {
+				t.Errorf("Content-Type = %q", got)
+			}
+		}
+		w.Header().Set("Content-Type", mediaType)
+		w.Write([]byte(`{"data":{"id":"ws-1","type":"workspaces","attributes":{"name":"tenant-a"}}}`))
+	}))
+
+	if _, err := c.GetWorkspace(context.Background(), "acme", "tenant-a"); err != nil {
+		t.Fatalf("GetWorkspace: %v", err)
+	}
+	if _, err := c.UpdateWorkspace(context.Background(), "acme", "tenant-a", WorkspaceAttributes{Description: "x"}); err != nil {
+		t.Fatalf("UpdateWorkspace: %v", err)
+	}
+}
+
+func TestClientDecodesErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		requestID string
+		want      string
+		wantCount int
+	}{
+		{
+			name:      "JSON:API error objects",
+			status:    http.StatusUnprocessableEntity,
+			body:      `{"errors":[{"status":"422","title":"invalid attribute","detail":"Name has already been taken","source":{"pointer":"/data/attributes/name"}},{"status":"422","title":"invalid attribute","detail":"invalid attribute"}]}`,
+			requestID: "req-123",
+			want:      "terraform cloud: 422 invalid attribute: Name has already been taken (/data/attributes/name); invalid attribute (request ID req-123)",
+			wantCount: 2,
+		},
+		{
+			name:      "bare string list",
+			status:    http.StatusNotFound,
+			body:      `{"errors":["not found"]}`,
+			want:      "terraform cloud: 404 not found",
+			wantCount: 1,
+		},
+		{
+			name:   "body that is not JSON",
+			status: http.StatusBadGateway,
+			body:   `<html>bad gateway</html>`,
+			want:   "terraform cloud: 502 Bad Gateway",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.requestID != "" {
+					w.Header().Set("X-Request-Id", tt.requestID)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			c.MaxRetries = 0
+
+			_, err := c.GetWorkspace(context.Background(), "acme", "tenant-a")
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error = %v, want *APIError", err)
+			}
+			if apiErr.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, tt.status)
+			}
+			if len(apiErr.Errors) != tt.wantCount {
+				t.Errorf("got %d error objects, want %d", len(apiErr.Errors), tt.wantCount)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("Error() = %q, want %q", err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors":[{"status":"404","title":"not found"}]}`))
+	}))
+
+	_, err := c.GetWorkspace(context.Background(), "acme", "missing")
+	if !IsNotFound(err) {
+		t.Errorf("IsNotFound(%v) = false", err)
+	}
+	if IsConflict(err) {
+		t.Errorf("IsConflict(%v) = true", err)
+	}
+}
+
+func TestClientContextCancellation(t *testing.T) {
+	release := make(chan struct{})
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := c.GetWorkspace(ctx, "acme", "tenant-a")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("request returned after %s, want prompt cancellation", elapsed)
+	}
+}
+
+func TestClientContextAlreadyCancelled(t *testing.T) {
+	calls := 0
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := c.GetWorkspace(ctx, "acme", "tenant-a"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+	if calls != 0 {
+		t.Errorf("server saw %d requests, want 0", calls)
+	}
+}
+
+func TestClientRetriesRateLimitedRequests(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		value   func() string
+		waitMax time.Duration
+		minWait time.Duration
+	}{
+		{
+			name:    "Retry-After in seconds",
+			header:  "Retry-After",
+			value:   func() string { return "1" },
+			waitMax: 2 * time.Second,
+			minWait: time.Second,
+		},
+		{
+			name:   "Retry-After as an HTTP date",
+			header: "Retry-After",
+			value: func() string {
+				return time.Now().Add(2 * time.Second).UTC().Format(http.TimeFormat)
+			},
+			waitMax: 3 * time.Second,
+			minWait: 500 * time.Millisecond,
+		},
+		{
+			name:    "X-RateLimit-Reset",
+			header:  "X-RateLimit-Reset",
+			value:   func() string { return "0.2" },
+			waitMax: time.Second,
+			minWait: 200 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					w.Header().Set(tt.header, tt.value())
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.Write([]byte(`{"data":{"id":"ws-1","type":"workspaces","attributes":{"name":"tenant-a"}}}`))
+			}))
+			c.RetryWaitMax = tt.waitMax
+
+			start := time.Now()
+			// POST is not retried on server errors, but is when rate limited.
+			if _, err := c.CreateWorkspace(context.Background(), "acme", WorkspaceAttributes{Name: "tenant-a"}); err != nil {
+				t.Fatalf("CreateWorkspace: %v", err)
+			}
+			if got := atomic.LoadInt32(&calls); got != 2 {
+				t.Errorf("server saw %d requests, want 2", got)
+			}
+			if elapsed := time.Since(start); elapsed < tt.minWait {
+				t.Errorf("retried after %s, want at least %s", elapsed, tt.minWait)
+			}
+		})
+	}
+}
+
+func TestClientGivesUpWhenRetryAfterExceedsMax(t *testing.T) {
+	var calls int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+
+	start := time.Now()
+	_, err := c.GetWorkspace(context.Background(), "acme", "tenant-a")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("error = %v, want a 429 APIError", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("server saw %d requests, want 1", got)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("gave up after %s, want at once", elapsed)
+	}
+}
+
+func TestClientRetriesServerErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		call      func(c *Client) error
+		wantCalls int32
+		wantErr   bool
+	}{
+		{
+			name: "GET is retried",
+			call: func(c *Client) error {
+				_, err := c.GetWorkspace(context.Background(), "acme", "tenant-a")
+				return err
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "POST is not retried",
+			call: func(c *Client) error {
+				_, err := c.CreateWorkspace(context.Background(), "acme", WorkspaceAttributes{Name: "tenant-a"})
+				return err
+			},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Write([]byte(`{"data":{"id":"ws-1","type":"workspaces","attributes":{"name":"tenant-a"}}}`))
+			}))
+
+			err := tt.call(c)
+			if tt.wantErr {
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+					t.Errorf("error = %v, want a 503 APIError", err)
+				}
+			} else if err != nil {
+				t.Errorf("error = %v", err)
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("server saw %d requests, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClientReplaysBodyOnRetry(t *testing.T) {
+	var bodies []string
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"data":{"id":"ws-1","type":"workspaces","attributes":{"name":"tenant-a"}}}`))
+	}))
+
+	if _, err := c.UpdateWorkspace(context.Background(), "acme", "tenant-a", WorkspaceAttributes{Description: "replayed"}); err != nil {
+		t.Fatalf("UpdateWorkspace: %v", err)
+	}
+	if len(bodies) != 3 {
+		t.Fatalf("server saw %d requests, want 3", len(bodies))
+	}
+	for i, body := range bodies {
+		if body == "" || body != bodies[0] {
+			t.Errorf("attempt %d sent body %q, want %q", i+1, body, bodies[0])
+		}
+	}
+}
+
+func TestAPIErrorIncludesRequestID(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-42")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"errors":[{"status":"409","title":"conflict"}]}`))
+	}))
+
+	_, err := c.LockWorkspace(context.Background(), "ws-1", "maintenance")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want *APIError", err)
+	}
+	if apiErr.RequestID != "req-42" {
+		t.Errorf("RequestID = %q, want req-42", apiErr.RequestID)
+	}
+	if want := "terraform cloud: 409 conflict (request ID req-42)"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
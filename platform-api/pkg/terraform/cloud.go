@@ -0,0 +1,295 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// configurationUploadTimeout bounds the wait for Terraform Cloud to process
+// an uploaded configuration before the run can be queued.
+const configurationUploadTimeout = 30 * time.Second
+
+// CloudExecutor runs workspaces in a Terraform Cloud organization.
+type CloudExecutor struct {
+	client       *Client
+	organization string
+	// variableSets are attached, by ID, to every workspace it ensures.
+	variableSets []string
+}
+
+func NewCloudExecutor(client *Client, organization string, variableSets []string) *CloudExecutor {
+	return &CloudExecutor{
+		client:       client,
+		organization: organization,
+		variableSets: variableSets,
+	}
+}
+
+func (e *CloudExecutor) EnsureWorkspace(ctx context.Context, spec WorkspaceSpec) (*WorkspaceInfo, error) {
+	workspace, err := e.client.GetWorkspace(ctx, e.organization, spec.Name)
+	if IsNotFound(err) {
+		workspace, err = e.client.CreateWorkspace(ctx, e.organization, WorkspaceAttributes{
+			Name:        spec.Name,
+			Description: spec.Description,
+			TagNames:    spec.Tags,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create workspace: %v", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %v", err)
+	}
+
+	for _, v := range spec.Variables {
+		if _, err := e.client.SetVariable(ctx, workspace.ID, v); err != nil {
+			return nil, fmt.Errorf("failed to set workspace variable %s: %v", v.Key, err)
+		}
+	}
+	for _, id := range e.variableSets {
+		if err := e.client.AttachVariableSet(ctx, id, workspace.ID); err != nil {
+			return nil, fmt.Errorf("failed to attach variable set %s: %v", id, err)
+		}
+	}
+
+	return &WorkspaceInfo{ID: workspace.ID, Name: workspace.Attributes.Name}, nil
+}
+
+func (e *CloudExecutor) GetWorkspace(ctx context.Context, name string) (*WorkspaceInfo, error) {
+	workspace, err := e.client.GetWorkspace(ctx, e.organization, name)
+	if err != nil {
+		return nil, err
+	}
+	return &WorkspaceInfo{ID: workspace.ID, Name: workspace.Attributes.Name}, nil
+}
+
+func (e *CloudExecutor) DeleteWorkspace(ctx context.Context, name string) error {
+	return e.client.DeleteWorkspace(ctx, e.organization, name)
+}
+
+func (e *CloudExecutor) StartRun(ctx context.Context, req RunRequest) (*RunState, error) {
+	workspace, err := e.client.GetWorkspace(ctx, e.organization, req.Workspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %v", err)
+	}
+
+	var configurationID string
+	if len(req.Files) > 0 {
+		if configurationID, err = e.uploadConfiguration(ctx, workspace.ID, req.Files); err != nil {
+			return nil, err
+		}
+	}
+
+	autoApply := req.AutoApply && !req.PlanOnly
+	run, err := e.client.CreateRun(ctx, RunOptions{
+		WorkspaceID:            workspace.ID,
+		ConfigurationVersionID: configurationID,
+		Message:                req.Message,
+		IsDestroy:              req.IsDestroy,
+		AutoApply:              &autoApply,
+		PlanOnly:               req.PlanOnly,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to queue run: %v", err)
+	}
+
+	return &RunState{
+		ID:                     run.ID,
+		WorkspaceID:            workspace.ID,
+		ConfigurationVersionID: configurationID,
+		Status:                 run.Attributes.Status,
+		URL:                    e.runURL(req.Workspace, run.ID),
+	}, nil
+}
+
+func (e *CloudExecutor) uploadConfiguration(ctx context.Context, workspaceID string, files map[string][]byte) (string, error) {
+	archive, err := Pack(files)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidConfiguration, err)
+	}
+
+	autoQueue := false
+	cv, err := e.client.CreateConfigurationVersion(ctx, workspaceID, ConfigurationVersionAttributes{AutoQueueRuns: &autoQueue})
+	if err != nil {
+		return "", fmt.Errorf("failed to create configuration version: %v", err)
+	}
+	if err := e.client.UploadConfiguration(ctx, cv.Attributes.UploadURL, archive); err != nil {
+		return "", fmt.Errorf("failed to upload configuration: %v", err)
+	}
+
+	deadline := time.Now().Add(configurationUploadTimeout)
+	for cv.Attributes.Status != ConfigurationUploaded {
+		if cv.Attributes.Status == ConfigurationErrored {
+			return "", fmt.Errorf("configuration upload failed: %s", cv.Attributes.ErrorMessage)
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("configuration version %s was not processed in time", cv.ID)
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(time.Second):
+		}
+		if cv, err = e.client.GetConfigurationVersion(ctx, cv.ID); err != nil {
+			return "", fmt.Errorf("failed to get configuration version: %v", err)
+		}
+	}
+
+	return cv.ID, nil
+}
+
+func (e *CloudExecutor) GetRun(ctx context.Context, runID string) (*RunState, error) {
+	run, err := e.client.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &RunState{
+		ID:          run.ID,
+		Status:      run.Attributes.Status,
+		HasChanges:  run.Attributes.HasChanges,
+		Confirmable: run.Attributes.Actions != nil && run.Attributes.Actions.IsConfirmable,
+		Final:       run.Final(),
+	}
+
+	// Resource counts come from the apply once it has run, else the plan.
+	if rel := run.Relationships; rel != nil {
+		if rel.Workspace != nil && rel.Workspace.Data != nil {
+			state.WorkspaceID = rel.Workspace.Data.ID
+		}
+		if rel.ConfigurationVersion != nil && rel.ConfigurationVersion.Data != nil {
+			state.ConfigurationVersionID = rel.ConfigurationVersion.Data.ID
+		}
+
+		var phase *Plan
+		if rel.Apply != nil && rel.Apply.Data != nil && (state.Status == RunApplying || state.Status == RunApplied) {
+			phase, err = e.client.GetApply(ctx, rel.Apply.Data.ID)
+		} else if rel.Plan != nil && rel.Plan.Data != nil {
+			phase, err = e.client.GetPlan(ctx, rel.Plan.Data.ID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if phase != nil {
+			state.ResourceAdditions = phase.Attributes.ResourceAdditions
+			state.ResourceChanges = phase.Attributes.ResourceChanges
+			state.ResourceDestructions = phase.Attributes.ResourceDestructions
+		}
+	}
+
+	return state, nil
+}
+
+func (e *CloudExecutor) ApplyRun(ctx context.Context, runID, comment string) error {
+	return confirmable(e.client.ApplyRun(ctx, runID, comment))
+}
+
+func (e *CloudExecutor) DiscardRun(ctx context.Context, runID, comment string) error {
+	return confirmable(e.client.DiscardRun(ctx, runID, comment))
+}
+
+// confirmable maps the API's 409 for a run that cannot be confirmed to
+// ErrNotConfirmable.
+func confirmable(err error) error {
+	if IsConflict(err) {
+		return ErrNotConfirmable
+	}
+	return err
+}
+
+func (e *CloudExecutor) RunLogs(ctx context.Context, runID string) (*RunLogs, error) {
+	run, err := e.client.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := &RunLogs{}
+	rel := run.Relationships
+	if rel == nil {
+		return logs, nil
+	}
+	if rel.Plan != nil && rel.Plan.Data != nil {
+		if logs.Plan, err = e.phaseLog(ctx, e.client.GetPlan, rel.Plan.Data.ID); err != nil {
+			return nil, err
+		}
+	}
+	if rel.Apply != nil && rel.Apply.Data != nil {
+		if logs.Apply, err = e.phaseLog(ctx, e.client.GetApply, rel.Apply.Data.ID); err != nil {
+			return nil, err
+		}
+	}
+	return logs, nil
+}
+
+func (e *CloudExecutor) phaseLog(ctx context.Context, get func(context.Context, string) (*Plan, error), id string) (string, error) {
+	phase, err := get(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if phase.Attributes.LogReadURL == "" {
+		return "", nil
+	}
+	return e.client.ReadLog(ctx, phase.Attributes.LogReadURL)
+}
+
+func (e *CloudExecutor) PlanJSON(ctx context.Context, runID string) (json.RawMessage, error) {
+	run, err := e.client.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run.Relationships == nil || run.Relationships.Plan == nil || run.Relationships.Plan.Data == nil {
+		return nil, ErrNotFound
+	}
+
+	plan, err := e.client.GetPlanJSON(ctx, run.Relationships.Plan.Data.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan) == 0 {
+		return nil, ErrNotFound
+	}
+	return plan, nil
+}
+
+func (e *CloudExecutor) Outputs(ctx context.Context, workspace string, sensitive bool) ([]Output, error) {
+	ws, err := e.client.GetWorkspace(ctx, e.organization, workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := e.client.CurrentStateVersionOutputs(ctx, ws.ID)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]Output, 0, len(remote))
+	for _, o := range remote {
+		output := Output{
+			Name:      o.Attributes.Name,
+			Type:      o.Attributes.Type,
+			Sensitive: o.Attributes.Sensitive,
+			Value:     o.Attributes.Value,
+		}
+		// The list never includes sensitive values; each has to be read
+		// on its own.
+		if o.Attributes.Sensitive && sensitive {
+			full, err := e.client.GetStateVersionOutput(ctx, o.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read output %s: %v", o.Attributes.Name, err)
+			}
+			output.Value = full.Attributes.Value
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs, nil
+}
+
+func (e *CloudExecutor) runURL(workspace, runID string) string {
+	return fmt.Sprintf("%s/app/%s/workspaces/%s/runs/%s", e.client.BaseURL,
+		url.PathEscape(e.organization), url.PathEscape(workspace), url.PathEscape(runID))
+}
@@ -0,0 +1,131 @@
+package terraform
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	ConfigurationPending  = "pending"
+	ConfigurationUploaded = "uploaded"
+	ConfigurationErrored  = "errored"
+)
+
+type ConfigurationVersion struct {
+	ID         string                         `json:"id,omitempty"`
+	Type       string                         `json:"type"`
+	Attributes ConfigurationVersionAttributes `json:"attributes"`
+}
+
+type ConfigurationVersionAttributes struct {
+	// AutoQueueRuns defaults to true in the API; set it to false to queue
+	// the run explicitly with CreateRun.
+	AutoQueueRuns *bool  `json:"auto-queue-runs,omitempty"`
+	Speculative   bool   `json:"speculative,omitempty"`
+	Status        string `json:"status,omitempty"`
+	Source        string `json:"source,omitempty"`
+	UploadURL     string `json:"upload-url,omitempty"`
+	Error         string `json:"error,omitempty"`
+	ErrorMessage  string `json:"error-message,omitempty"`
+}
+
+type configurationVersionDocument struct {
+	Data ConfigurationVersion `json:"data"`
+}
+
+func (c *Client) CreateConfigurationVersion(ctx context.Context, workspaceID string, attrs ConfigurationVersionAttributes) (*ConfigurationVersion, error) {
+	var doc configurationVersionDocument
+	body := configurationVersionDocument{Data: ConfigurationVersion{Type: "configuration-versions", Attributes: attrs}}
+	if err := c.do(ctx, http.MethodPost, "/workspaces/"+url.PathEscape(workspaceID)+"/configuration-versions", nil, body, &doc); err != nil {
+		return nil, err
+	}
+	return &doc.Data, nil
+}
+
+func (c *Client) GetConfigurationVersion(ctx context.Context, id string) (*ConfigurationVersion, error) {
+	var doc configurationVersionDocument
+	if err := c.do(ctx, http.MethodGet, "/configuration-versions/"+url.PathEscape(id), nil, nil, &doc); err != nil {
+		return nil, err
+	}
+	return &doc.Data, nil
+}
+
+// UploadConfiguration sends a tar.gz archive to a configuration version's
+// upload URL. The URL is pre-signed, so no token is sent with it.
+func (c *Client) UploadConfiguration(ctx context.Context, uploadURL string, archive io.Reader) error {
+	payload, err := io.ReadAll(archive)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration archive: %v", err)
+	}
+
+	resp, err := c.send(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/octet-stream")
+		return req, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload configuration: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return decodeError(resp)
+	}
+	return nil
+}
+
+// Pack builds the tar.gz archive of a configuration from file names and
+// contents. Names are relative paths; absolute paths and paths leaving the
+// archive root are rejected.
+func Pack(files map[string][]byte) (*bytes.Buffer, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if !validFileName(name) {
+			return nil, fmt.Errorf("invalid configuration file name %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range names {
+		header := &tar.Header{
+			Name:     path.Clean(name),
+			Mode:     0644,
+			Size:     int64(len(files[name])),
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, fmt.Errorf("failed to pack %s: %v", name, err)
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return nil, fmt.Errorf("failed to pack %s: %v", name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to pack configuration: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to pack configuration: %v", err)
+	}
+	return &buf, nil
+}
+
+func validFileName(name string) bool {
+	clean := path.Clean(name)
+	return name != "" && !path.IsAbs(clean) && clean != ".." && !strings.HasPrefix(clean, "../")
+}
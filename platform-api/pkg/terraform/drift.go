@@ -0,0 +1,42 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DriftedResource is a managed resource whose real state no longer matches
+// the last applied state. Actions are "update" for a resource changed
+// outside Terraform and "delete" for one that no longer exists.
+type DriftedResource struct {
+	Address string   `json:"address"`
+	Type    string   `json:"type"`
+	Actions []string `json:"actions"`
+}
+
+// ResourceDrift returns the drifted resources a plan's refresh detected,
+// read from the resource_drift of `terraform show -json` output.
+func ResourceDrift(planJSON []byte) ([]DriftedResource, error) {
+	var plan struct {
+		ResourceDrift []struct {
+			Address string `json:"address"`
+			Mode    string `json:"mode"`
+			Type    string `json:"type"`
+			Change  struct {
+				Actions []string `json:"actions"`
+			} `json:"change"`
+		} `json:"resource_drift"`
+	}
+	if err := json.Unmarshal(planJSON, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %v", err)
+	}
+
+	drifted := []DriftedResource{}
+	for _, rd := range plan.ResourceDrift {
+		if rd.Mode != "managed" || len(rd.Change.Actions) == 0 || rd.Change.Actions[0] == "no-op" {
+			continue
+		}
+		drifted = append(drifted, DriftedResource{Address: rd.Address, Type: rd.Type, Actions: rd.Change.Actions})
+	}
+	return drifted, nil
+}
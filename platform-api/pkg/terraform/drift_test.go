@@ -0,0 +1,63 @@
+package terraform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResourceDrift(t *testing.T) {
+	tests := []struct {
+		name string
+		plan string
+		want []DriftedResource
+	}{
+		{"no drift section", `{"format_version":"1.2"}`, []DriftedResource{}},
+		{"empty", `{"resource_drift":[]}`, []DriftedResource{}},
+		{
+			name: "updated and deleted",
+			plan: `{"resource_drift":[
+				{"address":"aws_s3_bucket.logs","mode":"managed","type":"aws_s3_bucket","change":{"actions":["update"]}},
+				{"address":"module.db.aws_db_instance.main","mode":"managed","type":"aws_db_instance","change":{"actions":["delete"]}}
+			]}`,
+			want: []DriftedResource{
+				{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket", Actions: []string{"update"}},
+				{Address: "module.db.aws_db_instance.main", Type: "aws_db_instance", Actions: []string{"delete"}},
+			},
+		},
+		{
+			name: "no-op filtered",
+			plan: `{"resource_drift":[
+				{"address":"aws_s3_bucket.logs","mode":"managed","type":"aws_s3_bucket","change":{"actions":["no-op"]}},
+				{"address":"aws_s3_bucket.data","mode":"managed","type":"aws_s3_bucket","change":{"actions":[]}}
+			]}`,
+			want: []DriftedResource{},
+		},
+		{
+			name: "data sources skipped",
+			plan: `{"resource_drift":[
+				{"address":"data.aws_caller_identity.current","mode":"data","type":"aws_caller_identity","change":{"actions":["update"]}},
+				{"address":"aws_iam_role.app","mode":"managed","type":"aws_iam_role","change":{"actions":["update"]}}
+			]}`,
+			want: []DriftedResource{
+				{Address: "aws_iam_role.app", Type: "aws_iam_role", Actions: []string{"update"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResourceDrift([]byte(tt.plan))
+			if err != nil {
+				t.Fatalf("ResourceDrift: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResourceDrift = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceDriftInvalidPlan(t *testing.T) {
+	if _, err := ResourceDrift([]byte("not json")); err == nil {
+		t.Error("ResourceDrift accepted an invalid plan")
+	}
+}
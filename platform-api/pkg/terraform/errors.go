@@ -0,0 +1,100 @@
+package terraform
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// APIError is an error response from the API, with the entries of its
+// JSON:API errors array. RequestID is the API's X-Request-Id, which
+// Terraform support can trace.
+type APIError struct {
+	StatusCode int
+	RequestID  string
+	Errors     []ErrorObject
+}
+
+type ErrorObject struct {
+	Status string       `json:"status,omitempty"`
+	Title  string       `json:"title,omitempty"`
+	Detail string       `json:"detail,omitempty"`
+	Source *ErrorSource `json:"source,omitempty"`
+}
+
+type ErrorSource struct {
+	Pointer string `json:"pointer,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	var parts []string
+	for _, obj := range e.Errors {
+		msg := obj.Title
+		if obj.Detail != "" && obj.Detail != obj.Title {
+			if msg != "" {
+				msg += ": "
+			}
+			msg += obj.Detail
+		}
+		if obj.Source != nil && obj.Source.Pointer != "" {
+			msg += " (" + obj.Source.Pointer + ")"
+		}
+		if msg != "" {
+			parts = append(parts, msg)
+		}
+	}
+	msg := http.StatusText(e.StatusCode)
+	if len(parts) > 0 {
+		msg = strings.Join(parts, "; ")
+	}
+	if e.RequestID != "" {
+		return fmt.Sprintf("terraform cloud: %d %s (request ID %s)", e.StatusCode, msg, e.RequestID)
+	}
+	return fmt.Sprintf("terraform cloud: %d %s", e.StatusCode, msg)
+}
+
+// IsNotFound reports whether err is a 404 from the API or ErrNotFound.
+// Terraform Cloud also answers 404 when the token cannot see the resource.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound) || hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is a 409, e.g. locking a locked workspace.
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// decodeError reads an error response. Most endpoints return JSON:API error
+// objects, but some return a bare list of strings.
+func decodeError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode, RequestID: resp.Header.Get("X-Request-Id")}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	var doc struct {
+		Errors []json.RawMessage `json:"errors"`
+	}
+	if json.Unmarshal(data, &doc) != nil {
+		return apiErr
+	}
+
+	for _, raw := range doc.Errors {
+		var obj ErrorObject
+		if json.Unmarshal(raw, &obj) == nil {
+			apiErr.Errors = append(apiErr.Errors, obj)
+			continue
+		}
+		var title string
+		if json.Unmarshal(raw, &title) == nil {
+			apiErr.Errors = append(apiErr.Errors, ErrorObject{Title: title})
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,104 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+var (
+	ErrNotFound             = errors.New("not found")
+	ErrNotConfirmable       = errors.New("run is not waiting for confirmation")
+	ErrInvalidConfiguration = errors.New("invalid terraform configuration")
+)
+
+// Executor runs Terraform for named workspaces. CloudExecutor delegates to
+// Terraform Cloud; LocalExecutor runs the terraform binary on this host.
+// Runs are asynchronous: StartRun returns once the run is queued and GetRun
+// reports its progress using the Run* statuses.
+type Executor interface {
+	// EnsureWorkspace creates the workspace if needed and sets its variables.
+	EnsureWorkspace(ctx context.Context, spec WorkspaceSpec) (*WorkspaceInfo, error)
+	// GetWorkspace returns an error satisfying IsNotFound if the workspace
+	// does not exist.
+	GetWorkspace(ctx context.Context, name string) (*WorkspaceInfo, error)
+	DeleteWorkspace(ctx context.Context, name string) error
+
+	StartRun(ctx context.Context, req RunRequest) (*RunState, error)
+	GetRun(ctx context.Context, runID string) (*RunState, error)
+	// ApplyRun and DiscardRun return ErrNotConfirmable unless the run is
+	// waiting for confirmation.
+	ApplyRun(ctx context.Context, runID, comment string) error
+	DiscardRun(ctx context.Context, runID, comment string) error
+	// RunLogs returns the plan and apply logs of a run; phases that have not
+	// started have empty logs.
+	RunLogs(ctx context.Context, runID string) (*RunLogs, error)
+	// PlanJSON returns the plan in `terraform show -json` format, or an
+	// error satisfying IsNotFound before the plan has finished.
+	PlanJSON(ctx context.Context, runID string) (json.RawMessage, error)
+
+	// Outputs returns the outputs of the workspace's current state, with the
+	// values of sensitive outputs only when sensitive is set. A workspace
+	// that has never applied has no outputs.
+	Outputs(ctx context.Context, workspace string, sensitive bool) ([]Output, error)
+}
+
+// WorkspaceSpec describes a workspace and the variables every run gets.
+type WorkspaceSpec struct {
+	Name        string
+	Description string
+	Tags        []string
+	Variables   []VariableAttributes
+}
+
+type WorkspaceInfo struct {
+	ID   string
+	Name string
+}
+
+// RunRequest queues a run. Files, keyed by relative path, replace the
+// workspace configuration; without them the latest configuration runs again.
+// A PlanOnly run stops after the plan and can never be applied.
+type RunRequest struct {
+	Workspace string
+	Files     map[string][]byte
+	Message   string
+	IsDestroy bool
+	AutoApply bool
+	PlanOnly  bool
+}
+
+type RunState struct {
+	ID                     string `json:"id"`
+	WorkspaceID            string `json:"workspace_id"`
+	ConfigurationVersionID string `json:"configuration_version_id,omitempty"`
+	Status                 string `json:"status"`
+	HasChanges             bool   `json:"has_changes"`
+	Confirmable            bool   `json:"confirmable"`
+	Final                  bool   `json:"final"`
+	ResourceAdditions      int    `json:"resource_additions"`
+	ResourceChanges        int    `json:"resource_changes"`
+	ResourceDestructions   int    `json:"resource_destructions"`
+	URL                    string `json:"url,omitempty"`
+}
+
+type RunLogs struct {
+	Plan  string `json:"plan"`
+	Apply string `json:"apply"`
+}
+
+type Output struct {
+	Name      string
+	Type      string
+	Sensitive bool
+	Value     json.RawMessage
+}
+
+// IsFinal reports whether a run in the status will not change again.
+func IsFinal(status string) bool {
+	switch status {
+	case RunApplied, RunPlannedAndFinished, RunErrored, RunDiscarded, RunCanceled, RunForceCanceled:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,857 @@
+package terraform
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// localRunTimeout bounds a single terraform command.
+	localRunTimeout = time.Hour
+
+	// localPlanFile is the saved plan, kept in the run's working directory
+	// where the workspace's user can write it.
+	localPlanFile = "zz_platform.tfplan"
+)
+
+var localNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// LocalExecutor runs the terraform binary on this host. Everything lives
+// under Dir:
+//
+//	workspaces/<name>/workspace.json  variables and metadata
+//	workspaces/<name>/config/         latest configuration
+//	workspaces/<name>/outputs.json    outputs after the last apply
+//	workspaces/<name>/state/          local backend state
+//	workspaces/<name>/home/           HOME of the workspace's commands
+//	workspaces/<name>/plugins/        provider plugin cache
+//	runs/<id>/run.json                run state
+//	runs/<id>/work/                   configuration snapshot the run executes
+//	runs/<id>/{plan,apply}.log        command output
+//	runs/<id>/plan.json               `terraform show -json` of the plan
+//
+// Each run gets its own working directory initialised against Backend; with
+// the default local backend, state is kept in the workspace's state
+// directory. BackendConfig entries are passed to `terraform init
+// -backend-config`, with {workspace} replaced by the workspace name. Runs of
+// a workspace execute one at a time; a run left unfinished by a previous
+// process is reported as errored.
+//
+// Commands see only PATH, the host's TF_* variables and the workspace's own
+// environment variables. Configuration is still arbitrary code running on
+// this host, with whatever credentials the host holds, such as a mounted
+// service account token, so the executor is only for development. With
+// UIDBase set, each workspace is given its own user ID from UIDBase up and
+// its commands run as that user; only its working, state, home and plugin
+// cache directories belong to it, so one workspace cannot read another's
+// state or variables, or plant a provider for it. That needs the server to
+// run as root and stops at the filesystem: runs share the host's network and
+// kernel.
+type LocalExecutor struct {
+	Binary        string
+	Dir           string
+	Backend       string
+	BackendConfig []string
+	UIDBase       int
+
+	mu sync.Mutex
+	// active counts the goroutines executing each run; confirming a run
+	// starts a new one before the planning one has returned.
+	active map[string]int
+	locks  map[string]*sync.Mutex
+}
+
+func NewLocalExecutor(binary, dir, backend string, backendConfig []string, uidBase int) *LocalExecutor {
+	if binary == "" {
+		binary = "terraform"
+	}
+	if backend == "" {
+		backend = "local"
+	}
+	return &LocalExecutor{
+		Binary:        binary,
+		Dir:           dir,
+		Backend:       backend,
+		BackendConfig: backendConfig,
+		UIDBase:       uidBase,
+		active:        make(map[string]int),
+		locks:         make(map[string]*sync.Mutex),
+	}
+}
+
+type localWorkspace struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Tags        []string             `json:"tags"`
+	Variables   []VariableAttributes `json:"variables"`
+	UID         int                  `json:"uid,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+}
+
+type localRun struct {
+	RunState
+	Workspace string    `json:"workspace"`
+	Message   string    `json:"message"`
+	IsDestroy bool      `json:"is_destroy"`
+	AutoApply bool      `json:"auto_apply"`
+	PlanOnly  bool      `json:"plan_only"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (e *LocalExecutor) EnsureWorkspace(ctx context.Context, spec WorkspaceSpec) (*WorkspaceInfo, error) {
+	if !localNamePattern.MatchString(spec.Name) {
+		return nil, fmt.Errorf("invalid workspace name %q", spec.Name)
+	}
+
+	ws := localWorkspace{CreatedAt: time.Now()}
+	if err := readJSON(e.workspacePath(spec.Name, "workspace.json"), &ws); err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	ws.Name = spec.Name
+	ws.Description = spec.Description
+	ws.Tags = spec.Tags
+	ws.Variables = spec.Variables
+
+	// The user ID is allocated and saved under the lock so two new
+	// workspaces never share one.
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.UIDBase > 0 && ws.UID == 0 {
+		uid, err := e.allocateUID()
+		if err != nil {
+			return nil, err
+		}
+		ws.UID = uid
+	}
+	if err := e.mkdir(e.workspacePath(spec.Name)); err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %v", err)
+	}
+	if err := writeJSON(e.workspacePath(spec.Name, "workspace.json"), ws); err != nil {
+		return nil, err
+	}
+	return &WorkspaceInfo{ID: spec.Name, Name: spec.Name}, nil
+}
+
+// allocateUID returns the lowest user ID from UIDBase that no workspace
+// holds. Callers hold e.mu.
+func (e *LocalExecutor) allocateUID() (int, error) {
+	entries, err := os.ReadDir(filepath.Join(e.Dir, "workspaces"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, fmt.Errorf("failed to list workspaces: %v", err)
+	}
+	used := make(map[int]bool, len(entries))
+	for _, entry := range entries {
+		if ws, err := e.loadWorkspace(entry.Name()); err == nil && ws.UID > 0 {
+			used[ws.UID] = true
+		}
+	}
+	uid := e.UIDBase
+	for used[uid] {
+		uid++
+	}
+	return uid, nil
+}
+
+func (e *LocalExecutor) GetWorkspace(ctx context.Context, name string) (*WorkspaceInfo, error) {
+	ws, err := e.loadWorkspace(name)
+	if err != nil {
+		return nil, err
+	}
+	return &WorkspaceInfo{ID: ws.Name, Name: ws.Name}, nil
+}
+
+// DeleteWorkspace removes the workspace directory, including local state.
+// Resources still in that state are not destroyed.
+func (e *LocalExecutor) DeleteWorkspace(ctx context.Context, name string) error {
+	if _, err := e.loadWorkspace(name); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(e.workspacePath(name)); err != nil {
+		return fmt.Errorf("failed to delete workspace: %v", err)
+	}
+	return nil
+}
+
+func (e *LocalExecutor) StartRun(ctx context.Context, req RunRequest) (*RunState, error) {
+	ws, err := e.loadWorkspace(req.Workspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %v", err)
+	}
+
+	if len(req.Files) > 0 {
+		if err := e.replaceConfiguration(ws.Name, req.Files); err != nil {
+			return nil, err
+		}
+	}
+
+	id, err := newLocalID("run-")
+	if err != nil {
+		return nil, err
+	}
+	run := &localRun{
+		RunState: RunState{
+			ID:          id,
+			WorkspaceID: ws.Name,
+			Status:      RunPending,
+		},
+		Workspace: ws.Name,
+		Message:   req.Message,
+		IsDestroy: req.IsDestroy,
+		AutoApply: req.AutoApply && !req.PlanOnly,
+		PlanOnly:  req.PlanOnly,
+		CreatedAt: time.Now(),
+	}
+
+	// The run executes a snapshot so later uploads do not change it.
+	work := e.runPath(id, "work")
+	if err := copyDir(e.workspacePath(ws.Name, "config"), work); err != nil {
+		os.RemoveAll(e.runPath(id))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: workspace has no configuration yet", ErrInvalidConfiguration)
+		}
+		return nil, fmt.Errorf("failed to prepare run: %v", err)
+	}
+	if err := e.writeRunFiles(ws, work); err != nil {
+		os.RemoveAll(e.runPath(id))
+		return nil, err
+	}
+	if err := e.prepareRun(ws, id); err != nil {
+		os.RemoveAll(e.runPath(id))
+		return nil, err
+	}
+
+	e.mu.Lock()
+	err = e.saveRun(run)
+	if err == nil {
+		e.active[id]++
+	}
+	e.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	go e.execute(id, true)
+
+	state := run.RunState
+	return &state, nil
+}
+
+func (e *LocalExecutor) GetRun(ctx context.Context, runID string) (*RunState, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	run, err := e.loadRun(runID)
+	if err != nil {
+		return nil, err
+	}
+	if !run.Final && !run.Confirmable && e.active[runID] == 0 {
+		run.Status = RunErrored
+		run.Final = true
+		run.Error = "run was interrupted"
+		if err := e.saveRun(run); err != nil {
+			return nil, err
+		}
+	}
+	state := run.RunState
+	return &state, nil
+}
+
+func (e *LocalExecutor) ApplyRun(ctx context.Context, runID, comment string) error {
+	if err := e.confirm(runID, RunConfirmed); err != nil {
+		return err
+	}
+	go e.execute(runID, false)
+	return nil
+}
+
+func (e *LocalExecutor) DiscardRun(ctx context.Context, runID, comment string) error {
+	return e.confirm(runID, RunDiscarded)
+}
+
+// confirm moves a run waiting for confirmation to status. A confirmed run is
+// counted as active before the lock is released so GetRun does not report it
+// as interrupted, even while the goroutine that planned it is still
+// finishing.
+func (e *LocalExecutor) confirm(runID, status string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	run, err := e.loadRun(runID)
+	if err != nil {
+		return err
+	}
+	if !run.Confirmable {
+		return ErrNotConfirmable
+	}
+	run.Confirmable = false
+	run.Status = status
+	run.Final = IsFinal(status)
+	if err := e.saveRun(run); err != nil {
+		return err
+	}
+	if !run.Final {
+		e.active[runID]++
+	}
+	return nil
+}
+
+func (e *LocalExecutor) RunLogs(ctx context.Context, runID string) (*RunLogs, error) {
+	if _, err := e.loadRun(runID); err != nil {
+		return nil, err
+	}
+	plan, err := readOptional(e.runPath(runID, "plan.log"))
+	if err != nil {
+		return nil, err
+	}
+	apply, err := readOptional(e.runPath(runID, "apply.log"))
+	if err != nil {
+		return nil, err
+	}
+	return &RunLogs{Plan: string(plan), Apply: string(apply)}, nil
+}
+
+func (e *LocalExecutor) PlanJSON(ctx context.Context, runID string) (json.RawMessage, error) {
+	if _, err := e.loadRun(runID); err != nil {
+		return nil, err
+	}
+	plan, err := os.ReadFile(e.runPath(runID, "plan.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %v", err)
+	}
+	return plan, nil
+}
+
+func (e *LocalExecutor) Outputs(ctx context.Context, workspace string, sensitive bool) ([]Output, error) {
+	if _, err := e.loadWorkspace(workspace); err != nil {
+		return nil, err
+	}
+
+	var raw map[string]struct {
+		Sensitive bool            `json:"sensitive"`
+		Type      json.RawMessage `json:"type"`
+		Value     json.RawMessage `json:"value"`
+	}
+	if err := readJSON(e.workspacePath(workspace, "outputs.json"), &raw); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	outputs := make([]Output, 0, len(raw))
+	for name, o := range raw {
+		output := Output{Name: name, Sensitive: o.Sensitive, Value: o.Value}
+		// Types are type expressions: "string", or e.g. ["list","string"].
+		if json.Unmarshal(o.Type, &output.Type) != nil {
+			output.Type = string(o.Type)
+		}
+		if o.Sensitive && !sensitive {
+			output.Value = nil
+		}
+		outputs = append(outputs, output)
+	}
+	sort.Slice(outputs, func(i, j int) bool { return outputs[i].Name < outputs[j].Name })
+	return outputs, nil
+}
+
+// execute runs the plan phase of a new run, or the apply phase of a
+// confirmed one, holding the workspace lock.
+func (e *LocalExecutor) execute(runID string, plan bool) {
+	defer func() {
+		e.mu.Lock()
+		if e.active[runID]--; e.active[runID] <= 0 {
+			delete(e.active, runID)
+		}
+		e.mu.Unlock()
+	}()
+
+	run, err := e.loadRun(runID)
+	if err != nil {
+		return
+	}
+
+	lock := e.workspaceLock(run.Workspace)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if plan {
+		err = e.plan(run)
+	}
+	if err == nil && (run.Status == RunConfirmed || run.AutoApply && run.Status == RunPlanned) {
+		err = e.apply(run)
+	}
+	if err != nil {
+		run.Status = RunErrored
+		run.Confirmable = false
+		run.Final = true
+		run.Error = err.Error()
+		e.update(run)
+	}
+}
+
+func (e *LocalExecutor) plan(run *localRun) error {
+	ws, err := e.loadWorkspace(run.Workspace)
+	if err != nil {
+		return err
+	}
+
+	run.Status = RunPlanning
+	e.update(run)
+
+	logFile, err := os.Create(e.runPath(run.ID, "plan.log"))
+	if err != nil {
+		return fmt.Errorf("failed to create plan log: %v", err)
+	}
+	defer logFile.Close()
+
+	initArgs := []string{"init", "-input=false", "-no-color"}
+	for _, setting := range e.backendConfig(run.Workspace) {
+		initArgs = append(initArgs, "-backend-config="+setting)
+	}
+	if _, err := e.terraform(ws, run.ID, nil, logFile, initArgs...); err != nil {
+		return err
+	}
+
+	planArgs := []string{"plan", "-input=false", "-no-color", "-detailed-exitcode", "-out=" + localPlanFile}
+	if run.IsDestroy {
+		planArgs = append(planArgs, "-destroy")
+	}
+	code, err := e.terraform(ws, run.ID, nil, logFile, planArgs...)
+	if err != nil && code != 2 {
+		return err
+	}
+	run.HasChanges = code == 2
+
+	var planJSON bytes.Buffer
+	if _, err := e.terraform(ws, run.ID, &planJSON, logFile, "show", "-json", localPlanFile); err != nil {
+		return err
+	}
+	if err := os.WriteFile(e.runPath(run.ID, "plan.json"), planJSON.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to save plan: %v", err)
+	}
+	run.ResourceAdditions, run.ResourceChanges, run.ResourceDestructions = countChanges(planJSON.Bytes())
+
+	if !run.HasChanges || run.PlanOnly {
+		run.Status = RunPlannedAndFinished
+		run.Final = true
+		e.update(run)
+		return nil
+	}
+
+	run.Status = RunPlanned
+	run.Confirmable = !run.AutoApply
+	e.update(run)
+	return nil
+}
+
+func (e *LocalExecutor) apply(run *localRun) error {
+	ws, err := e.loadWorkspace(run.Workspace)
+	if err != nil {
+		return err
+	}
+
+	run.Status = RunApplying
+	run.Confirmable = false
+	e.update(run)
+
+	logFile, err := os.Create(e.runPath(run.ID, "apply.log"))
+	if err != nil {
+		return fmt.Errorf("failed to create apply log: %v", err)
+	}
+	defer logFile.Close()
+
+	if _, err := e.terraform(ws, run.ID, nil, logFile, "apply", "-input=false", "-no-color", localPlanFile); err != nil {
+		return err
+	}
+
+	var outputs bytes.Buffer
+	if _, err := e.terraform(ws, run.ID, &outputs, logFile, "output", "-json"); err != nil {
+		return err
+	}
+	if err := os.WriteFile(e.workspacePath(run.Workspace, "outputs.json"), outputs.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to save outputs: %v", err)
+	}
+
+	run.Status = RunApplied
+	run.Final = true
+	e.update(run)
+	return nil
+}
+
+// terraform runs one command in the run's working directory and returns its
+// exit code. Diagnostics go to log; stdout goes to out, or to log if out is
+// nil.
+func (e *LocalExecutor) terraform(ws *localWorkspace, runID string, out, log io.Writer, args ...string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), localRunTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, e.Binary, args...)
+	cmd.Dir = e.runPath(runID, "work")
+	cmd.Env = e.commandEnv(ws)
+	cmd.Stdout = out
+	if out == nil {
+		cmd.Stdout = log
+	}
+	cmd.Stderr = log
+	if ws.UID > 0 {
+		if err := runAs(cmd, ws.UID); err != nil {
+			return -1, err
+		}
+	}
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), fmt.Errorf("terraform %s exited with status %d", args[0], exitErr.ExitCode())
+	}
+	if err != nil {
+		return -1, fmt.Errorf("failed to run terraform %s: %v", args[0], err)
+	}
+	return 0, nil
+}
+
+// commandEnv is the whole environment of a workspace's commands: PATH and
+// TF_* from the host, the workspace's environment variables, then the
+// settings the executor relies on, which the workspace cannot override.
+func (e *LocalExecutor) commandEnv(ws *localWorkspace) []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, "PATH=") || strings.HasPrefix(kv, "TF_") {
+			env = append(env, kv)
+		}
+	}
+	env = append(env, workspaceEnv(ws)...)
+
+	return append(env,
+		"HOME="+e.workspacePath(ws.Name, "home"),
+		"TF_IN_AUTOMATION=1",
+		"TF_PLUGIN_CACHE_DIR="+e.workspacePath(ws.Name, "plugins"),
+	)
+}
+
+// prepareRun creates the workspace's own directories and, when workspaces
+// run as their own users, hands them and the run's working directory to the
+// workspace's user. Everything else under Dir stays private to the server.
+func (e *LocalExecutor) prepareRun(ws *localWorkspace, runID string) error {
+	if err := e.mkdir(e.workspacePath(ws.Name)); err != nil {
+		return fmt.Errorf("failed to prepare workspace: %v", err)
+	}
+	if err := e.mkdir(e.runPath(runID)); err != nil {
+		return fmt.Errorf("failed to prepare run: %v", err)
+	}
+	owned := []string{e.workspacePath(ws.Name, "state"), e.workspacePath(ws.Name, "home"), e.workspacePath(ws.Name, "plugins")}
+	for _, dir := range owned {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("failed to prepare workspace: %v", err)
+		}
+	}
+
+	// State used to be kept directly in the workspace directory.
+	legacy := e.workspacePath(ws.Name, "terraform.tfstate")
+	if _, err := os.Stat(legacy); err == nil {
+		if err := os.Rename(legacy, e.workspacePath(ws.Name, "state", "terraform.tfstate")); err != nil {
+			return fmt.Errorf("failed to move state: %v", err)
+		}
+	}
+
+	if ws.UID == 0 {
+		return nil
+	}
+	if e.UIDBase == 0 {
+		return fmt.Errorf("workspace %s runs as user %d but user isolation is not enabled", ws.Name, ws.UID)
+	}
+	// The workspace's own directories are only chowned at the top: their
+	// contents are the workspace user's, who could have swapped a
+	// directory for a link elsewhere. The working directory is new and was
+	// written by the server alone.
+	for _, dir := range owned {
+		if err := os.Lchown(dir, ws.UID, ws.UID); err != nil {
+			return fmt.Errorf("failed to prepare workspace: %v", err)
+		}
+	}
+	if err := chownTree(e.runPath(runID, "work"), ws.UID); err != nil {
+		return fmt.Errorf("failed to prepare run: %v", err)
+	}
+	return nil
+}
+
+// mkdir creates a directory the server owns. When workspaces run as their
+// own users it and its parents under Dir can be traversed, but not listed,
+// by them so they reach the directories they own.
+func (e *LocalExecutor) mkdir(path string) error {
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return err
+	}
+	if e.UIDBase == 0 {
+		return nil
+	}
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if err := os.Chmod(dir, 0711); err != nil {
+			return err
+		}
+		if dir == filepath.Clean(e.Dir) || dir == filepath.Dir(dir) {
+			return nil
+		}
+	}
+}
+
+func chownTree(root string, uid int) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, uid, uid)
+	})
+}
+
+// writeRunFiles adds the backend override and the workspace's Terraform
+// variables to a run's working directory.
+func (e *LocalExecutor) writeRunFiles(ws *localWorkspace, work string) error {
+	backend := fmt.Sprintf("terraform {\n  backend %q {}\n}\n", e.Backend)
+	if err := os.WriteFile(filepath.Join(work, "zz_platform_backend_override.tf"), []byte(backend), 0600); err != nil {
+		return fmt.Errorf("failed to write backend configuration: %v", err)
+	}
+
+	var vars bytes.Buffer
+	for _, v := range ws.Variables {
+		if v.Category != CategoryTerraform {
+			continue
+		}
+		value := v.Value
+		if !v.HCL {
+			quoted, _ := json.Marshal(v.Value)
+			value = strings.NewReplacer("${", "$${", "%{", "%%{").Replace(string(quoted))
+		}
+		fmt.Fprintf(&vars, "%s = %s\n", v.Key, value)
+	}
+	if err := os.WriteFile(filepath.Join(work, "zz_platform.auto.tfvars"), vars.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write variables: %v", err)
+	}
+	return nil
+}
+
+func (e *LocalExecutor) backendConfig(workspace string) []string {
+	if e.Backend == "local" && len(e.BackendConfig) == 0 {
+		return []string{"path=" + e.workspacePath(workspace, "state", "terraform.tfstate")}
+	}
+	settings := make([]string, 0, len(e.BackendConfig))
+	for _, setting := range e.BackendConfig {
+		settings = append(settings, strings.ReplaceAll(setting, "{workspace}", workspace))
+	}
+	return settings
+}
+
+// replaceConfiguration swaps in a new configuration for the workspace.
+func (e *LocalExecutor) replaceConfiguration(workspace string, files map[string][]byte) error {
+	staging := e.workspacePath(workspace, "config.new")
+	os.RemoveAll(staging)
+	for name, content := range files {
+		if !validFileName(name) {
+			os.RemoveAll(staging)
+			return fmt.Errorf("%w: invalid configuration file name %q", ErrInvalidConfiguration, name)
+		}
+		target := filepath.Join(staging, filepath.FromSlash(filepath.Clean(name)))
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return fmt.Errorf("failed to write configuration: %v", err)
+		}
+		if err := os.WriteFile(target, content, 0600); err != nil {
+			return fmt.Errorf("failed to write configuration: %v", err)
+		}
+	}
+
+	config := e.workspacePath(workspace, "config")
+	if err := os.RemoveAll(config); err != nil {
+		return fmt.Errorf("failed to replace configuration: %v", err)
+	}
+	if err := os.Rename(staging, config); err != nil {
+		return fmt.Errorf("failed to replace configuration: %v", err)
+	}
+	return nil
+}
+
+func (e *LocalExecutor) loadWorkspace(name string) (*localWorkspace, error) {
+	if !localNamePattern.MatchString(name) {
+		return nil, ErrNotFound
+	}
+	var ws localWorkspace
+	if err := readJSON(e.workspacePath(name, "workspace.json"), &ws); err != nil {
+		return nil, err
+	}
+	return &ws, nil
+}
+
+func (e *LocalExecutor) loadRun(id string) (*localRun, error) {
+	if !localNamePattern.MatchString(id) {
+		return nil, ErrNotFound
+	}
+	var run localRun
+	if err := readJSON(e.runPath(id, "run.json"), &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (e *LocalExecutor) saveRun(run *localRun) error {
+	if err := os.MkdirAll(e.runPath(run.ID), 0700); err != nil {
+		return fmt.Errorf("failed to save run: %v", err)
+	}
+	return writeJSON(e.runPath(run.ID, "run.json"), run)
+}
+
+// update saves a run from its executing goroutine.
+func (e *LocalExecutor) update(run *localRun) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.saveRun(run); err != nil {
+		run.Error = err.Error()
+	}
+}
+
+func (e *LocalExecutor) workspaceLock(name string) *sync.Mutex {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	lock, ok := e.locks[name]
+	if !ok {
+		lock = &sync.Mutex{}
+		e.locks[name] = lock
+	}
+	return lock
+}
+
+func (e *LocalExecutor) workspacePath(name string, elem ...string) string {
+	return filepath.Join(append([]string{e.Dir, "workspaces", name}, elem...)...)
+}
+
+func (e *LocalExecutor) runPath(id string, elem ...string) string {
+	return filepath.Join(append([]string{e.Dir, "runs", id}, elem...)...)
+}
+
+// workspaceEnv returns the workspace's environment variables as KEY=value.
+func workspaceEnv(ws *localWorkspace) []string {
+	var env []string
+	for _, v := range ws.Variables {
+		if v.Category == CategoryEnv {
+			env = append(env, v.Key+"="+v.Value)
+		}
+	}
+	return env
+}
+
+// countChanges counts planned additions, changes and destructions the way
+// Terraform Cloud does: a replacement is both an addition and a destruction.
+func countChanges(planJSON []byte) (additions, changes, destructions int) {
+	var plan struct {
+		ResourceChanges []struct {
+			Change struct {
+				Actions []string `json:"actions"`
+			} `json:"change"`
+		} `json:"resource_changes"`
+	}
+	if json.Unmarshal(planJSON, &plan) != nil {
+		return 0, 0, 0
+	}
+	for _, rc := range plan.ResourceChanges {
+		for _, action := range rc.Change.Actions {
+			switch action {
+			case "create":
+				additions++
+			case "update":
+				changes++
+			case "delete":
+				destructions++
+			}
+		}
+	}
+	return additions, changes, destructions
+}
+
+func copyDir(src, dst string) error {
+	if _, err := os.Stat(src); err != nil {
+		return err
+	}
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0700)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0600)
+	})
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", filepath.Base(path), err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode %s: %v", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// writeJSON replaces path atomically so readers never see a partial file.
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %v", filepath.Base(path), err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %v", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write %s: %v", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func readOptional(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", filepath.Base(path), err)
+	}
+	return data, nil
+}
+
+func newLocalID(prefix string) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate ID: %v", err)
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package terraform
+
+import (
+	"errors"
+	"os/exec"
+)
+
+func runAs(cmd *exec.Cmd, uid int) error {
+	return errors.New("running workspaces as their own users is not supported on this platform")
+}
@@ -0,0 +1,317 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubTerraform records each command in $HOME/calls and answers like
+// terraform would. STUB_PLAN_EXIT sets the plan's -detailed-exitcode.
+const stubTerraform = `#!/bin/sh
+echo "$*" >> "$HOME/calls"
+case "$1" in
+init)
+	echo "Terraform has been successfully initialized!"
+	;;
+plan)
+	echo "Plan: stub"
+	touch zz_platform.tfplan
+	exit "${STUB_PLAN_EXIT:-2}"
+	;;
+show)
+	cat <<'EOF'
+{"resource_changes":[
+	{"address":"a.new","change":{"actions":["create"]}},
+	{"address":"a.changed","change":{"actions":["update"]}},
+	{"address":"a.replaced","change":{"actions":["delete","create"]}},
+	{"address":"a.same","change":{"actions":["no-op"]}}
+]}
+EOF
+	;;
+apply)
+	echo "Apply complete!"
+	;;
+output)
+	echo '{"url":{"sensitive":false,"type":"string","value":"https://app"},"password":{"sensitive":true,"type":"string","value":"hunter2"}}'
+	;;
+esac
+`
+
+func newTestLocalExecutor(t *testing.T) *LocalExecutor {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub terraform is a shell script")
+	}
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "terraform"), []byte(stubTerraform), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return NewLocalExecutor("", t.TempDir(), "", nil, 0)
+}
+
+// startLocalRun creates workspace "tenant-a" with the variables and starts a
+// run of a one-file configuration.
+func startLocalRun(t *testing.T, e *LocalExecutor, req RunRequest, vars ...VariableAttributes) *RunState {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := e.EnsureWorkspace(ctx, WorkspaceSpec{Name: "tenant-a", Variables: vars}); err != nil {
+		t.Fatalf("EnsureWorkspace: %v", err)
+	}
+	req.Workspace = "tenant-a"
+	req.Files = map[string][]byte{"main.tf": []byte(`resource "null_resource" "a" {}`)}
+	run, err := e.StartRun(ctx, req)
+	if err != nil {
+		t.Fatalf("StartRun: %v", err)
+	}
+	return run
+}
+
+// waitForRun polls the run until done reports true for it.
+func waitForRun(t *testing.T, e *LocalExecutor, id string, done func(*RunState) bool) *RunState {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		run, err := e.GetRun(context.Background(), id)
+		if err != nil {
+			t.Fatalf("GetRun: %v", err)
+		}
+		if done(run) {
+			return run
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("run stuck in %s", run.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func settled(run *RunState) bool { return run.Final || run.Confirmable }
+
+func TestLocalExecutorPlanAndApply(t *testing.T) {
+	e := newTestLocalExecutor(t)
+	ctx := context.Background()
+	run := startLocalRun(t, e, RunRequest{Message: "test"},
+		VariableAttributes{Key: "greeting", Value: "${oops}", Category: CategoryTerraform},
+		VariableAttributes{Key: "STUB_PLAN_EXIT", Value: "2", Category: CategoryEnv},
+	)
+
+	run = waitForRun(t, e, run.ID, settled)
+	if run.Status != RunPlanned || !run.Confirmable || !run.HasChanges {
+		t.Fatalf("after plan got %+v", run)
+	}
+	if run.ResourceAdditions != 2 || run.ResourceChanges != 1 || run.ResourceDestructions != 1 {
+		t.Errorf("changes = +%d ~%d -%d, want +2 ~1 -1", run.ResourceAdditions, run.ResourceChanges, run.ResourceDestructions)
+	}
+
+	vars, err := os.ReadFile(e.runPath(run.ID, "work", "zz_platform.auto.tfvars"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(vars) != "greeting = \"$${oops}\"\n" {
+		t.Errorf("tfvars = %q", vars)
+	}
+	plan, err := e.PlanJSON(ctx, run.ID)
+	if err != nil || !json.Valid(plan) {
+		t.Fatalf("PlanJSON = %s, %v", plan, err)
+	}
+
+	if err := e.ApplyRun(ctx, run.ID, ""); err != nil {
+		t.Fatalf("ApplyRun: %v", err)
+	}
+	run = waitForRun(t, e, run.ID, func(r *RunState) bool { return r.Final })
+	if run.Status != RunApplied {
+		t.Fatalf("after apply got %+v", run)
+	}
+
+	logs, err := e.RunLogs(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("RunLogs: %v", err)
+	}
+	if !strings.Contains(logs.Plan, "Plan: stub") || !strings.Contains(logs.Apply, "Apply complete!") {
+		t.Errorf("logs = %+v", logs)
+	}
+
+	outputs, err := e.Outputs(ctx, "tenant-a", false)
+	if err != nil {
+		t.Fatalf("Outputs: %v", err)
+	}
+	if len(outputs) != 2 || outputs[0].Name != "password" || outputs[0].Value != nil || string(outputs[1].Value) != `"https://app"` {
+		t.Errorf("outputs = %+v", outputs)
+	}
+}
+
+func TestLocalExecutorDiscard(t *testing.T) {
+	e := newTestLocalExecutor(t)
+	ctx := context.Background()
+	run := waitForRun(t, e, startLocalRun(t, e, RunRequest{}).ID, settled)
+	if !run.Confirmable {
+		t.Fatalf("after plan got %+v", run)
+	}
+
+	if err := e.DiscardRun(ctx, run.ID, ""); err != nil {
+		t.Fatalf("DiscardRun: %v", err)
+	}
+	run, err := e.GetRun(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("GetRun: %v", err)
+	}
+	if run.Status != RunDiscarded || !run.Final {
+		t.Errorf("after discard got %+v", run)
+	}
+	if err := e.ApplyRun(ctx, run.ID, ""); !errors.Is(err, ErrNotConfirmable) {
+		t.Errorf("ApplyRun after discard = %v, want ErrNotConfirmable", err)
+	}
+	if _, err := os.Stat(e.runPath(run.ID, "apply.log")); !os.IsNotExist(err) {
+		t.Errorf("discarded run was applied")
+	}
+}
+
+func TestLocalExecutorPlanOutcomes(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      RunRequest
+		exitCode string
+		status   string
+	}{
+		{"no changes", RunRequest{}, "0", RunPlannedAndFinished},
+		{"plan only", RunRequest{PlanOnly: true, AutoApply: true}, "2", RunPlannedAndFinished},
+		{"auto apply", RunRequest{AutoApply: true}, "2", RunApplied},
+		{"plan fails", RunRequest{}, "1", RunErrored},
+		{"destroy", RunRequest{IsDestroy: true, AutoApply: true}, "2", RunApplied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestLocalExecutor(t)
+			run := startLocalRun(t, e, tt.req, VariableAttributes{Key: "STUB_PLAN_EXIT", Value: tt.exitCode, Category: CategoryEnv})
+			run = waitForRun(t, e, run.ID, func(r *RunState) bool { return r.Final })
+			if run.Status != tt.status {
+				t.Errorf("status = %s, want %s", run.Status, tt.status)
+			}
+
+			calls, err := os.ReadFile(e.workspacePath("tenant-a", "home", "calls"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var plan string
+			for _, line := range strings.Split(string(calls), "\n") {
+				if strings.HasPrefix(line, "plan ") {
+					plan = line
+				}
+			}
+			if strings.Contains(plan, "-destroy") != tt.req.IsDestroy {
+				t.Errorf("plan command = %q", plan)
+			}
+		})
+	}
+}
+
+func TestLocalExecutorGetRunReportsInterruptedRuns(t *testing.T) {
+	e := NewLocalExecutor("", t.TempDir(), "", nil, 0)
+	ctx := context.Background()
+	runs := []*localRun{
+		{RunState: RunState{ID: "run-planning", Status: RunPlanning}},
+		{RunState: RunState{ID: "run-waiting", Status: RunPlanned, Confirmable: true}},
+		{RunState: RunState{ID: "run-done", Status: RunApplied, Final: true}},
+	}
+	for _, run := range runs {
+		if err := e.saveRun(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string]string{
+		"run-planning": RunErrored,
+		"run-waiting":  RunPlanned,
+		"run-done":     RunApplied,
+	}
+	for id, want := range tests {
+		run, err := e.GetRun(ctx, id)
+		if err != nil {
+			t.Fatalf("GetRun(%s): %v", id, err)
+		}
+		if run.Status != want {
+			t.Errorf("GetRun(%s).Status = %s, want %s", id, run.Status, want)
+		}
+	}
+	saved, err := e.loadRun("run-planning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved.Final || saved.Error != "run was interrupted" {
+		t.Errorf("interrupted run saved as %+v", saved)
+	}
+
+	// A run still executing in this process is not interrupted.
+	if err := e.saveRun(&localRun{RunState: RunState{ID: "run-active", Status: RunApplying}}); err != nil {
+		t.Fatal(err)
+	}
+	e.active["run-active"] = 1
+	if run, err := e.GetRun(ctx, "run-active"); err != nil || run.Status != RunApplying {
+		t.Errorf("GetRun(run-active) = %+v, %v", run, err)
+	}
+
+	if _, err := e.GetRun(ctx, "../run-done"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetRun with a path = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLocalExecutorReplaceConfiguration(t *testing.T) {
+	e := NewLocalExecutor("", t.TempDir(), "", nil, 0)
+	if err := e.replaceConfiguration("tenant-a", map[string][]byte{
+		"main.tf":              []byte("# main"),
+		"modules/app/main.tf":  []byte("# module"),
+		"./modules/../vars.tf": []byte("# vars"),
+	}); err != nil {
+		t.Fatalf("replaceConfiguration: %v", err)
+	}
+	for _, name := range []string{"main.tf", "modules/app/main.tf", "vars.tf"} {
+		if _, err := os.Stat(filepath.Join(e.workspacePath("tenant-a", "config"), filepath.FromSlash(name))); err != nil {
+			t.Errorf("%s not written: %v", name, err)
+		}
+	}
+
+	for _, name := range []string{"", "..", "../escape.tf", "modules/../../escape.tf", "/etc/escape.tf"} {
+		err := e.replaceConfiguration("tenant-a", map[string][]byte{name: []byte("# bad")})
+		if !errors.Is(err, ErrInvalidConfiguration) {
+			t.Errorf("replaceConfiguration(%q) = %v, want ErrInvalidConfiguration", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(e.Dir, "workspaces", "escape.tf")); !os.IsNotExist(err) {
+		t.Errorf("file written outside the configuration")
+	}
+	if _, err := os.Stat(filepath.Join(e.workspacePath("tenant-a", "config"), "main.tf")); err != nil {
+		t.Errorf("rejected upload replaced the configuration: %v", err)
+	}
+}
+
+func TestCountChanges(t *testing.T) {
+	tests := []struct {
+		name                             string
+		plan                             string
+		additions, changes, destructions int
+	}{
+		{"invalid", `not json`, 0, 0, 0},
+		{"empty", `{}`, 0, 0, 0},
+		{"create", `{"resource_changes":[{"change":{"actions":["create"]}}]}`, 1, 0, 0},
+		{"update", `{"resource_changes":[{"change":{"actions":["update"]}}]}`, 0, 1, 0},
+		{"delete", `{"resource_changes":[{"change":{"actions":["delete"]}}]}`, 0, 0, 1},
+		{"replace", `{"resource_changes":[{"change":{"actions":["delete","create"]}},{"change":{"actions":["create","delete"]}}]}`, 2, 0, 2},
+		{"no-op and read", `{"resource_changes":[{"change":{"actions":["no-op"]}},{"change":{"actions":["read"]}}]}`, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, c, d := countChanges([]byte(tt.plan))
+			if a != tt.additions || c != tt.changes || d != tt.destructions {
+				t.Errorf("countChanges = +%d ~%d -%d, want +%d ~%d -%d", a, c, d, tt.additions, tt.changes, tt.destructions)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package terraform
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// runAs makes cmd run as uid, with the group of the same ID and no
+// supplementary groups.
+func runAs(cmd *exec.Cmd, uid int) error {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Credential: &syscall.Credential{Uid: uint32(uid), Gid: uint32(uid)},
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Run statuses. A run stops in one of the final statuses, or waits for
+// ApplyRun or DiscardRun while RunActions.IsConfirmable is set.
+const (
+	RunPending            = "pending"
+	RunPlanning           = "planning"
+	RunPlanned            = "planned"
+	RunCostEstimated      = "cost_estimated"
+	RunPolicyChecked      = "policy_checked"
+	RunConfirmed          = "confirmed"
+	RunApplying           = "applying"
+	RunApplied            = "applied"
+	RunPlannedAndFinished = "planned_and_finished"
+	RunErrored            = "errored"
+	RunDiscarded          = "discarded"
+	RunCanceled           = "canceled"
+	RunForceCanceled      = "force_canceled"
+)
+
+type Run struct {
+	ID            string            `json:"id,omitempty"`
+	Type          string            `json:"type"`
+	Attributes    RunAttributes     `json:"attributes"`
+	Relationships *RunRelationships `json:"relationships,omitempty"`
+}
+
+type RunAttributes struct {
+	Message     string      `json:"message,omitempty"`
+	IsDestroy   bool        `json:"is-destroy,omitempty"`
+	AutoApply   *bool       `json:"auto-apply,omitempty"`
+	PlanOnly    bool        `json:"plan-only,omitempty"`
+	RefreshOnly bool        `json:"refresh-only,omitempty"`
+	Status      string      `json:"status,omitempty"`
+	HasChanges  bool        `json:"has-changes,omitempty"`
+	Source      string      `json:"source,omitempty"`
+	Actions     *RunActions `json:"actions,omitempty"`
+	CreatedAt   *time.Time  `json:"created-at,omitempty"`
+}
+
+type RunActions struct {
+	IsCancelable  bool `json:"is-cancelable"`
+	IsConfirmable bool `json:"is-confirmable"`
+	IsDiscardable bool `json:"is-discardable"`
+}
+
+type RunRelationships struct {
+	Workspace            *Relationship `json:"workspace,omitempty"`
+	ConfigurationVersion *Relationship `json:"configuration-version,omitempty"`
+	Plan                 *Relationship `json:"plan,omitempty"`
+	Apply                *Relationship `json:"apply,omitempty"`
+}
+
+// Final reports whether the run has stopped and will not change again.
+func (r *Run) Final() bool {
+	return IsFinal(r.Attributes.Status)
+}
+
+// RunOptions queues a run. An empty ConfigurationVersionID runs the
+// workspace's latest configuration.
+type RunOptions struct {
+	WorkspaceID            string
+	ConfigurationVersionID string
+	Message                string
+	IsDestroy              bool
+	AutoApply              *bool
+	PlanOnly               bool
+	RefreshOnly            bool
+}
+
+// Plan and Apply share their shape: the status of the phase and the resource
+// counts it reports.
+type Plan struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Attributes PhaseAttributes `json:"attributes"`
+}
+
+type Apply = Plan
+
+type PhaseAttributes struct {
+	Status               string `json:"status"`
+	HasChanges           bool   `json:"has-changes"`
+	ResourceAdditions    int    `json:"resource-additions"`
+	ResourceChanges      int    `json:"resource-changes"`
+	ResourceDestructions int    `json:"resource-destructions"`
+	LogReadURL           string `json:"log-read-url"`
+}
+
+type runDocument struct {
+	Data Run `json:"data"`
+}
+
+type planDocument struct {
+	Data Plan `json:"data"`
+}
+
+func (c *Client) CreateRun(ctx context.Context, opts RunOptions) (*Run, error) {
+	relationships := &RunRelationships{
+		Workspace: &Relationship{Data: &ResourceRef{ID: opts.WorkspaceID, Type: "workspaces"}},
+	}
+	if opts.ConfigurationVersionID != "" {
+		relationships.ConfigurationVersion = &Relationship{Data: &ResourceRef{ID: opts.ConfigurationVersionID, Type: "configuration-versions"}}
+	}
+
+	body := runDocument{Data: Run{
+		Type: "runs",
+		Attributes: RunAttributes{
+			Message:     opts.Message,
+			IsDestroy:   opts.IsDestroy,
+			AutoApply:   opts.AutoApply,
+			PlanOnly:    opts.PlanOnly,
+			RefreshOnly: opts.RefreshOnly,
+		},
+		Relationships: relationships,
+	}}
+
+	var doc runDocument
+	if err := c.do(ctx, http.MethodPost, "/runs", nil, body, &doc); err != nil {
+		return nil, err
+	}
+	return &doc.Data, nil
+}
+
+func (c *Client) GetRun(ctx context.Context, id string) (*Run, error) {
+	var doc runDocument
+	if err := c.do(ctx, http.MethodGet, "/runs/"+url.PathEscape(id), nil, nil, &doc); err != nil {
+		return nil, err
+	}
+	return &doc.Data, nil
+}
+
+func (c *Client) GetPlan(ctx context.Context, id string) (*Plan, error) {
+	var doc planDocument
+	if err := c.do(ctx, http.MethodGet, "/plans/"+url.PathEscape(id), nil, nil, &doc); err != nil {
+		return nil, err
+	}
+	return &doc.Data, nil
+}
+
+// GetPlanJSON returns the plan in `terraform show -json` format. The API
+// answers 204 until the plan has finished.
+func (c *Client) GetPlanJSON(ctx context.Context, planID string) (json.RawMessage, error) {
+	var plan json.RawMessage
+	if err := c.do(ctx, http.MethodGet, "/plans/"+url.PathEscape(planID)+"/json-output", nil, nil, &plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (c *Client) GetApply(ctx context.Context, id string) (*Apply, error) {
+	var doc planDocument
+	if err := c.do(ctx, http.MethodGet, "/applies/"+url.PathEscape(id), nil, nil, &doc); err != nil {
+		return nil, err
+	}
+	return &doc.Data, nil
+}
+
+// ApplyRun confirms a run that is waiting for confirmation. The API accepts
+// the action and applies asynchronously; poll GetRun for the outcome.
+func (c *Client) ApplyRun(ctx context.Context, id, comment string) error {
+	return c.runAction(ctx, id, "apply", comment)
+}
+
+// DiscardRun skips applying a run that is waiting for confirmation.
+func (c *Client) DiscardRun(ctx context.Context, id, comment string) error {
+	return c.runAction(ctx, id, "discard", comment)
+}
+
+func (c *Client) CancelRun(ctx context.Context, id, comment string) error {
+	return c.runAction(ctx, id, "cancel", comment)
+}
+
+func (c *Client) runAction(ctx context.Context, id, action, comment string) error {
+	var body interface{}
+	if comment != "" {
+		body = map[string]string{"comment": comment}
+	}
+	return c.do(ctx, http.MethodPost, "/runs/"+url.PathEscape(id)+"/actions/"+action, nil, body, nil)
+}
+
+// ReadLog downloads a plan or apply log from its LogReadURL. The URL is
+// pre-signed, so no token is sent with it.
+func (c *Client) ReadLog(ctx context.Context, logURL string) (string, error) {
+	resp, err := c.send(ctx, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, logURL, nil)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read log: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return "", decodeError(resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read log: %v", err)
+	}
+	return string(data), nil
+}
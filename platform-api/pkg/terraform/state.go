@@ -0,0 +1,69 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+type StateVersionOutput struct {
+	ID         string                       `json:"id"`
+	Type       string                       `json:"type"`
+	Attributes StateVersionOutputAttributes `json:"attributes"`
+}
+
+// StateVersionOutputAttributes holds one root module output. Value is the
+// output's JSON value; it is null for sensitive outputs except when read
+// individually with GetStateVersionOutput.
+type StateVersionOutputAttributes struct {
+	Name         string          `json:"name"`
+	Sensitive    bool            `json:"sensitive"`
+	Type         string          `json:"type"`
+	Value        json.RawMessage `json:"value"`
+	DetailedType json.RawMessage `json:"detailed-type"`
+}
+
+type stateVersionOutputDocument struct {
+	Data StateVersionOutput `json:"data"`
+}
+
+type stateVersionOutputListDocument struct {
+	Data []StateVersionOutput `json:"data"`
+	Meta struct {
+		Pagination Pagination `json:"pagination"`
+	} `json:"meta"`
+}
+
+// CurrentStateVersionOutputs lists all outputs of the workspace's current
+// state, reading every page. A workspace that has never applied has no state
+// and returns a not found error.
+func (c *Client) CurrentStateVersionOutputs(ctx context.Context, workspaceID string) ([]StateVersionOutput, error) {
+	path := "/workspaces/" + url.PathEscape(workspaceID) + "/current-state-version-outputs"
+	opts := ListOptions{PageNumber: 1, PageSize: maxPageSize}
+
+	var outputs []StateVersionOutput
+	for {
+		var doc stateVersionOutputListDocument
+		if err := c.do(ctx, http.MethodGet, path, opts.values(), nil, &doc); err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, doc.Data...)
+
+		next := doc.Meta.Pagination.NextPage
+		if next == nil || *next <= opts.PageNumber {
+			return outputs, nil
+		}
+		opts.PageNumber = *next
+	}
+}
+
+// GetStateVersionOutput reads a single output, including the value of a
+// sensitive one when the token may read state.
+func (c *Client) GetStateVersionOutput(ctx context.Context, id string) (*StateVersionOutput, error) {
+	var doc stateVersionOutputDocument
+	if err := c.do(ctx, http.MethodGet, "/state-version-outputs/"+url.PathEscape(id), nil, nil, &doc); err != nil {
+		return nil, err
+	}
+	return &doc.Data, nil
+}
@@ -0,0 +1,48 @@
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCurrentStateVersionOutputsReadsAllPages(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/workspaces/ws-1/current-state-version-outputs" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("page[size]"); got != fmt.Sprint(maxPageSize) {
+			t.Errorf("page[size] = %q", got)
+		}
+		switch r.URL.Query().Get("page[number]") {
+		case "1":
+			w.Write([]byte(`{"data":[
+				{"id":"wsout-1","type":"state-version-outputs","attributes":{"name":"endpoint","type":"string","value":"db.internal"}},
+				{"id":"wsout-2","type":"state-version-outputs","attributes":{"name":"password","sensitive":true,"type":"string","value":null}}
+			],"meta":{"pagination":{"current-page":1,"next-page":2,"total-pages":2,"total-count":3}}}`))
+		case "2":
+			w.Write([]byte(`{"data":[
+				{"id":"wsout-3","type":"state-version-outputs","attributes":{"name":"port","type":"number","value":5432}}
+			],"meta":{"pagination":{"current-page":2,"prev-page":1,"next-page":null,"total-pages":2,"total-count":3}}}`))
+		default:
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	outputs, err := c.CurrentStateVersionOutputs(context.Background(), "ws-1")
+	if err != nil {
+		t.Fatalf("CurrentStateVersionOutputs: %v", err)
+	}
+	var names []string
+	for _, o := range outputs {
+		names = append(names, o.Attributes.Name)
+	}
+	if fmt.Sprint(names) != "[endpoint password port]" {
+		t.Errorf("names = %v", names)
+	}
+	if !outputs[1].Attributes.Sensitive || string(outputs[1].Attributes.Value) != "null" {
+		t.Errorf("sensitive output = %+v", outputs[1].Attributes)
+	}
+}
@@ -0,0 +1,148 @@
+package terraform
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Variable categories: Terraform input variables, or environment variables
+// set for the run.
+const (
+	CategoryTerraform = "terraform"
+	CategoryEnv       = "env"
+)
+
+type Variable struct {
+	ID         string             `json:"id,omitempty"`
+	Type       string             `json:"type"`
+	Attributes VariableAttributes `json:"attributes"`
+}
+
+// VariableAttributes describes a workspace variable. The API never returns
+// the value of a sensitive variable. HCL values are parsed as HCL rather
+// than taken as a literal string.
+type VariableAttributes struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Category    string `json:"category"`
+	HCL         bool   `json:"hcl"`
+	Sensitive   bool   `json:"sensitive"`
+}
+
+type VariableSet struct {
+	ID         string                `json:"id"`
+	Type       string                `json:"type"`
+	Attributes VariableSetAttributes `json:"attributes"`
+}
+
+type VariableSetAttributes struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Global      bool   `json:"global"`
+}
+
+type VariableSetList struct {
+	Items      []VariableSet
+	Pagination Pagination
+}
+
+type variableDocument struct {
+	Data Variable `json:"data"`
+}
+
+type variableListDocument struct {
+	Data []Variable `json:"data"`
+}
+
+type variableSetListDocument struct {
+	Data []VariableSet `json:"data"`
+	Meta struct {
+		Pagination Pagination `json:"pagination"`
+	} `json:"meta"`
+}
+
+type resourceRefsDocument struct {
+	Data []ResourceRef `json:"data"`
+}
+
+func (c *Client) ListVariables(ctx context.Context, workspaceID string) ([]Variable, error) {
+	var doc variableListDocument
+	if err := c.do(ctx, http.MethodGet, variablesPath(workspaceID), nil, nil, &doc); err != nil {
+		return nil, err
+	}
+	return doc.Data, nil
+}
+
+func (c *Client) CreateVariable(ctx context.Context, workspaceID string, attrs VariableAttributes) (*Variable, error) {
+	var doc variableDocument
+	body := variableDocument{Data: Variable{Type: "vars", Attributes: attrs}}
+	if err := c.do(ctx, http.MethodPost, variablesPath(workspaceID), nil, body, &doc); err != nil {
+		return nil, err
+	}
+	return &doc.Data, nil
+}
+
+func (c *Client) UpdateVariable(ctx context.Context, workspaceID, id string, attrs VariableAttributes) (*Variable, error) {
+	var doc variableDocument
+	body := variableDocument{Data: Variable{ID: id, Type: "vars", Attributes: attrs}}
+	if err := c.do(ctx, http.MethodPatch, variablesPath(workspaceID)+"/"+url.PathEscape(id), nil, body, &doc); err != nil {
+		return nil, err
+	}
+	return &doc.Data, nil
+}
+
+func (c *Client) DeleteVariable(ctx context.Context, workspaceID, id string) error {
+	return c.do(ctx, http.MethodDelete, variablesPath(workspaceID)+"/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// SetVariable creates the variable, or updates the one with the same key and
+// category.
+func (c *Client) SetVariable(ctx context.Context, workspaceID string, attrs VariableAttributes) (*Variable, error) {
+	existing, err := c.ListVariables(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range existing {
+		if v.Attributes.Key == attrs.Key && v.Attributes.Category == attrs.Category {
+			return c.UpdateVariable(ctx, workspaceID, v.ID, attrs)
+		}
+	}
+	return c.CreateVariable(ctx, workspaceID, attrs)
+}
+
+// ListVariableSets returns one page of the organization's variable sets.
+func (c *Client) ListVariableSets(ctx context.Context, org string, opts ListOptions) (*VariableSetList, error) {
+	var doc variableSetListDocument
+	if err := c.do(ctx, http.MethodGet, "/organizations/"+url.PathEscape(org)+"/varsets", opts.values(), nil, &doc); err != nil {
+		return nil, err
+	}
+	return &VariableSetList{Items: doc.Data, Pagination: doc.Meta.Pagination}, nil
+}
+
+// AttachVariableSet applies a variable set to the workspaces. Attaching a set
+// that is already attached is not an error.
+func (c *Client) AttachVariableSet(ctx context.Context, varsetID string, workspaceIDs ...string) error {
+	return c.do(ctx, http.MethodPost, variableSetWorkspacesPath(varsetID), nil, workspaceRefs(workspaceIDs), nil)
+}
+
+func (c *Client) DetachVariableSet(ctx context.Context, varsetID string, workspaceIDs ...string) error {
+	return c.do(ctx, http.MethodDelete, variableSetWorkspacesPath(varsetID), nil, workspaceRefs(workspaceIDs), nil)
+}
+
+func workspaceRefs(ids []string) resourceRefsDocument {
+	doc := resourceRefsDocument{Data: make([]ResourceRef, 0, len(ids))}
+	for _, id := range ids {
+		doc.Data = append(doc.Data, ResourceRef{ID: id, Type: "workspaces"})
+	}
+	return doc
+}
+
+func variablesPath(workspaceID string) string {
+	return "/workspaces/" + url.PathEscape(workspaceID) + "/vars"
+}
+
+func variableSetWorkspacesPath(varsetID string) string {
+	return "/varsets/" + url.PathEscape(varsetID) + "/relationships/workspaces"
+}
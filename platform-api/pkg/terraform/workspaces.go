@@ -0,0 +1,151 @@
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Workspace struct {
+	ID            string                  `json:"id,omitempty"`
+	Type          string                  `json:"type"`
+	Attributes    WorkspaceAttributes     `json:"attributes"`
+	Relationships *WorkspaceRelationships `json:"relationships,omitempty"`
+}
+
+// WorkspaceAttributes is used both to read workspaces and to create or update
+// them; unset fields are left out of requests so an update only changes what
+// is set. Read-only attributes are ignored by the API.
+type WorkspaceAttributes struct {
+	Name             string     `json:"name,omitempty"`
+	Description      string     `json:"description,omitempty"`
+	AutoApply        *bool      `json:"auto-apply,omitempty"`
+	ExecutionMode    string     `json:"execution-mode,omitempty"`
+	TerraformVersion string     `json:"terraform-version,omitempty"`
+	WorkingDirectory string     `json:"working-directory,omitempty"`
+	TagNames         []string   `json:"tag-names,omitempty"`
+	Locked           bool       `json:"locked,omitempty"`
+	ResourceCount    int        `json:"resource-count,omitempty"`
+	CreatedAt        *time.Time `json:"created-at,omitempty"`
+	UpdatedAt        *time.Time `json:"updated-at,omitempty"`
+}
+
+type WorkspaceRelationships struct {
+	Organization *Relationship `json:"organization,omitempty"`
+	CurrentRun   *Relationship `json:"current-run,omitempty"`
+	LockedBy     *Relationship `json:"locked-by,omitempty"`
+}
+
+type WorkspaceList struct {
+	Items      []Workspace
+	Pagination Pagination
+}
+
+type WorkspaceListOptions struct {
+	ListOptions
+	// Search matches workspace names containing the string.
+	Search string
+	// Tags limits results to workspaces with all of the comma-separated tags.
+	Tags string
+}
+
+type workspaceDocument struct {
+	Data Workspace `json:"data"`
+}
+
+type workspaceListDocument struct {
+	Data []Workspace `json:"data"`
+	Meta struct {
+		Pagination Pagination `json:"pagination"`
+	} `json:"meta"`
+}
+
+func (c *Client) CreateWorkspace(ctx context.Context, org string, attrs WorkspaceAttributes) (*Workspace, error) {
+	var doc workspaceDocument
+	body := workspaceDocument{Data: Workspace{Type: "workspaces", Attributes: attrs}}
+	if err := c.do(ctx, http.MethodPost, "/organizations/"+url.PathEscape(org)+"/workspaces", nil, body, &doc); err != nil {
+		return nil, err
+	}
+	return &doc.Data, nil
+}
+
+func (c *Client) GetWorkspace(ctx context.Context, org, name string) (*Workspace, error) {
+	var doc workspaceDocument
+	if err := c.do(ctx, http.MethodGet, workspacePath(org, name), nil, nil, &doc); err != nil {
+		return nil, err
+	}
+	return &doc.Data, nil
+}
+
+func (c *Client) GetWorkspaceByID(ctx context.Context, id string) (*Workspace, error) {
+	var doc workspaceDocument
+	if err := c.do(ctx, http.MethodGet, "/workspaces/"+url.PathEscape(id), nil, nil, &doc); err != nil {
+		return nil, err
+	}
+	return &doc.Data, nil
+}
+
+// ListWorkspaces returns one page of the organization's workspaces. Follow
+// Pagination.NextPage to read the rest.
+func (c *Client) ListWorkspaces(ctx context.Context, org string, opts WorkspaceListOptions) (*WorkspaceList, error) {
+	query := opts.values()
+	if opts.Search != "" {
+		query.Set("search[name]", opts.Search)
+	}
+	if opts.Tags != "" {
+		query.Set("search[tags]", opts.Tags)
+	}
+
+	var doc workspaceListDocument
+	if err := c.do(ctx, http.MethodGet, "/organizations/"+url.PathEscape(org)+"/workspaces", query, nil, &doc); err != nil {
+		return nil, err
+	}
+	return &WorkspaceList{Items: doc.Data, Pagination: doc.Meta.Pagination}, nil
+}
+
+func (c *Client) UpdateWorkspace(ctx context.Context, org, name string, attrs WorkspaceAttributes) (*Workspace, error) {
+	var doc workspaceDocument
+	body := workspaceDocument{Data: Workspace{Type: "workspaces", Attributes: attrs}}
+	if err := c.do(ctx, http.MethodPatch, workspacePath(org, name), nil, body, &doc); err != nil {
+		return nil, err
+	}
+	return &doc.Data, nil
+}
+
+// LockWorkspace locks the workspace against runs. Locking a locked workspace
+// fails with a conflict (see IsConflict).
+func (c *Client) LockWorkspace(ctx context.Context, id, reason string) (*Workspace, error) {
+	var doc workspaceDocument
+	body := map[string]string{"reason": reason}
+	if err := c.do(ctx, http.MethodPost, "/workspaces/"+url.PathEscape(id)+"/actions/lock", nil, body, &doc); err != nil {
+		return nil, err
+	}
+	return &doc.Data, nil
+}
+
+// UnlockWorkspace releases the lock. force unlocks a workspace locked by
+// another user or a run, which needs admin access to the workspace.
+func (c *Client) UnlockWorkspace(ctx context.Context, id string, force bool) (*Workspace, error) {
+	action := "unlock"
+	if force {
+		action = "force-unlock"
+	}
+
+	var doc workspaceDocument
+	if err := c.do(ctx, http.MethodPost, "/workspaces/"+url.PathEscape(id)+"/actions/"+action, nil, nil, &doc); err != nil {
+		return nil, err
+	}
+	return &doc.Data, nil
+}
+
+// DeleteWorkspace deletes the workspace and its state without destroying the
+// infrastructure it manages.
+func (c *Client) DeleteWorkspace(ctx context.Context, org, name string) error {
+	return c.do(ctx, http.MethodDelete, workspacePath(org, name), nil, nil, nil)
+}
+
+func workspacePath(org, name string) string {
+	return fmt.Sprintf("/organizations/%s/workspaces/%s", url.PathEscape(org), url.PathEscape(name))
+}
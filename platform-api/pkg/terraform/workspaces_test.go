@@ -0,0 +1,195 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func workspaceJSON(id, name string, locked bool) string {
+	return fmt.Sprintf(`{"id":%q,"type":"workspaces","attributes":{"name":%q,"locked":%t,"auto-apply":true,"resource-count":3}}`, id, name, locked)
+}
+
+func TestGetWorkspace(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.EscapedPath() != "/api/v2/organizations/acme/workspaces/tenant%20a" {
+			t.Errorf("got %s %s", r.Method, r.URL.EscapedPath())
+		}
+		fmt.Fprintf(w, `{"data":%s}`, workspaceJSON("ws-1", "tenant a", false))
+	}))
+
+	ws, err := c.GetWorkspace(context.Background(), "acme", "tenant a")
+	if err != nil {
+		t.Fatalf("GetWorkspace: %v", err)
+	}
+	if ws.ID != "ws-1" || ws.Attributes.Name != "tenant a" || ws.Attributes.ResourceCount != 3 {
+		t.Errorf("got %+v", ws)
+	}
+	if ws.Attributes.AutoApply == nil || !*ws.Attributes.AutoApply {
+		t.Errorf("AutoApply = %v, want true", ws.Attributes.AutoApply)
+	}
+}
+
+func TestListWorkspacesPages(t *testing.T) {
+	pages := map[string]string{
+		"1": fmt.Sprintf(`{"data":[%s,%s],"meta":{"pagination":{"current-page":1,"next-page":2,"total-pages":2,"total-count":3}}}`,
+			workspaceJSON("ws-1", "tenant-a", false), workspaceJSON("ws-2", "tenant-b", false)),
+		"2": fmt.Sprintf(`{"data":[%s],"meta":{"pagination":{"current-page":2,"prev-page":1,"next-page":null,"total-pages":2,"total-count":3}}}`,
+			workspaceJSON("ws-3", "tenant-c", false)),
+	}
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/api/v2/organizations/acme/workspaces" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if query.Get("page[size]") != "2" || query.Get("search[tags]") != "tenant" {
+			t.Errorf("query = %s", r.URL.RawQuery)
+		}
+		page := query.Get("page[number]")
+		if page == "" {
+			page = "1"
+		}
+		body, ok := pages[page]
+		if !ok {
+			t.Errorf("unexpected page %s", page)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+
+	opts := WorkspaceListOptions{ListOptions: ListOptions{PageSize: 2}, Tags: "tenant"}
+	var names []string
+	for requests := 0; ; requests++ {
+		if requests > len(pages) {
+			t.Fatal("pagination did not end")
+		}
+		list, err := c.ListWorkspaces(context.Background(), "acme", opts)
+		if err != nil {
+			t.Fatalf("ListWorkspaces: %v", err)
+		}
+		if list.Pagination.TotalCount != 3 {
+			t.Errorf("TotalCount = %d, want 3", list.Pagination.TotalCount)
+		}
+		for _, ws := range list.Items {
+			names = append(names, ws.Attributes.Name)
+		}
+		if list.Pagination.NextPage == nil {
+			break
+		}
+		opts.PageNumber = *list.Pagination.NextPage
+	}
+
+	if fmt.Sprint(names) != "[tenant-a tenant-b tenant-c]" {
+		t.Errorf("names = %v", names)
+	}
+}
+
+func TestUpdateWorkspaceSendsOnlySetAttributes(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/api/v2/organizations/acme/workspaces/tenant-a" {
+			t.Errorf("got %s %s", r.Method, r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var doc struct {
+			Data struct {
+				Type       string                 `json:"type"`
+				Attributes map[string]interface{} `json:"attributes"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &doc); err != nil {
+			t.Fatalf("invalid body %s: %v", body, err)
+		}
+		if doc.Data.Type != "workspaces" {
+			t.Errorf("type = %q", doc.Data.Type)
+		}
+		if len(doc.Data.Attributes) != 2 || doc.Data.Attributes["auto-apply"] != false || doc.Data.Attributes["terraform-version"] != "1.6.0" {
+			t.Errorf("attributes = %v", doc.Data.Attributes)
+		}
+		fmt.Fprintf(w, `{"data":%s}`, workspaceJSON("ws-1", "tenant-a", false))
+	}))
+
+	autoApply := false
+	_, err := c.UpdateWorkspace(context.Background(), "acme", "tenant-a", WorkspaceAttributes{
+		AutoApply:        &autoApply,
+		TerraformVersion: "1.6.0",
+	})
+	if err != nil {
+		t.Fatalf("UpdateWorkspace: %v", err)
+	}
+}
+
+func TestLockAndUnlockWorkspace(t *testing.T) {
+	locked := false
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s", r.Method)
+		}
+		switch r.URL.Path {
+		case "/api/v2/workspaces/ws-1/actions/lock":
+			if locked {
+				w.WriteHeader(http.StatusConflict)
+				w.Write([]byte(`{"errors":[{"status":"409","title":"conflict","detail":"Unable to lock workspace. The workspace is already locked."}]}`))
+				return
+			}
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["reason"] != "maintenance" {
+				t.Errorf("reason = %q", body["reason"])
+			}
+			locked = true
+		case "/api/v2/workspaces/ws-1/actions/unlock", "/api/v2/workspaces/ws-1/actions/force-unlock":
+			locked = false
+		default:
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		fmt.Fprintf(w, `{"data":%s}`, workspaceJSON("ws-1", "tenant-a", locked))
+	}))
+	c.MaxRetries = 0
+	ctx := context.Background()
+
+	ws, err := c.LockWorkspace(ctx, "ws-1", "maintenance")
+	if err != nil {
+		t.Fatalf("LockWorkspace: %v", err)
+	}
+	if !ws.Attributes.Locked {
+		t.Error("workspace not locked")
+	}
+
+	_, err = c.LockWorkspace(ctx, "ws-1", "maintenance")
+	if !IsConflict(err) {
+		t.Fatalf("second LockWorkspace error = %v, want conflict", err)
+	}
+
+	for _, force := range []bool{false, true} {
+		locked = true
+		ws, err = c.UnlockWorkspace(ctx, "ws-1", force)
+		if err != nil {
+			t.Fatalf("UnlockWorkspace(force=%t): %v", force, err)
+		}
+		if ws.Attributes.Locked {
+			t.Errorf("UnlockWorkspace(force=%t): workspace still locked", force)
+		}
+	}
+}
+
+func TestDeleteWorkspace(t *testing.T) {
+	deleted := false
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Path != "/api/v2/organizations/acme/workspaces/tenant-a" {
+			t.Errorf("got %s %s", r.Method, r.URL.Path)
+		}
+		deleted = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	if err := c.DeleteWorkspace(context.Background(), "acme", "tenant-a"); err != nil {
+		t.Fatalf("DeleteWorkspace: %v", err)
+	}
+	if !deleted {
+		t.Error("workspace was not deleted")
+	}
+}